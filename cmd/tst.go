@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 	"volleybot/pkg/postgres"
 	"volleybot/pkg/res"
 	"volleybot/pkg/services"
 	"volleybot/pkg/telegram"
 
 	"github.com/jackc/pgx/v4/pgxpool"
+	log "github.com/sirupsen/logrus"
 )
 
 type StartHandler struct {
@@ -19,77 +24,171 @@ type StartHandler struct {
 	ReserveService *services.VolleyBotService
 }
 
-func (h *StartHandler) StartCmd(msg *telegram.Message, chanr chan telegram.MessageResponse) error {
+func (h *StartHandler) StartCmd(ctx context.Context, msg *telegram.Message, chanr chan telegram.MessageResponse) error {
 	if msg.Chat.Id <= 0 {
 		return nil
 	}
-	text := "*Привет!*\n" +
-		"Я достаточно молодой волейбольный бот, но кое-что я могу.\n\n" +
-		"*Вот те команды, которые я уже понимаю:*"
+	tb := telegram.NewTextBuilder(telegram.ParseModeHTML)
+	tb.Bold("Привет!").Text("\nЯ достаточно молодой волейбольный бот, но кое-что я могу.\n\n")
+	tb.Bold("Вот те команды, которые я уже понимаю:")
 	cmds := []telegram.BotCommand{h.Command}
 	cmds = append(cmds, h.ReserveService.GetCommands(msg.From.Id)...)
 	for _, cmd := range cmds {
-		text += fmt.Sprintf("\n/%s - %s", cmd.Command, cmd.Description)
+		tb.Textf("\n/%s - %s", cmd.Command, cmd.Description)
+	}
+	if _, err := telegram.SendBoolRequest(ctx, h.Bot, &telegram.SetMyCommandsRequest{
+		Commands: cmds, Scope: telegram.BotCommandScopeChat{Type: "chat", ChatId: msg.From.Id}}); err != nil {
+		log.WithError(err).Error("can't set chat commands")
 	}
-	h.Bot.SendRequest(&telegram.SetMyCommandsRequest{
-		Commands: cmds, Scope: telegram.BotCommandScopeChat{Type: "chat", ChatId: msg.From.Id}})
 
 	mr := &telegram.MessageRequest{
 		ChatId:    msg.Chat.Id,
-		Text:      text,
-		ParseMode: "Markdown"}
-	h.Bot.SendMessage(mr)
+		Text:      tb.String(),
+		ParseMode: tb.ParseMode}
+	h.Bot.SendMessage(ctx, mr)
 	return nil
 }
 
+func ParseBanList(users string) telegram.BanList {
+	bl := telegram.BanList{}
+	for _, uid := range strings.Split(users, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(uid)); err == nil {
+			bl[id] = true
+		}
+	}
+	return bl
+}
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	url := os.Getenv("PGURL")
 	tb, _ := telegram.NewSimpleBot(os.Getenv("TOKEN"), &http.Client{})
-	dbpool, err := pgxpool.Connect(context.Background(), url)
+	dbpool, err := pgxpool.Connect(ctx, url)
 	if err != nil {
-		return
+		log.WithError(err).Fatal("can't connect to the database")
 	}
+	defer dbpool.Close()
 
 	vres := res.StaticVolleyResourceLoader{}.GetResources()
 	lrep, _ := postgres.NewLocationRepository(dbpool)
-	lrep.UpdateDB()
+	lrep.UpdateDB(ctx)
 	prep, _ := postgres.NewPersonPgRepository(dbpool)
-	prep.UpdateDB()
+	prep.UpdateDB(ctx)
 	rrep, _ := postgres.NewVolleyPgRepository(dbpool, &prep, &lrep)
-	rrep.UpdateDB()
+	rrep.UpdateDB(ctx)
+	schrep, _ := postgres.NewSchedulePgRepository(dbpool, &prep, &lrep)
+	schrep.UpdateDB(ctx)
+	rtrep, _ := postgres.NewRatingPgRepository(dbpool)
+	rtrep.UpdateDB(ctx)
 	strep, _ := postgres.NewStateRepository(dbpool)
-	strep.UpdateDB()
+	strep.UpdateDB(ctx)
 	confrep, _ := postgres.NewLocationConfigRepository(dbpool)
-	confrep.UpdateDB()
+	confrep.UpdateDB(ctx)
+	orep, _ := postgres.NewOffsetRepository(dbpool)
+	orep.UpdateDB(ctx)
+	cbrep, _ := postgres.NewCallbackDataRepository(dbpool)
+	cbrep.UpdateDB(ctx)
 
-	vservice := services.NewVolleyBotService(tb, &vres, &strep, &lrep, &rrep, &prep, &confrep)
+	lb := telegram.NewLimitedBot(tb)
+	cb := telegram.NewCallbackDataBot(lb, &cbrep)
+	vservice := services.NewVolleyBotService(cb, &vres, &strep, &lrep, &rrep, &prep, &confrep, &schrep, &rtrep)
 
+	vres.Resources.Payment.ProviderToken = os.Getenv("PAYMENT_TOKEN")
+	if vres.Resources.Payment.ProviderToken == "" {
+		vres.Resources.Show.PayBtn = ""
+	}
 	if os.Getenv("LOCATION") != "" {
 		vres.Location.Name = os.Getenv("LOCATION")
 	} else {
 		vres.Location.Name = "default"
 	}
 
-	lp := telegram.SimpleLongPoller{SimplePoller: telegram.NewSimplePoller(tb)}
+	var (
+		lp telegram.LongPoller
+		uh telegram.UpdateHandler
+	)
+	mh := telegram.NewMiddlewareHandler(nil,
+		telegram.RecoverMiddleware(),
+		telegram.LoggingMiddleware(),
+		telegram.TimingMiddleware(5*time.Second),
+		telegram.BanMiddleware(ParseBanList(os.Getenv("BANNED_USERS"))),
+		telegram.FloodMiddleware(30, time.Minute, "Слишком много запросов, попробуйте позже"),
+		telegram.CallbackDataMiddleware(&cbrep, "Кнопка устарела"))
+	allowed := []string{"message", "edited_message", "channel_post", "edited_channel_post", "callback_query",
+		"inline_query", "pre_checkout_query", "my_chat_member", "chat_member", "poll", "poll_answer"}
+	if os.Getenv("WEBHOOK_URL") != "" {
+		addr := os.Getenv("WEBHOOK_ADDR")
+		if addr == "" {
+			addr = ":8080"
+		}
+		ws := telegram.NewWebhookServer(tb, addr, os.Getenv("WEBHOOK_SECRET"))
+		if _, err = telegram.SendBoolRequest(ctx, tb, telegram.SetWebhookRequest{
+			Url: os.Getenv("WEBHOOK_URL"), AllowedUpdates: allowed, SecretToken: ws.SecretToken}); err != nil {
+			log.WithError(err).Fatal("can't set webhook")
+		}
+		mh.UpdateHandler = ws.UpdateHandlers[0]
+		uh = mh
+		ws.UpdateHandlers = []telegram.UpdateHandler{telegram.NewDispatcher(8, 100, mh)}
+		lp = ws
+	} else {
+		if _, err = telegram.SendBoolRequest(ctx, tb, telegram.DeleteWebhookRequest{}); err != nil {
+			log.WithError(err).Fatal("can't delete webhook")
+		}
+		timeout := telegram.DefaultPollTimeout
+		if t, err := strconv.Atoi(os.Getenv("POLL_TIMEOUT")); err == nil && t > 0 {
+			timeout = t
+		}
+		// the client waits for the whole long poll plus a margin, so getUpdates is never cut off
+		pb, _ := telegram.NewSimpleBot(os.Getenv("TOKEN"), telegram.NewPollingClient(timeout))
+		pl := telegram.SimpleLongPoller{SimplePoller: telegram.NewSimplePoller(pb)}
+		pl.Timeout = timeout
+		pl.AllowedUpdates = allowed
+		pl.OffsetStore = &orep
+		mh.UpdateHandler = pl.UpdateHandlers[0]
+		uh = mh
+		pl.UpdateHandlers = []telegram.UpdateHandler{telegram.NewDispatcher(8, 100, mh)}
+		lp = pl
+	}
 
-	sh := StartHandler{Bot: tb}
-	startcmd := telegram.CommandHandler{
-		Command: "start", Handler: func(m *telegram.Message) error {
-			return sh.StartCmd(m, nil)
-		}}
-	lp.UpdateHandlers[0].AppendMessageHandlers(&startcmd)
-	lp.UpdateHandlers[0].AppendMessageHandlers(&vservice)
-	lp.UpdateHandlers[0].AppendCallbackHandlers(&vservice)
+	me, err := telegram.GetMe(ctx, tb)
+	if err != nil {
+		log.WithError(err).Fatal("can't get bot info")
+	}
+	sh := StartHandler{Bot: lb}
+	router := telegram.NewRouter(me.UserName)
+	router.Handle("start", func(ctx context.Context, m *telegram.Message, cmd telegram.Command) error {
+		return sh.StartCmd(ctx, m, nil)
+	})
+	router.Handle("volley", vservice.ProceedVolleyCommand)
+	router.HandleDeepLink("join", vservice.ProceedJoinLink)
+	uh.AppendMessageHandlers(router)
+	uh.AppendMessageHandlers(&vservice)
+	uh.AppendEditedMessageHandlers(&telegram.BaseMessageHandler{Handler: vservice.ProceedEditedMessage})
+	uh.AppendChannelPostHandlers(&telegram.BaseMessageHandler{Handler: vservice.ProceedChannelPost})
+	uh.AppendEditedChannelPostHandlers(&telegram.BaseMessageHandler{Handler: vservice.ProceedChannelPost})
+	uh.AppendCallbackHandlers(&vservice)
+	uh.AppendInlineQueryHandlers(&vservice)
+	uh.AppendPreCheckoutQueryHandlers(&vservice)
+	uh.AppendChatMemberHandlers(&vservice)
+	uh.AppendPollHandlers(&vservice)
 
 	sh.ReserveService = &vservice
 	sh.Command.Command = "start"
 	sh.Command.Description = "начать работу с ботом"
 	cmds := []telegram.BotCommand{sh.Command}
 
-	_, err = tb.SendRequest(&telegram.SetMyCommandsRequest{
+	_, err = telegram.SendBoolRequest(ctx, tb, &telegram.SetMyCommandsRequest{
 		Commands: cmds, Scope: telegram.BotCommandScope{Type: "all_private_chats"}})
-	if err == nil {
-		lp.Run()
+	if err != nil {
+		log.WithError(err).Fatal("can't set bot commands")
+	}
+	go cbrep.RunCleaner(ctx, time.Hour)
+	go vservice.RunUnpinner(ctx, 5*time.Minute)
+	go vservice.RunReleaser(ctx, time.Minute)
+	go vservice.RunScheduler(ctx, time.Hour)
+	if err = lp.Run(ctx); err != nil {
+		log.WithError(err).Fatal("can't receive updates")
 	}
 }
@@ -13,15 +13,33 @@ type ActionsStateProvider struct {
 	BaseStateProvider
 	Resources     ActionsResources
 	ShowResources ShowResources
+	PollResources PollResources
 }
 
 func (p ActionsStateProvider) GetRequests() (rlist []telegram.StateRequest) {
 	if p.State.Action == "pub" {
 		show_p := ShowStateProvider{BaseStateProvider: p.BaseStateProvider, Resources: p.ShowResources}
 		show_p.State.ChatId = p.Location.ChatId
+		show_p.State.MessageId = 0
+		show_p.State.InlineMessageId = ""
 		show_p.State.State = "show"
 		show_p.State.Action = "show"
-		return show_p.GetRequests()
+		for _, sreq := range show_p.GetRequests() {
+			sreq.Pin = p.Location.ChatId != 0 && sreq.State.ChatId == p.Location.ChatId
+			rlist = append(rlist, sreq)
+		}
+		return
+	}
+	if p.State.Action == "poll" {
+		poll_p := PollStateProvider{BaseStateProvider: p.BaseStateProvider, Resources: p.PollResources}
+		if p.Location.ChatId != 0 {
+			poll_p.State.ChatId = p.Location.ChatId
+		}
+		poll_p.State.MessageId = 0
+		poll_p.State.InlineMessageId = ""
+		poll_p.State.State = "poll"
+		poll_p.State.Data = p.reserve.Base64Id()
+		return poll_p.GetRequests()
 	}
 	if p.State.Action == "copy" {
 		req := &telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.CopyDoneMessage}
@@ -54,6 +72,20 @@ func (p ActionsStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
 				Action: "pub", Text: res.PublishBtn})
 			kh.Actions = append(kh.Actions, telegram.ActionButton{
 				Action: "send", Text: res.SendBtn})
+			kh.Actions = append(kh.Actions, telegram.ActionButton{
+				Action: "poll", Text: res.PollBtn})
+			kh.Actions = append(kh.Actions, telegram.ActionButton{
+				Action: "teams", Text: res.TeamsBtn})
+			kh.Actions = append(kh.Actions, telegram.ActionButton{
+				Action: "results", Text: res.ResultsBtn})
+			if p.reserve.Activity == volley.Tournament {
+				kh.Actions = append(kh.Actions, telegram.ActionButton{
+					Action: "tour", Text: res.TournamentBtn})
+			}
+			if p.ScheduleRepository != nil {
+				kh.Actions = append(kh.Actions, telegram.ActionButton{
+					Action: "sched", Text: res.ScheduleBtn})
+			}
 		}
 	}
 	return &kh
@@ -61,7 +93,7 @@ func (p ActionsStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
 
 func (p ActionsStateProvider) Proceed() (st telegram.State, err error) {
 	if p.State.Action == "copy" {
-		if p.reserve, err = p.Repository.Add(p.reserve.Copy()); err != nil {
+		if p.reserve, err = p.Repository.Add(p.ctx, p.reserve.Copy()); err != nil {
 			p.State.Action = "show"
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
@@ -74,7 +106,22 @@ func (p ActionsStateProvider) Proceed() (st telegram.State, err error) {
 			return
 		}
 	}
-	if p.State.Action == "pub" {
+	if p.State.Action == "sched" && p.reserve.ScheduleId == uuid.Nil && p.ScheduleRepository != nil {
+		sch, err := p.ScheduleRepository.Add(p.ctx, volley.NewSchedule(p.reserve))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"package":  "bvbot",
+				"function": "Proceed",
+				"struct":   "ActionsStateProvider",
+				"state":    p.State,
+				"error":    err,
+			}).Error("can't add schedule")
+			return p.BackState, err
+		}
+		p.reserve.ScheduleId = sch.Id
+		p.State.Updated = true
+	}
+	if p.State.Action == "pub" || p.State.Action == "poll" {
 		return p.BackState, nil
 	}
 	return p.BaseStateProvider.Proceed()
@@ -150,7 +197,11 @@ func (p RemovePlayerStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
 	if p.State.ChatId == p.Person.TelegramId {
 		pllist := []telegram.EnumItem{}
 		for _, mb := range p.reserve.Members {
-			pllist = append(pllist, telegram.EnumItem{Id: strconv.Itoa(mb.TelegramId), Item: mb.String()})
+			item := mb.String()
+			if mb.Phone != "" {
+				item += " " + mb.Phone
+			}
+			pllist = append(pllist, telegram.EnumItem{Id: strconv.Itoa(mb.TelegramId), Item: item})
 		}
 		kh := telegram.NewEnumKeyboardHelper(pllist)
 		kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(p.Resources.Message)
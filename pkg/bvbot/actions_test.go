@@ -1,6 +1,7 @@
 package bvbot
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -44,8 +45,23 @@ func TestActionsStateKbd(t *testing.T) {
 			{Text: res.PublishBtn, CallbackData: "res_actions_pub_" + r.Id.String()},
 			{Text: res.SendBtn, CallbackData: "res_actions_send_" + r.Id.String()},
 		},
+		{
+			{Text: res.PollBtn, CallbackData: "res_actions_poll_" + r.Id.String()},
+			{Text: res.TeamsBtn, CallbackData: "res_actions_teams_" + r.Id.String()},
+		},
+		{
+			{Text: res.ResultsBtn, CallbackData: "res_actions_results_" + r.Id.String()},
+		},
 	}
 
+	tr := r
+	tr.Activity = volley.Tournament
+	tkbd := append([][]telegram.InlineKeyboardButton{}, akbd[:len(akbd)-1]...)
+	tkbd = append(tkbd, []telegram.InlineKeyboardButton{
+		{Text: res.ResultsBtn, CallbackData: "res_actions_results_" + r.Id.String()},
+		{Text: res.TournamentBtn, CallbackData: "res_actions_tour_" + r.Id.String()},
+	})
+
 	tests := map[string]struct {
 		res volley.Volley
 		p   person.Person
@@ -56,6 +72,7 @@ func TestActionsStateKbd(t *testing.T) {
 		"Group chat admin": {res: r, p: admin, cid: -10},
 		"Admin":            {res: r, p: admin, cid: admin.TelegramId, kbd: akbd},
 		"Author":           {res: r, p: oauthor, cid: oauthor.TelegramId, kbd: akbd},
+		"Tournament":       {res: tr, p: oauthor, cid: oauthor.TelegramId, kbd: tkbd},
 	}
 
 	for name, test := range tests {
@@ -63,7 +80,7 @@ func TestActionsStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_actions_actions_" + test.res.Id.String())
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, test.res.Location, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, test.res.Location, nil, nil, "")
 			bp.reserve = test.res
 			sp := ActionsStateProvider{BaseStateProvider: bp, Resources: res}
 			acts := sp.GetKeyboardHelper().GetKeyboard().(telegram.InlineKeyboardMarkup).InlineKeyboard
@@ -116,7 +133,7 @@ func TestCancelStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_cancel_cancel_" + test.res.Id.String())
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, test.res.Location, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, test.res.Location, nil, nil, "")
 			bp.reserve = test.res
 			sp := CancelStateProvider{BaseStateProvider: bp, Resources: res, ShowResources: sres}
 
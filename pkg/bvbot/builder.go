@@ -1,6 +1,8 @@
 package bvbot
 
 import (
+	"context"
+	"strings"
 	"volleybot/pkg/domain/location"
 	"volleybot/pkg/domain/person"
 	"volleybot/pkg/domain/volley"
@@ -10,22 +12,26 @@ import (
 )
 
 type BaseStateProvider struct {
-	reserve          volley.Volley
-	kh               telegram.KeyboardHelper
-	name             string
-	BackState        telegram.State
-	Message          telegram.Message
-	Person           person.Person
-	Repository       volley.Repository
-	ConfigRepository location.LocationConfigRepository
-	Location         location.Location
-	State            telegram.State
-	Text             string
+	ctx                context.Context
+	reserve            volley.Volley
+	kh                 telegram.KeyboardHelper
+	name               string
+	BackState          telegram.State
+	Message            telegram.Message
+	Person             person.Person
+	Repository         volley.Repository
+	ScheduleRepository volley.ScheduleRepository
+	RatingRepository   volley.RatingRepository
+	ConfigRepository   location.LocationConfigRepository
+	LockGame           func(data string) (unlock func())
+	Location           location.Location
+	State              telegram.State
+	Text               string
 }
 
-func NewBaseStateProvider(state telegram.State, msg telegram.Message, p person.Person, loc location.Location,
+func NewBaseStateProvider(ctx context.Context, state telegram.State, msg telegram.Message, p person.Person, loc location.Location,
 	rep volley.Repository, cfgrep location.LocationConfigRepository, text string) (sp BaseStateProvider, err error) {
-	sp = BaseStateProvider{State: state, Message: msg, Person: p, Location: loc, Repository: rep, ConfigRepository: cfgrep, Text: text}
+	sp = BaseStateProvider{ctx: ctx, State: state, Message: msg, Person: p, Location: loc, Repository: rep, ConfigRepository: cfgrep, Text: text}
 	sp.name = "beach_volley"
 	if rep != nil && state.Data != "" {
 		id, err := volley.Volley{}.IdFromBase64(state.Data)
@@ -41,7 +47,7 @@ func NewBaseStateProvider(state telegram.State, msg telegram.Message, p person.P
 
 			return sp, err
 		}
-		if sp.reserve, err = sp.Repository.Get(id); err != nil {
+		if sp.reserve, err = sp.Repository.Get(sp.ctx, id); err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
 				"function": "NewBaseStateProvider",
@@ -66,7 +72,7 @@ func (p BaseStateProvider) GetBaseKeyboardHelper(txt string) (kh telegram.BaseKe
 
 func (p BaseStateProvider) Proceed() (st telegram.State, err error) {
 	if p.State.Updated {
-		err = p.Repository.Update(p.reserve)
+		err = p.Repository.Update(p.ctx, p.reserve)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
@@ -88,20 +94,33 @@ func (p BaseStateProvider) GetRequests() (rlist []telegram.StateRequest) {
 	if p.State.Action != p.State.State {
 		return
 	}
-	if p.State.ChatId < 0 {
-		req := telegram.DeleteMessageRequest{ChatId: p.State.ChatId, MessageId: p.State.MessageId}
-		rlist = append(rlist, telegram.StateRequest{Request: &req})
-		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: p.GetMR()})
-
+	mr := p.GetMR()
+	if p.State.MessageId == 0 && p.State.InlineMessageId == "" {
+		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: mr})
+	} else if p.IsKeyboardOnly(mr) {
+		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: p.GetEditMarkupMR(mr)})
 	} else {
-		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: p.GetEditMR(p.GetMR())})
+		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: p.GetEditMR(mr)})
 	}
 	return
 }
 
+func (p BaseStateProvider) IsKeyboardOnly(mr *telegram.MessageRequest) bool {
+	if p.Message.Chat == nil || p.Message.Chat.Id != p.State.ChatId || p.Message.MessageId != p.State.MessageId {
+		return false
+	}
+	return p.Message.Text != "" &&
+		strings.TrimSpace(telegram.PlainText(mr.ParseMode, mr.Text)) == strings.TrimSpace(p.Message.Text)
+}
+
+func (p *BaseStateProvider) GetEditMarkupMR(mr *telegram.MessageRequest) *telegram.EditMessageReplyMarkupRequest {
+	return &telegram.EditMessageReplyMarkupRequest{MessageId: p.State.MessageId, ChatId: mr.ChatId,
+		InlineMessageId: p.State.InlineMessageId, ReplyMarkup: mr.ReplyMarkup}
+}
+
 func (p *BaseStateProvider) GetEditMR(mr *telegram.MessageRequest) (mer *telegram.EditMessageTextRequest) {
 	mer = &telegram.EditMessageTextRequest{MessageId: p.State.MessageId, ChatId: mr.ChatId, Text: mr.Text, ParseMode: mr.ParseMode,
-		ReplyMarkup: mr.ReplyMarkup}
+		ReplyMarkup: mr.ReplyMarkup, InlineMessageId: p.State.InlineMessageId}
 	return
 }
 
@@ -137,7 +156,7 @@ func (p BaseStateProvider) CreateMR(cid int, txt string, pmode string, kbd inter
 func (p *BaseStateProvider) NotifyPlayers(action string) (reqlist []telegram.StateRequest) {
 	for _, mb := range p.reserve.Members {
 		if mb.Person.TelegramId != p.Person.TelegramId {
-			if param, ok := mb.Settings[action]; ok && param == "on" {
+			if mb.Notify(action, p.reserve.Location.Id) {
 				mr := p.GetMR()
 				mr.ChatId = mb.TelegramId
 				reqlist = append(reqlist, telegram.StateRequest{Request: mr})
@@ -148,14 +167,14 @@ func (p *BaseStateProvider) NotifyPlayers(action string) (reqlist []telegram.Sta
 }
 
 func (p BaseStateProvider) GetLocationConfig() (conf Config) {
-	err := p.ConfigRepository.Get(p.Location, p.name, &conf)
+	err := p.ConfigRepository.Get(p.ctx, p.Location, p.name, &conf)
 
 	if err != nil {
 		if (conf == Config{}) {
 			conf = NewConfig()
-			p.ConfigRepository.Add(p.Location, p.name, conf)
+			p.ConfigRepository.Add(p.ctx, p.Location, p.name, conf)
 		} else {
-			err := p.ConfigRepository.Update(p.Location, p.name, conf)
+			err := p.ConfigRepository.Update(p.ctx, p.Location, p.name, conf)
 			if err != nil {
 				log.WithFields(log.Fields{
 					"package":  "bvbot",
@@ -173,7 +192,7 @@ func (p BaseStateProvider) GetLocationConfig() (conf Config) {
 }
 
 func (p BaseStateProvider) UpdateLocationConfig(conf Config) (err error) {
-	if err = p.ConfigRepository.Update(p.Location, p.name, &conf); err != nil {
+	if err = p.ConfigRepository.Update(p.ctx, p.Location, p.name, &conf); err != nil {
 		log.WithFields(log.Fields{
 			"package":  "bvbot",
 			"function": "UpdateLocationConfig",
@@ -191,8 +210,8 @@ type BvStateBuilder struct {
 	Resources Resources
 }
 
-func NewBvStateBuilder(loc location.Location, msg telegram.Message, p person.Person, rep volley.Repository, res Resources, cfgrep location.LocationConfigRepository, st telegram.State) (bld BvStateBuilder, err error) {
-	bp, err := NewBaseStateProvider(st, msg, p, loc, rep, cfgrep, "")
+func NewBvStateBuilder(ctx context.Context, loc location.Location, msg telegram.Message, p person.Person, rep volley.Repository, res Resources, cfgrep location.LocationConfigRepository, st telegram.State) (bld BvStateBuilder, err error) {
+	bp, err := NewBaseStateProvider(ctx, st, msg, p, loc, rep, cfgrep, "")
 	if err != nil {
 		log.WithFields(log.Fields{
 			"package":  "bvbot",
@@ -232,11 +251,62 @@ func (bld BvStateBuilder) GetStateProvider(st telegram.State) (sp telegram.State
 		bp.BackState.State = "main"
 		bp.BackState.Action = bp.BackState.State
 		sp = ShowStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Show}
+	case "pay":
+		bp.BackState.State = "show"
+		bp.BackState.Action = bp.BackState.State
+		sp = &PaymentStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Payment}
+	case "poll":
+		sp = PollStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Poll}
+	case "promo":
+		sp = PromoteStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Promote}
+	case "inline":
+		sp = InlineStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Inline,
+			ShowResources: bld.Resources.Show}
 	case "actions":
 		bp.BackState.State = "show"
 		bp.BackState.Action = bp.BackState.State
 		sp = ActionsStateProvider{BaseStateProvider: bp,
-			Resources: bld.Resources.Actions, ShowResources: bld.Resources.Show}
+			Resources: bld.Resources.Actions, ShowResources: bld.Resources.Show, PollResources: bld.Resources.Poll}
+	case "sched":
+		bp.BackState.State = "actions"
+		bp.BackState.Action = bp.BackState.State
+		sp = &ScheduleStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Schedule}
+	case "sdays":
+		bp.BackState.State = "sched"
+		bp.BackState.Action = bp.BackState.State
+		sp = ScheduleDaysStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Schedule}
+	case "teams":
+		bp.BackState.State = "actions"
+		bp.BackState.Action = bp.BackState.State
+		sp = TeamsStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Teams}
+	case "tpair":
+		bp.BackState.State = "teams"
+		bp.BackState.Action = bp.BackState.State
+		sp = TeamPairStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Teams}
+	case "results":
+		bp.BackState.State = "actions"
+		bp.BackState.Action = bp.BackState.State
+		sp = &ResultsStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Results}
+	case "rscore":
+		bp.BackState.State = "results"
+		bp.BackState.Action = bp.BackState.State
+		sp = &ScoreStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Results}
+	case "tour":
+		bp.BackState.State = "actions"
+		bp.BackState.Action = bp.BackState.State
+		sp = TournamentStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Tournament}
+	case "tmatch":
+		bp.BackState.State = "tour"
+		bp.BackState.Action = bp.BackState.State
+		sp = TournamentMatchStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Tournament}
+	case "treset":
+		bp.BackState.State = "tour"
+		bp.BackState.Action = bp.BackState.State
+		sp = TournamentResetStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Tournament}
+	case "tscore":
+		bp.BackState.State = "tour"
+		bp.BackState.Action = bp.BackState.State
+		sp = &TournamentScoreStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Tournament}
 	case "date":
 		bp.BackState.State = "show"
 		bp.BackState.Action = bp.BackState.State
@@ -316,6 +386,11 @@ func (bld BvStateBuilder) GetStateProvider(st telegram.State) (sp telegram.State
 		bp.BackState.Action = bp.BackState.State
 		pp := PlayerStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Profile}
 		sp = SexStateProvider{PlayerStateProvider: pp}
+	case "phone":
+		bp.BackState.State = "profile"
+		bp.BackState.Action = bp.BackState.State
+		pp := PlayerStateProvider{BaseStateProvider: bp, Resources: bld.Resources.Profile}
+		sp = &PhoneStateProvider{PlayerStateProvider: pp, Resources: bld.Resources.Phone}
 	case "notifies":
 		bp.BackState.State = "profile"
 		bp.BackState.Action = bp.BackState.State
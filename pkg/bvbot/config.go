@@ -4,8 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"strings"
+	"volleybot/pkg/telegram"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -110,14 +110,15 @@ type ConfigTelegramView struct {
 }
 
 func NewConfigTelegramViewRu(cfg Config) ConfigTelegramView {
-	return ConfigTelegramView{Config: cfg, ParseMode: "Markdown"}
+	return ConfigTelegramView{Config: cfg, ParseMode: telegram.ParseModeHTML}
 }
 
 func (tgv ConfigTelegramView) GetText() (text string) {
-	text = NewConfigCourtsTelegramViewRu(tgv.Config.Courts).GetText()
-	text += "\n\n"
-	text += NewConfigPriceTelegramViewRu(tgv.Config.Price).GetText()
-	return
+	cview := NewConfigCourtsTelegramViewRu(tgv.Config.Courts)
+	cview.ParseMode = tgv.ParseMode
+	pview := NewConfigPriceTelegramViewRu(tgv.Config.Price)
+	pview.ParseMode = tgv.ParseMode
+	return cview.GetText() + "\n\n" + pview.GetText()
 }
 
 type ConfigCourtsTelegramView struct {
@@ -130,16 +131,17 @@ func NewConfigCourtsTelegramViewRu(cfg CourtsConfig) ConfigCourtsTelegramView {
 	return ConfigCourtsTelegramView{
 		CourtsConfig: cfg,
 		Resources:    NewConfigCourtsResourcesRu(),
-		ParseMode:    "Markdown",
+		ParseMode:    telegram.ParseModeHTML,
 	}
 }
 
 func (tgv ConfigCourtsTelegramView) GetText() (text string) {
-	text = "⚙️*Настройки кортов:*"
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.Max, tgv.CourtsConfig.Max)
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.MinPlayers, tgv.CourtsConfig.MinPlayers)
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.MaxPlayers, tgv.CourtsConfig.MaxPlayers)
-	return
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("⚙️").Bold("Настройки кортов:")
+	tb.Text("\n").Bold(tgv.Resources.Max).Textf(": %v", tgv.CourtsConfig.Max)
+	tb.Text("\n").Bold(tgv.Resources.MinPlayers).Textf(": %v", tgv.CourtsConfig.MinPlayers)
+	tb.Text("\n").Bold(tgv.Resources.MaxPlayers).Textf(": %v", tgv.CourtsConfig.MaxPlayers)
+	return tb.String()
 }
 
 type ConfigPriceTelegramView struct {
@@ -152,14 +154,15 @@ func NewConfigPriceTelegramViewRu(cfg PriceConfig) ConfigPriceTelegramView {
 	return ConfigPriceTelegramView{
 		PriceConfig: cfg,
 		Resources:   NewConfigPriceResourcesRu(),
-		ParseMode:   "Markdown",
+		ParseMode:   telegram.ParseModeHTML,
 	}
 }
 
 func (tgv ConfigPriceTelegramView) GetText() (text string) {
-	text = "⚙️*Настройки цены:*"
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.Min, tgv.PriceConfig.Min)
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.Max, tgv.PriceConfig.Max)
-	text += fmt.Sprintf("\n*%s*: %v", tgv.Resources.Step, tgv.PriceConfig.Step)
-	return
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("⚙️").Bold("Настройки цены:")
+	tb.Text("\n").Bold(tgv.Resources.Min).Textf(": %v", tgv.PriceConfig.Min)
+	tb.Text("\n").Bold(tgv.Resources.Max).Textf(": %v", tgv.PriceConfig.Max)
+	tb.Text("\n").Bold(tgv.Resources.Step).Textf(": %v", tgv.PriceConfig.Step)
+	return tb.String()
 }
@@ -0,0 +1,66 @@
+package bvbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+func TestDescStateProvider(t *testing.T) {
+	author := person.Person{Id: uuid.New(), Firstname: "Elly", TelegramId: 100}
+	v := volley.NewVolley(author, time.Now().Add(time.Hour), time.Now().Add(3*time.Hour))
+	v.Description = "Old"
+	updated := volley.Volley{}
+	rep := volleyRepositoryMock{reserves: []volley.Volley{v}, updated: &updated}
+	newProvider := func(action string, mid int, text string) *DescStateProvider {
+		st := telegram.State{Prefix: "res", Separator: "_", State: "desc", Action: action, Data: v.Base64Id(),
+			ChatId: author.TelegramId, MessageId: mid}
+		msg := telegram.Message{MessageId: 55, Text: text}
+		bp, _ := NewBaseStateProvider(context.Background(), st, msg, author, v.Location, rep, nil, "")
+		bp.BackState = st
+		bp.BackState.State = "show"
+		bp.BackState.Action = "show"
+		return &DescStateProvider{BaseStateProvider: bp, Resources: NewDescResourcesRu()}
+	}
+
+	t.Run("Prompt with force reply", func(t *testing.T) {
+		rlist := newProvider("desc", 0, "").GetRequests()
+		if len(rlist) != 1 || rlist[0].State.MessageId != -1 {
+			t.FailNow()
+		}
+		kbd, ok := rlist[0].Request.(*telegram.MessageRequest).ReplyMarkup.(telegram.ForceReply)
+		if !ok || !kbd.ForceReply || kbd.InputFieldPlaceholder == "" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Remember description message", func(t *testing.T) {
+		sp := newProvider("desc", -1, "New")
+		sp.Proceed()
+		rlist := sp.GetRequests()
+		if updated.Description != "New" || len(rlist) != 3 {
+			t.FailNow()
+		}
+		edit := rlist[1]
+		if edit.Request != nil || edit.Clear || edit.State.Action != "edited" || edit.State.MessageId != 55 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Apply edited description", func(t *testing.T) {
+		sp := newProvider("edited", 55, "Edited")
+		st, err := sp.Proceed()
+		if err != nil || !st.Updated || updated.Description != "Edited" {
+			t.Fail()
+		}
+		if len(sp.GetRequests()) != 0 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package bvbot
+
+import (
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type InlineResources struct {
+	CacheTime  int `json:"cache_time"`
+	MaxResults int `json:"max_results"`
+}
+
+func NewInlineResourcesRu() (r InlineResources) {
+	r.CacheTime = 10
+	r.MaxResults = 50
+	return
+}
+
+type InlineStateProvider struct {
+	BaseStateProvider
+	Resources     InlineResources
+	ShowResources ShowResources
+}
+
+func (p InlineStateProvider) GetReserves() (rlist []volley.Volley) {
+	filter := volley.Volley{}
+	filter.StartTime = time.Now()
+	reserves, err := p.Repository.GetByFilter(p.ctx, filter, true, true)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  "bvbot",
+			"function": "GetReserves",
+			"struct":   "InlineStateProvider",
+			"fliter":   filter,
+			"error":    err,
+		}).Error("can't get reserves by filter")
+		return
+	}
+	for _, r := range reserves {
+		if r.Canceled || len(rlist) >= p.Resources.MaxResults {
+			continue
+		}
+		if r.Person.TelegramId == p.Person.TelegramId || r.HasPlayerByTelegramId(p.Person.TelegramId) {
+			rlist = append(rlist, r)
+		}
+	}
+	return
+}
+
+func (p InlineStateProvider) GetResults() (results []interface{}) {
+	for _, r := range p.GetReserves() {
+		sp := ShowStateProvider{BaseStateProvider: p.BaseStateProvider, Resources: p.ShowResources}
+		sp.reserve = r
+		sp.State = telegram.State{Prefix: p.State.Prefix, Separator: p.State.Separator,
+			State: "show", Action: "show", Data: r.Base64Id()}
+		rview := volley.NewTelegramViewRu(r)
+		article := telegram.NewInlineQueryResultArticle(r.Base64Id(), rview.String(), rview.GetText())
+		article.InputMessageContent.ParseMode = rview.ParseMode
+		article.ReplyMarkup = sp.GetKeyboardHelper().GetKeyboard()
+		results = append(results, article)
+	}
+	return
+}
+
+func (p InlineStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	req := telegram.AnswerInlineQueryRequest{InlineQueryId: p.State.Value, Results: p.GetResults(),
+		CacheTime: p.Resources.CacheTime, IsPersonal: true}
+	return append(rlist, telegram.StateRequest{Request: req})
+}
+
+func (p InlineStateProvider) Proceed() (telegram.State, error) {
+	return p.State, nil
+}
@@ -0,0 +1,118 @@
+package bvbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+type volleyRepositoryMock struct {
+	volley.Repository
+	reserves []volley.Volley
+	updated  *volley.Volley
+	player   *volley.Player
+	levels   map[uuid.UUID]volley.PlayerLevel
+}
+
+func (rep volleyRepositoryMock) GetByFilter(ctx context.Context, filter volley.Volley, ordered bool, sorted bool) ([]volley.Volley, error) {
+	mr := volley.NewMemoryRepository(&rep.reserves, filter, ordered)
+	return mr.GetByFilter(ctx, volley.Volley{}, false, sorted)
+}
+
+func (rep volleyRepositoryMock) Get(ctx context.Context, id uuid.UUID) (volley.Volley, error) {
+	for _, r := range rep.reserves {
+		if r.Id == id {
+			return r, nil
+		}
+	}
+	return volley.Volley{}, nil
+}
+
+func (rep volleyRepositoryMock) Update(ctx context.Context, r volley.Volley) error {
+	if rep.updated != nil {
+		*rep.updated = r
+	}
+	return nil
+}
+
+func (rep volleyRepositoryMock) GetPlayer(ctx context.Context, p person.Person) (volley.Player, error) {
+	return volley.Player{Person: p, Level: rep.levels[p.Id]}, nil
+}
+
+func (rep volleyRepositoryMock) UpdatePlayer(ctx context.Context, pl volley.Player) error {
+	if rep.player != nil {
+		*rep.player = pl
+	}
+	return nil
+}
+
+func TestInlineStateResults(t *testing.T) {
+	user := person.Person{Id: uuid.New(), Firstname: "Elly", TelegramId: 100}
+	other := person.Person{Id: uuid.New(), Firstname: "Steve", TelegramId: 123}
+	stime := time.Now().Add(24 * time.Hour)
+	newVolley := func(author person.Person, start time.Time, members ...person.Person) volley.Volley {
+		v := volley.NewVolley(author, start, start.Add(2*time.Hour))
+		v.MaxPlayers = 6
+		v.Location = location.Location{Id: uuid.New()}
+		for _, mb := range members {
+			v.Members = append(v.Members, volley.Member{Player: volley.Player{Person: mb}, Count: 1})
+		}
+		return v
+	}
+	own := newVolley(user, stime)
+	joined := newVolley(other, stime.Add(time.Hour), user)
+	foreign := newVolley(other, stime, other)
+	past := newVolley(user, time.Now().Add(-48*time.Hour), user)
+	canceled := newVolley(user, stime)
+	canceled.Canceled = true
+	reserves := []volley.Volley{own, joined, foreign, past, canceled}
+	rep := volleyRepositoryMock{reserves: reserves}
+
+	st := telegram.State{Prefix: "res", Separator: "_", State: "inline", Action: "inline", Value: "3456"}
+	bp, _ := NewBaseStateProvider(context.Background(), st, telegram.Message{}, user, location.Location{}, rep, nil, "")
+	sp := InlineStateProvider{BaseStateProvider: bp, Resources: NewInlineResourcesRu(), ShowResources: NewShowResourcesRu()}
+	rlist := sp.GetRequests()
+
+	t.Run("Answer inline query", func(t *testing.T) {
+		if len(rlist) != 1 {
+			t.FailNow()
+		}
+		req, ok := rlist[0].Request.(telegram.AnswerInlineQueryRequest)
+		if !ok || req.InlineQueryId != "3456" || !req.IsPersonal {
+			t.Fail()
+		}
+	})
+
+	t.Run("User upcoming games", func(t *testing.T) {
+		results := sp.GetResults()
+		if len(results) != 2 {
+			t.FailNow()
+		}
+		ids := map[string]bool{}
+		for _, r := range results {
+			ids[r.(telegram.InlineQueryResultArticle).Id] = true
+		}
+		if !ids[own.Base64Id()] || !ids[joined.Base64Id()] {
+			t.Fail()
+		}
+	})
+
+	t.Run("Join buttons", func(t *testing.T) {
+		results := sp.GetResults()
+		if len(results) == 0 {
+			t.FailNow()
+		}
+		article := results[0].(telegram.InlineQueryResultArticle)
+		kbd := article.ReplyMarkup.(telegram.InlineKeyboardMarkup).InlineKeyboard
+		if kbd[0][0].CallbackData != "res_show_join_"+article.Id {
+			t.Fail()
+		}
+	})
+}
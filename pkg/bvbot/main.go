@@ -72,7 +72,7 @@ func (p MainStateProvider) Proceed() (st telegram.State, err error) {
 	if p.State.Action == "order" {
 		p.reserve = p.NewReserve()
 		p.reserve.Location = p.Location
-		p.reserve, err = p.Repository.Add(p.reserve)
+		p.reserve, err = p.Repository.Add(p.ctx, p.reserve)
 		p.State.Data = p.reserve.Base64Id()
 		p.State.Action = "show"
 	} else if p.State.Action == "listd" {
@@ -119,17 +119,18 @@ type ListdStateProvider struct {
 }
 
 func (p ListdStateProvider) GetListText() (txt string) {
+	tb := telegram.NewTextBuilder(p.Resources.ParseMode)
 	if len(p.reserves) > 0 {
-		txt += p.Resources.ListCaption + "\n\n"
+		tb.Bold(p.Resources.ListCaption).Text("\n\n")
 		for i, res := range p.reserves {
 			tgv := volley.NewTelegramViewRu(res)
-			txt += fmt.Sprintf("%v. %s\n", i+1, tgv.String())
+			tb.Textf("%v. %s\n", i+1, tgv.String())
 		}
-		txt += "\n"
+		tb.Text("\n")
 	} else {
-		txt += p.Resources.NoReservesMessage
+		tb.Text(p.Resources.NoReservesMessage)
 	}
-	return
+	return tb.String()
 }
 
 func (p ListdStateProvider) GetMR() (mr *telegram.MessageRequest) {
@@ -170,7 +171,7 @@ func (p *ListdStateProvider) InitReserves() {
 	filter.StartTime = time.Date(dt.Year(), dt.Month(), dt.Day(), 0, 0, 0, 0, dt.Location())
 	filter.EndTime = filter.StartTime.Add(time.Duration(time.Hour * 24))
 	var err error
-	if p.reserves, err = p.Repository.GetByFilter(filter, true, true); err != nil {
+	if p.reserves, err = p.Repository.GetByFilter(p.ctx, filter, true, true); err != nil {
 		log.WithFields(log.Fields{
 			"package":  "bvbot",
 			"function": "InitReserves",
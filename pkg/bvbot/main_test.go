@@ -1,6 +1,7 @@
 package bvbot
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -50,7 +51,7 @@ func TestMainStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_main_main")
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, loc, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, loc, nil, nil, "")
 			sp := MainStateProvider{BaseStateProvider: bp, Resources: res}
 			acts := sp.GetKeyboardHelper().GetKeyboard().(telegram.InlineKeyboardMarkup).InlineKeyboard
 			if !reflect.DeepEqual(acts, test.kbd) {
@@ -0,0 +1,132 @@
+package bvbot
+
+import (
+	"fmt"
+	"time"
+	"volleybot/pkg/domain/order"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type PaymentResources struct {
+	ProviderToken string `json:"provider_token"`
+	Currency      string `json:"currency"`
+	Title         string `json:"title"`
+	Label         string `json:"label"`
+	ErrorMessage  string `json:"error_message"`
+	DoneMessage   string `json:"done_message"`
+}
+
+func NewPaymentResourcesRu() (r PaymentResources) {
+	r.Currency = "RUB"
+	r.Title = "Оплата участия"
+	r.Label = "Участие"
+	r.ErrorMessage = "Оплата сейчас недоступна. Обновите активность и попробуйте еще раз."
+	r.DoneMessage = "Спасибо! Оплата получена."
+	return
+}
+
+type PaymentStateProvider struct {
+	BaseStateProvider
+	Resources PaymentResources
+}
+
+func (p PaymentStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	switch p.State.Action {
+	case "pay":
+		if req := p.GetInvoice(); req != nil {
+			rlist = append(rlist, telegram.StateRequest{Request: req})
+		}
+	case "done":
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.DoneMessage}
+		rlist = append(rlist, telegram.StateRequest{Request: &req})
+	}
+	return
+}
+
+func (p PaymentStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	return nil
+}
+
+func (p PaymentStateProvider) GetInvoice() *telegram.InvoiceRequest {
+	sum := p.reserve.GetMemberSum(p.Person.Id)
+	if p.State.ChatId <= 0 || sum <= 0 || !p.reserve.MainMembers()[p.Person.Id] {
+		return nil
+	}
+	st := p.State
+	st.Action = "paid"
+	st.Data = p.reserve.Base64Id()
+	rview := volley.NewTelegramViewRu(p.reserve)
+	return &telegram.InvoiceRequest{
+		ChatId:        p.State.ChatId,
+		Title:         p.Resources.Title,
+		Description:   rview.String(),
+		Payload:       st.String(),
+		ProviderToken: p.Resources.ProviderToken,
+		Currency:      p.Resources.Currency,
+		Prices:        []telegram.LabeledPrice{{Label: p.Resources.Label, Amount: sum * 100}},
+	}
+}
+
+func (p PaymentStateProvider) CheckPayment(currency string, amount int) error {
+	mb := p.reserve.GetMember(p.Person.Id)
+	switch {
+	case p.reserve.Id == uuid.Nil:
+		return fmt.Errorf("reserve not found: %s", p.State.Data)
+	case p.reserve.Canceled:
+		return fmt.Errorf("reserve is canceled: %s", p.reserve.Id)
+	case mb.Count == 0:
+		return fmt.Errorf("person %s is not a member", p.Person.Id)
+	case !p.reserve.MainMembers()[p.Person.Id]:
+		return fmt.Errorf("person %s is in the waiting list", p.Person.Id)
+	case mb.GetPaid():
+		return fmt.Errorf("person %s has already paid", p.Person.Id)
+	case currency != p.Resources.Currency:
+		return fmt.Errorf("unexpected currency: %s", currency)
+	case amount != p.reserve.GetMemberSum(p.Person.Id)*100:
+		return fmt.Errorf("unexpected amount: %d", amount)
+	}
+	return nil
+}
+
+func (p *PaymentStateProvider) Proceed() (telegram.State, error) {
+	pay := p.Message.SuccessfulPayment
+	if p.State.Action != "paid" || pay == nil {
+		return p.State, nil
+	}
+	mb := p.reserve.GetMember(p.Person.Id)
+	if mb.Id == uuid.Nil {
+		err := fmt.Errorf("person %s is not a member", p.Person.Id)
+		log.WithFields(log.Fields{
+			"package":  "bvbot",
+			"function": "Proceed",
+			"struct":   "PaymentStateProvider",
+			"state":    p.State,
+			"payment":  pay,
+			"error":    err,
+		}).Error("can't apply payment: " + pay.TelegramPaymentChargeId)
+		return p.State, err
+	}
+	st := p.State
+	st.Action = "done"
+	if mb.Payment.ChargeId == pay.TelegramPaymentChargeId {
+		return st, nil
+	}
+	mb.SetPaid(true)
+	mb.Payment = order.Payment{
+		Id:               uuid.New(),
+		Person:           p.Person,
+		Sum:              pay.TotalAmount / 100,
+		Currency:         pay.Currency,
+		ChargeId:         pay.TelegramPaymentChargeId,
+		ProviderChargeId: pay.ProviderPaymentChargeId,
+		Date:             time.Now(),
+	}
+	p.reserve.JoinPlayer(mb)
+	err := p.Repository.Update(p.ctx, p.reserve)
+	st.Updated = true
+	return st, err
+}
@@ -0,0 +1,107 @@
+package bvbot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+func TestPaymentStateProvider(t *testing.T) {
+	user := person.Person{Id: uuid.New(), Firstname: "Elly", TelegramId: 100}
+	other := person.Person{Id: uuid.New(), Firstname: "Steve", TelegramId: 123}
+	stime := time.Now().Add(24 * time.Hour)
+	v := volley.NewVolley(other, stime, stime.Add(2*time.Hour))
+	v.Location = location.Location{Id: uuid.New()}
+	late := person.Person{Id: uuid.New(), Firstname: "Kate", TelegramId: 300}
+	v.Price = 500
+	v.MaxPlayers = 3
+	v.Members = []volley.Member{
+		{Player: volley.Player{Person: user}, Count: 2},
+		{Player: volley.Player{Person: other}, Count: 1},
+		{Player: volley.Player{Person: late}, Count: 1},
+	}
+	updated := volley.Volley{}
+	rep := volleyRepositoryMock{reserves: []volley.Volley{v}, updated: &updated}
+	res := NewPaymentResourcesRu()
+	newProvider := func(p person.Person, action string, msg telegram.Message) *PaymentStateProvider {
+		st := telegram.State{Prefix: "res", Separator: "_", State: "pay", Action: action, Data: v.Base64Id(),
+			ChatId: p.TelegramId}
+		bp, _ := NewBaseStateProvider(context.Background(), st, msg, p, v.Location, rep, nil, "")
+		return &PaymentStateProvider{BaseStateProvider: bp, Resources: res}
+	}
+
+	t.Run("Invoice for member share", func(t *testing.T) {
+		rlist := newProvider(user, "pay", telegram.Message{}).GetRequests()
+		if len(rlist) != 1 {
+			t.FailNow()
+		}
+		req := rlist[0].Request.(*telegram.InvoiceRequest)
+		if req.ChatId != user.TelegramId || req.Payload != "res_pay_paid_"+v.Base64Id() ||
+			req.Currency != "RUB" || req.Prices[0].Amount != 100000 {
+			t.Fail()
+		}
+	})
+
+	t.Run("No invoice for non members", func(t *testing.T) {
+		stranger := person.Person{Id: uuid.New(), TelegramId: 200}
+		if rlist := newProvider(stranger, "pay", telegram.Message{}).GetRequests(); len(rlist) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("No invoice for waiting list", func(t *testing.T) {
+		if rlist := newProvider(late, "pay", telegram.Message{}).GetRequests(); len(rlist) != 0 {
+			t.Fail()
+		}
+		if newProvider(late, "paid", telegram.Message{}).CheckPayment("RUB", 50000) == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Check payment", func(t *testing.T) {
+		sp := newProvider(user, "paid", telegram.Message{})
+		if sp.CheckPayment("RUB", 100000) != nil {
+			t.Fail()
+		}
+		if sp.CheckPayment("RUB", 50000) == nil || sp.CheckPayment("USD", 100000) == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Mark member as paid", func(t *testing.T) {
+		msg := telegram.Message{SuccessfulPayment: &telegram.SuccessfulPayment{Currency: "RUB", TotalAmount: 100000,
+			InvoicePayload: "res_pay_paid_" + v.Base64Id(), TelegramPaymentChargeId: "tg_charge"}}
+		st, err := newProvider(user, "paid", msg).Proceed()
+		if err != nil || st.State != "pay" || st.Action != "done" || !st.Updated {
+			t.Fail()
+		}
+		mb := updated.GetMember(user.Id)
+		if !mb.GetPaid() || mb.Payment.Id == uuid.Nil || mb.Payment.Sum != 1000 || mb.Payment.ChargeId != "tg_charge" {
+			t.Fail()
+		}
+		if updated.GetMember(other.Id).GetPaid() {
+			t.Fail()
+		}
+	})
+
+	t.Run("Skip applied charge", func(t *testing.T) {
+		msg := telegram.Message{SuccessfulPayment: &telegram.SuccessfulPayment{Currency: "RUB", TotalAmount: 100000,
+			InvoicePayload: "res_pay_paid_" + v.Base64Id(), TelegramPaymentChargeId: "tg_charge"}}
+		rep.reserves[0] = updated
+		updated = volley.Volley{}
+		st, err := newProvider(user, "paid", msg).Proceed()
+		if err != nil || st.Action != "done" || st.Updated {
+			t.Fail()
+		}
+		if updated.Id != uuid.Nil {
+			t.Fail()
+		}
+	})
+}
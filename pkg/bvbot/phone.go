@@ -0,0 +1,100 @@
+package bvbot
+
+import (
+	"fmt"
+	"volleybot/pkg/telegram"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type PhoneResources struct {
+	CancelBtn     string `json:"cancel_btn"`
+	CancelMessage string `json:"cancel_message"`
+	DoneMessage   string `json:"done_message"`
+	Message       string `json:"message"`
+	Placeholder   string `json:"placeholder"`
+	ShareBtn      string `json:"share_btn"`
+}
+
+func NewPhoneResourcesRu() (r PhoneResources) {
+	r.CancelBtn = "Отмена"
+	r.CancelMessage = "Телефон не изменен."
+	r.DoneMessage = "Успешно! Телефон %s сохранен."
+	r.Message = "Поделись номером телефона, чтобы организатор мог связаться с тобой по оплате."
+	r.Placeholder = "Нажми кнопку ниже"
+	r.ShareBtn = "📱 Поделиться номером"
+	return
+}
+
+type PhoneStateProvider struct {
+	PlayerStateProvider
+	Resources PhoneResources
+}
+
+func (p PhoneStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	switch p.State.Action {
+	case "phone":
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.Message,
+			ReplyMarkup: p.GetKeyboardHelper().GetKeyboard()}
+		p.State.MessageId = -1
+		return append(rlist, telegram.StateRequest{State: p.State, Request: &req})
+	case "done", "canceled":
+		txt := p.Resources.CancelMessage
+		if p.State.Action == "done" {
+			txt = fmt.Sprintf(p.Resources.DoneMessage, p.Player.Phone)
+		}
+		rlist = append(rlist, telegram.StateRequest{Clear: true, State: p.State})
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: txt,
+			ReplyMarkup: telegram.ReplyKeyboardRemove{RemoveKeyboard: true}}
+		return append(rlist, telegram.StateRequest{Request: &req})
+	}
+	return
+}
+
+func (p PhoneStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	kh := telegram.NewReplyKeyboardHelper()
+	kh.AddContactButton(p.Resources.ShareBtn)
+	kh.Buttons = append(kh.Buttons, telegram.KeyboardButton{Text: p.Resources.CancelBtn})
+	kh.Placeholder = p.Resources.Placeholder
+	return kh
+}
+
+func (p *PhoneStateProvider) Proceed() (telegram.State, error) {
+	st := p.State
+	if st.Action != "phone" || st.MessageId != -1 {
+		return st, nil
+	}
+	contact := p.Message.Contact
+	switch {
+	case contact != nil && contact.UserId == p.Person.TelegramId:
+		var err error
+		if p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person); err != nil {
+			log.WithFields(log.Fields{
+				"package":  "bvbot",
+				"function": "Proceed",
+				"struct":   "PhoneStateProvider",
+				"person":   p.Person,
+				"error":    err,
+			}).Error("can't get player information")
+		}
+		p.Player.Phone = contact.PhoneNumber
+		if err = p.Repository.UpdatePlayer(p.ctx, p.Player); err != nil {
+			log.WithFields(log.Fields{
+				"package":  "bvbot",
+				"function": "Proceed",
+				"struct":   "PhoneStateProvider",
+				"person":   p.Person,
+				"error":    err,
+			}).Error("can't save player phone")
+			return st, err
+		}
+		p.State.Action = "done"
+	case contact != nil || p.Message.Text == p.Resources.CancelBtn:
+		p.State.Action = "canceled"
+	default:
+		p.State.Action = "retry"
+		return st, nil
+	}
+	st.Action = "saved"
+	return st, nil
+}
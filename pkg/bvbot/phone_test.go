@@ -0,0 +1,73 @@
+package bvbot
+
+import (
+	"context"
+	"testing"
+
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+func TestPhoneStateProvider(t *testing.T) {
+	user := person.Person{Id: uuid.New(), Firstname: "Elly", TelegramId: 100}
+	player := volley.Player{}
+	rep := volleyRepositoryMock{player: &player}
+	res := NewPhoneResourcesRu()
+	newProvider := func(mid int, msg telegram.Message) *PhoneStateProvider {
+		st := telegram.State{Prefix: "res", Separator: "_", State: "phone", Action: "phone",
+			ChatId: user.TelegramId, MessageId: mid}
+		bp, _ := NewBaseStateProvider(context.Background(), st, msg, user, location.Location{}, rep, nil, "")
+		pp := PlayerStateProvider{BaseStateProvider: bp, Resources: NewProfileResourcesRu()}
+		return &PhoneStateProvider{PlayerStateProvider: pp, Resources: res}
+	}
+
+	t.Run("Prompt", func(t *testing.T) {
+		rlist := newProvider(0, telegram.Message{}).GetRequests()
+		if len(rlist) != 1 || rlist[0].State.MessageId != -1 {
+			t.FailNow()
+		}
+		kbd, ok := rlist[0].Request.(*telegram.MessageRequest).ReplyMarkup.(telegram.ReplyKeyboardMarkup)
+		if !ok || len(kbd.Keyboard) != 2 || !kbd.Keyboard[0][0].RequestContact || kbd.Keyboard[1][0].Text != res.CancelBtn {
+			t.Fail()
+		}
+	})
+
+	tests := map[string]struct {
+		msg    telegram.Message
+		action string
+		phone  string
+		count  int
+	}{
+		"Own contact": {
+			msg:    telegram.Message{Contact: &telegram.Contact{PhoneNumber: "+79990001122", UserId: user.TelegramId}},
+			action: "done", phone: "+79990001122", count: 2,
+		},
+		"Foreign contact": {
+			msg:    telegram.Message{Contact: &telegram.Contact{PhoneNumber: "+79990003344", UserId: 123}},
+			action: "canceled", count: 2,
+		},
+		"Cancel": {msg: telegram.Message{Text: res.CancelBtn}, action: "canceled", count: 2},
+		"Retry":  {msg: telegram.Message{Text: "hello"}, action: "retry"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			player = volley.Player{}
+			sp := newProvider(-1, test.msg)
+			if _, err := sp.Proceed(); err != nil || sp.State.Action != test.action || player.Phone != test.phone {
+				t.FailNow()
+			}
+			rlist := sp.GetRequests()
+			if len(rlist) != test.count {
+				t.FailNow()
+			}
+			if test.count > 0 && !rlist[0].Clear {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package bvbot
+
+import (
+	"fmt"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+const PollJoinOption = "0"
+
+type PollResources struct {
+	Question    string `json:"question"`
+	JoinOption  string `json:"join_option"`
+	LeaveOption string `json:"leave_option"`
+}
+
+func NewPollResourcesRu() (r PollResources) {
+	r.Question = "%s\nКто будет?"
+	r.JoinOption = "😀 Буду"
+	r.LeaveOption = "😞 Не смогу"
+	return
+}
+
+type PollStateProvider struct {
+	BaseStateProvider
+	Resources PollResources
+}
+
+func (p PollStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	if p.State.Action != "poll" || p.reserve.Id == uuid.Nil {
+		return
+	}
+	if p.State.MessageId == 0 {
+		if req := p.GetPoll(); req != nil {
+			rlist = append(rlist, telegram.StateRequest{State: p.State, Request: req})
+		}
+		return
+	}
+	if p.reserve.Canceled {
+		req := telegram.StopPollRequest{ChatId: p.State.ChatId, MessageId: p.State.MessageId}
+		rlist = append(rlist, telegram.StateRequest{State: p.State, Request: req, Clear: true})
+	}
+	return
+}
+
+func (p PollStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	return nil
+}
+
+func (p PollStateProvider) GetPoll() *telegram.SendPollRequest {
+	if p.State.ChatId == 0 || p.reserve.Canceled || !p.reserve.Ordered() {
+		return nil
+	}
+	rview := volley.NewTelegramViewRu(p.reserve)
+	return &telegram.SendPollRequest{
+		ChatId:              p.State.ChatId,
+		Question:            fmt.Sprintf(p.Resources.Question, rview.GetTitle()),
+		Options:             []string{p.Resources.JoinOption, p.Resources.LeaveOption},
+		DisableNotification: p.State.ChatId < 0,
+	}
+}
+
+func (p PollStateProvider) Proceed() (telegram.State, error) {
+	st := p.State
+	if st.Action != "vote" {
+		return st, nil
+	}
+	st.Action = "voted"
+	if p.reserve.Id == uuid.Nil || p.reserve.Canceled || !p.reserve.Ordered() {
+		return st, nil
+	}
+	mb := p.reserve.GetMember(p.Person.Id)
+	switch {
+	case p.State.Value == PollJoinOption && mb.Count == 0:
+		if mb.Id == uuid.Nil {
+			pl, err := p.Repository.GetPlayer(p.ctx, p.Person)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"package":  "bvbot",
+					"function": "Proceed",
+					"struct":   "PollStateProvider",
+					"person":   p.Person,
+					"error":    err,
+				}).Error("can't get player information")
+				pl = volley.Player{Person: p.Person}
+			}
+			mb = volley.Member{Player: pl}
+		}
+		mb.Count = 1
+	case p.State.Value != PollJoinOption && mb.Count > 0:
+		mb.Count = 0
+	default:
+		return st, nil
+	}
+	p.reserve.JoinPlayer(mb)
+	err := p.Repository.Update(p.ctx, p.reserve)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  "bvbot",
+			"function": "Proceed",
+			"struct":   "PollStateProvider",
+			"state":    p.State,
+			"error":    err,
+		}).Error("can't apply poll answer to reserve: " + p.reserve.Id.String())
+		return st, err
+	}
+	st.Updated = true
+	return st, nil
+}
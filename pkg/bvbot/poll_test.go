@@ -0,0 +1,106 @@
+package bvbot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+func TestPollStateProvider(t *testing.T) {
+	user := person.Person{Id: uuid.New(), Firstname: "Elly", TelegramId: 100}
+	other := person.Person{Id: uuid.New(), Firstname: "Steve", TelegramId: 123}
+	stime := time.Date(2021, 12, 04, 15, 0, 0, 0, time.UTC)
+	v := volley.NewVolley(other, stime, stime.Add(2*time.Hour))
+	v.Location = location.Location{Id: uuid.New()}
+	v.Members = []volley.Member{{Player: volley.Player{Person: other}, Count: 1}}
+	canceled := v
+	canceled.Id = uuid.New()
+	canceled.Canceled = true
+	updated := volley.Volley{}
+	rep := volleyRepositoryMock{reserves: []volley.Volley{v, canceled}, updated: &updated,
+		levels: map[uuid.UUID]volley.PlayerLevel{user.Id: volley.Middle}}
+	res := NewPollResourcesRu()
+	newProvider := func(r volley.Volley, p person.Person, st telegram.State) PollStateProvider {
+		st.Prefix, st.Separator, st.State, st.Data = "res", "_", "poll", r.Base64Id()
+		bp, _ := NewBaseStateProvider(context.Background(), st, telegram.Message{}, p, v.Location, rep, nil, "")
+		bp.reserve.Members = append([]volley.Member{}, bp.reserve.Members...)
+		return PollStateProvider{BaseStateProvider: bp, Resources: res}
+	}
+
+	t.Run("Send poll", func(t *testing.T) {
+		rlist := newProvider(v, other, telegram.State{Action: "poll", ChatId: -100}).GetRequests()
+		if len(rlist) != 1 {
+			t.FailNow()
+		}
+		req := rlist[0].Request.(*telegram.SendPollRequest)
+		if req.ChatId != -100 || req.IsAnonymous || !req.DisableNotification ||
+			!strings.HasPrefix(req.Question, "🏐 Сб, 04.12 15:00-17:00") ||
+			len(req.Options) != 2 || req.Options[0] != res.JoinOption {
+			t.Fail()
+		}
+	})
+
+	t.Run("No poll for canceled reserve", func(t *testing.T) {
+		if rlist := newProvider(canceled, other, telegram.State{Action: "poll", ChatId: -100}).GetRequests(); len(rlist) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Stop poll for canceled reserve", func(t *testing.T) {
+		rlist := newProvider(canceled, other, telegram.State{Action: "poll", ChatId: -100, MessageId: 12}).GetRequests()
+		if len(rlist) != 1 || !rlist[0].Clear {
+			t.FailNow()
+		}
+		if req := rlist[0].Request.(telegram.StopPollRequest); req.ChatId != -100 || req.MessageId != 12 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Keep running poll", func(t *testing.T) {
+		if rlist := newProvider(v, other, telegram.State{Action: "poll", ChatId: -100, MessageId: 12}).GetRequests(); len(rlist) != 0 {
+			t.Fail()
+		}
+	})
+
+	tests := map[string]struct {
+		p       person.Person
+		value   string
+		updated bool
+		count   int
+	}{
+		"Join":                  {p: user, value: PollJoinOption, updated: true, count: 1},
+		"Join already joined":   {p: other, value: PollJoinOption},
+		"Leave":                 {p: other, value: "1", updated: true, count: 0},
+		"Retract":               {p: other, value: "", updated: true, count: 0},
+		"Leave without joining": {p: user, value: "1"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			updated = volley.Volley{}
+			st, err := newProvider(v, test.p, telegram.State{Action: "vote", ChatId: test.p.TelegramId, Value: test.value}).Proceed()
+			if err != nil || st.State != "poll" || st.Action != "voted" || st.Updated != test.updated {
+				t.FailNow()
+			}
+			if test.updated && updated.GetMember(test.p.Id).Count != test.count {
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("Joined with player level", func(t *testing.T) {
+		updated = volley.Volley{}
+		newProvider(v, user, telegram.State{Action: "vote", ChatId: user.TelegramId, Value: PollJoinOption}).Proceed()
+		if updated.GetMember(user.Id).Level != volley.Middle {
+			t.Fail()
+		}
+	})
+}
@@ -19,7 +19,7 @@ type PlayerStateProvider struct {
 
 func (p PlayerStateProvider) Proceed() (st telegram.State, err error) {
 	if p.State.Updated {
-		p.Repository.UpdatePlayer(p.Player)
+		p.Repository.UpdatePlayer(p.ctx, p.Player)
 		p.State.Updated = false
 	}
 	return p.BaseStateProvider.Proceed()
@@ -37,7 +37,7 @@ func (p PlayerStateProvider) GetMR() *telegram.MessageRequest {
 
 func (p PlayerStateProvider) GetRequests() (reqlist []telegram.StateRequest) {
 	var err error
-	if p.Player, err = p.Repository.GetPlayer(p.Person); err != nil {
+	if p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person); err != nil {
 		log.WithFields(log.Fields{
 			"package":  "bvbot",
 			"function": "GetRequests",
@@ -46,6 +46,11 @@ func (p PlayerStateProvider) GetRequests() (reqlist []telegram.StateRequest) {
 			"error":    err,
 		}).Error("can't get player information")
 	}
+	if p.RatingRepository != nil {
+		if r, err := p.RatingRepository.Get(p.ctx, p.Location, p.Person.Id); err == nil {
+			p.Player.Rating = r.Value
+		}
+	}
 	var sreq telegram.StateRequest
 	sreq.State = p.State
 	sreq.Request = p.GetEditMR(p.GetMR())
@@ -75,6 +80,8 @@ func (p ProfileStateProvider) GetKeyboardHelper() (kh telegram.KeyboardHelper) {
 			Action: "sex", Text: res.SexBtn})
 		ah.Actions = append(ah.Actions, telegram.ActionButton{
 			Action: "notifies", Text: res.NotifiesBtn})
+		ah.Actions = append(ah.Actions, telegram.ActionButton{
+			Action: "phone", Text: res.PhoneBtn})
 	}
 	return &ah
 }
@@ -116,7 +123,7 @@ func (p PLevelStateProvider) Proceed() (telegram.State, error) {
 				"error":    err,
 			}).Error("can't parse level value")
 		}
-		p.Player, err = p.Repository.GetPlayer(p.Person)
+		p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
@@ -167,7 +174,7 @@ func (p SexStateProvider) Proceed() (telegram.State, error) {
 				"error":    err,
 			}).Error("can't parse sex value")
 		}
-		p.Player, err = p.Repository.GetPlayer(p.Person)
+		p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
@@ -240,7 +247,7 @@ func (p NotifyCancelStateProvider) Proceed() (telegram.State, error) {
 	kh := p.GetKeyboardHelper().(*telegram.EnumKeyboardHelper)
 	if st.Action == "set" {
 		var err error
-		p.Player, err = p.Repository.GetPlayer(p.Person)
+		p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
@@ -266,7 +273,7 @@ func (p NotifyChangeStateProvider) Proceed() (telegram.State, error) {
 	kh := p.GetKeyboardHelper().(*telegram.EnumKeyboardHelper)
 	if st.Action == "set" {
 		var err error
-		p.Player, err = p.Repository.GetPlayer(p.Person)
+		p.Player, err = p.Repository.GetPlayer(p.ctx, p.Person)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"package":  "bvbot",
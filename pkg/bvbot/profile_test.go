@@ -1,6 +1,7 @@
 package bvbot
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"volleybot/pkg/domain/location"
@@ -21,6 +22,7 @@ func TestProfileStateKbd(t *testing.T) {
 		},
 		{
 			{Text: res.NotifiesBtn, CallbackData: "res_profile_notifies"},
+			{Text: res.PhoneBtn, CallbackData: "res_profile_phone"},
 		},
 	}
 
@@ -42,7 +44,7 @@ func TestProfileStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_profile_profile")
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, loc, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, loc, nil, nil, "")
 			pp := PlayerStateProvider{BaseStateProvider: bp, Resources: res}
 			sp := ProfileStateProvider{PlayerStateProvider: pp}
 			acts := sp.GetKeyboardHelper().GetKeyboard().(telegram.InlineKeyboardMarkup).InlineKeyboard
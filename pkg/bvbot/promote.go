@@ -0,0 +1,86 @@
+package bvbot
+
+import (
+	"fmt"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+type PromoteResources struct {
+	ConfirmBtn       string        `json:"confirm_btn"`
+	ConfirmedMessage string        `json:"confirmed_message"`
+	LeaveBtn         string        `json:"leave_btn"`
+	Message          string        `json:"message"`
+	ReleasedMessage  string        `json:"released_message"`
+	Window           time.Duration `json:"window"`
+}
+
+func NewPromoteResourcesRu() (r PromoteResources) {
+	r.ConfirmBtn = "👍 Точно буду"
+	r.ConfirmedMessage = "%s\nОтлично, ты в основном составе!"
+	r.LeaveBtn = "😞 Не смогу"
+	r.Message = "%s\nОсвободилось место, ты в основном составе! Подтверди участие до %s, иначе место перейдет следующему в резерве."
+	r.ReleasedMessage = "%s\nМесто передано следующему в резерве."
+	r.Window = 2 * time.Hour
+	return
+}
+
+type PromoteStateProvider struct {
+	BaseStateProvider
+	Resources PromoteResources
+}
+
+func (p PromoteStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	if p.State.Action != p.State.State || p.reserve.Id == uuid.Nil {
+		return
+	}
+	mb := p.reserve.GetMemberByTelegramId(p.State.ChatId)
+	rview := volley.NewTelegramViewRu(p.reserve)
+	if p.State.MessageId == 0 {
+		if !mb.Pending() || p.reserve.Canceled {
+			return
+		}
+		deadline := mb.PromotedAt.Add(p.Resources.Window).In(p.reserve.StartTime.Location())
+		txt := fmt.Sprintf(p.Resources.Message, rview.GetTitle(), deadline.Format("15:04 02.01"))
+		mr := p.CreateMR(p.State.ChatId, txt, "", p.GetKeyboardHelper().GetKeyboard())
+		return append(rlist, telegram.StateRequest{State: p.State, Request: mr})
+	}
+	if mb.Pending() && !p.reserve.Canceled {
+		return
+	}
+	txt := fmt.Sprintf(p.Resources.ReleasedMessage, rview.GetTitle())
+	if mb.Count > 0 && !p.reserve.Canceled {
+		txt = fmt.Sprintf(p.Resources.ConfirmedMessage, rview.GetTitle())
+	}
+	req := &telegram.EditMessageTextRequest{ChatId: p.State.ChatId, MessageId: p.State.MessageId, Text: txt}
+	return append(rlist, telegram.StateRequest{State: p.State, Request: req, Clear: true})
+}
+
+func (p PromoteStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	kh := telegram.ActionsKeyboardHelper{Columns: 2}
+	kh.State = p.State
+	kh.Actions = []telegram.ActionButton{
+		{Action: "confirm", Text: p.Resources.ConfirmBtn},
+		{Action: "leave", Text: p.Resources.LeaveBtn},
+	}
+	return &kh
+}
+
+func (p PromoteStateProvider) Proceed() (telegram.State, error) {
+	mb := p.reserve.GetMemberByTelegramId(p.Person.TelegramId)
+	switch p.State.Action {
+	case "confirm":
+		p.State.Updated = p.reserve.ConfirmPlayer(mb.Id)
+	case "leave":
+		if mb.Pending() {
+			mb.Count = 0
+			p.reserve.JoinPlayer(mb)
+			p.State.Updated = true
+		}
+	}
+	p.State.Action = p.State.State
+	return p.BaseStateProvider.Proceed()
+}
@@ -12,18 +12,28 @@ type Resources struct {
 	Courts        CourtsResources
 	Cancel        CancelResources
 	Description   DescResources
+	Inline        InlineResources
 	Join          JoinResources
 	Level         LevelResources
 	List          ListResources
+	LocationChat  LocationChatResources
 	Main          MainResources
 	MaxPlayer     MaxPlayersResources
+	Payment       PaymentResources
+	Phone         PhoneResources
+	Poll          PollResources
 	Profile       ProfileResources
+	Promote       PromoteResources
+	Results       ResultsResources
 	RemovePlayer  RemovePlayerResources
 	Price         PriceResources
 	Settings      SettingsResources
 	Sets          SetsResources
+	Schedule      ScheduleResources
 	Show          ShowResources
 	SendResources SendResources
+	Teams         TeamsResources
+	Tournament    TournamentResources
 	BackBtn       string
 	DescMessage   string
 }
@@ -42,12 +52,12 @@ type MainResources struct {
 }
 
 func NewMainResourcesRu() (r MainResources) {
-	r.ListCaption = "* Ближайшие активности *"
+	r.ListCaption = "Ближайшие активности"
 	r.ListDateBtn = "Найти по дате"
 	r.NewReserveBtn = "✨ Забронировать"
 	r.NoReservesMessage = "На ближайшее время активности не запланированы"
 	r.Text = "Выберите действие"
-	r.ParseMode = "Markdown"
+	r.ParseMode = telegram.ParseModeHTML
 	r.ProfileBtn = "😎 Профиль"
 	r.ConfigBtn = "🛠 Настройки"
 	r.TodayBtn = "Сегодня"
@@ -62,10 +72,10 @@ type ListResources struct {
 }
 
 func NewListResourcesRu() (r ListResources) {
-	r.ListCaption = "* Ближайшие активности *"
+	r.ListCaption = "Ближайшие активности"
 	r.NoReservesMessage = "На ближайшее время активности не запланированы"
 	r.Text = "Выберите действие"
-	r.ParseMode = "Markdown"
+	r.ParseMode = telegram.ParseModeHTML
 	return
 }
 
@@ -104,8 +114,13 @@ type ActionsResources struct {
 	CopyBtn         string `json:"copy_btn"`
 	CopyDoneMessage string `json:"copy_done_msg"`
 	PaidBtn         string `json:"paid"`
+	PollBtn         string `json:"poll_btn"`
 	PublishBtn      string `json:"publish_btn"`
+	ResultsBtn      string `json:"results_btn"`
+	ScheduleBtn     string `json:"schedule_btn"`
 	SendBtn         string `json:"send_btn"`
+	TeamsBtn        string `json:"teams_btn"`
+	TournamentBtn   string `json:"tournament_btn"`
 	RemovePlayerBtn string `json:"remove_player_btn"`
 }
 
@@ -115,8 +130,13 @@ func NewActionsResourcesRu() (r ActionsResources) {
 	r.CopyBtn = "🫂 Копировать"
 	r.CopyDoneMessage = "Копия сделана! 👆"
 	r.PaidBtn = "💰 Оплаты"
+	r.PollBtn = "📊 Опрос"
 	r.PublishBtn = "Опубликовать"
+	r.ResultsBtn = "📝 Результаты"
+	r.ScheduleBtn = "🔁 Повторять"
 	r.SendBtn = "Отправить"
+	r.TeamsBtn = "👥 Команды"
+	r.TournamentBtn = "🏆 Турнир"
 	r.RemovePlayerBtn = "Удалить игрока"
 	return
 }
@@ -131,7 +151,7 @@ type CancelResources struct {
 func NewCancelResourcesRu() (r CancelResources) {
 	r.BackBtn = "Передумал"
 	r.ConfirmBtn = "🧨 Уверен"
-	r.Text = "\n🧨*ВНИМАНИЕ!!!*🧨\nИгра будет отменена для всех участников. Если есть желание только выписаться, лучше воспользоваться кнопкой \"Не буду\""
+	r.Text = "\n🧨<b>ВНИМАНИЕ!!!</b>🧨\nИгра будет отменена для всех участников. Если есть желание только выписаться, лучше воспользоваться кнопкой \"Не буду\""
 	return
 }
 
@@ -146,6 +166,21 @@ func NewSendResourcesRu() (r SendResources) {
 	return
 }
 
+type LocationChatResources struct {
+	BindBtn       string `json:"bind_btn"`
+	BoundMessage  string `json:"bound_message"`
+	DeniedMessage string `json:"denied_message"`
+	OfferMessage  string `json:"offer_message"`
+}
+
+func NewLocationChatResourcesRu() (r LocationChatResources) {
+	r.BindBtn = "Сделать чатом площадки"
+	r.BoundMessage = "Готово! Этот чат теперь чат площадки."
+	r.DeniedMessage = "Привязать чат может только администратор площадки"
+	r.OfferMessage = "Привет! Сделать этот чат основным чатом площадки?"
+	return
+}
+
 type RemovePlayerResources struct {
 	BackBtn         string
 	Message         string
@@ -164,6 +199,7 @@ type ProfileResources struct {
 	NotifiesBtn     string
 	NotifyBtn       string
 	ParseMode       string
+	PhoneBtn        string
 	SexBtn          string
 	Text            string
 }
@@ -173,7 +209,8 @@ func NewProfileResourcesRu() (r ProfileResources) {
 	r.LevelBtn = "Уровень"
 	r.NotifiesBtn = "Оповещения"
 	r.NotifyBtn = "При изменениях"
-	r.ParseMode = "Markdown"
+	r.ParseMode = telegram.ParseModeHTML
+	r.PhoneBtn = "📱 Телефон"
 	r.SexBtn = "Пол"
 	r.Text = ""
 	return
@@ -210,7 +247,7 @@ type MaxPlayersResources struct {
 func NewMaxPlayersResourcesRu() (r MaxPlayersResources) {
 	r.BackBtn = "Назад"
 	r.Columns = 4
-	r.GroupChatWarning = "⚠️*Внимание* - здесь функция добавления игроков ограничена числом игроков записи. " +
+	r.GroupChatWarning = "⚠️<b>Внимание</b> - здесь функция добавления игроков ограничена числом игроков записи. " +
 		"В чате с ботом можно добавить больше игроков в резерв!"
 	return
 }
@@ -258,7 +295,7 @@ type ConfigResources struct {
 }
 
 func NewConfigResourcesRu() (cfg ConfigResources) {
-	cfg.ParseMode = "markdown"
+	cfg.ParseMode = telegram.ParseModeHTML
 	cfg.Courts = NewConfigCourtsResourcesRu()
 	cfg.Price = NewConfigPriceResourcesRu()
 	return
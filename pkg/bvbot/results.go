@@ -0,0 +1,226 @@
+package bvbot
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+var scoreRe = regexp.MustCompile(`^\s*(\d{1,2})\s*[:\-\s]\s*(\d{1,2})\s*$`)
+
+type ResultsResources struct {
+	CourtBtn       string `json:"court_btn"`
+	ErrorMessage   string `json:"error_message"`
+	NoTeamsMessage string `json:"no_teams_message"`
+	Placeholder    string `json:"placeholder"`
+	RateBtn        string `json:"rate_btn"`
+	RatedMessage   string `json:"rated_message"`
+	ScoreBtn       string `json:"score_btn"`
+	ScoreMessage   string `json:"score_message"`
+	UndoBtn        string `json:"undo_btn"`
+}
+
+func NewResultsResourcesRu() (r ResultsResources) {
+	r.CourtBtn = "🏐 Корт %d"
+	r.ErrorMessage = "Не понял счет. Отправь его в виде 21:15."
+	r.NoTeamsMessage = "Чтобы записать счет, сначала составь команды."
+	r.Placeholder = "21:15"
+	r.RateBtn = "✅ Подвести итоги"
+	r.RatedMessage = "Рейтинг обновлен:"
+	r.ScoreBtn = "🏐 Записать сет"
+	r.ScoreMessage = "Отправь счет сета на корте %d, например 21:15. Первым укажи счет первой команды корта."
+	r.UndoBtn = "↩️ Удалить сет"
+	return
+}
+
+type ResultsStateProvider struct {
+	BaseStateProvider
+	Resources ResultsResources
+	changes   []volley.RatingChange
+}
+
+func (p ResultsStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	if p.State.Action == "rate" {
+		if len(p.changes) == 0 {
+			return
+		}
+		txt := p.Resources.RatedMessage
+		for _, c := range p.changes {
+			txt += fmt.Sprintf("\n%s: %d → %d (%+d)", p.reserve.GetMember(c.PersonId).String(), c.Before, c.After, c.After-c.Before)
+		}
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: txt}
+		return append(rlist, telegram.StateRequest{Request: &req})
+	}
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p ResultsStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	res := p.Resources
+	rview := volley.NewResultsTelegramViewRu(p.reserve)
+	kh := telegram.ActionsKeyboardHelper{Columns: 2}
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(rview.GetText())
+	if p.reserve.Canceled || p.reserve.Rated || p.State.ChatId != p.Person.TelegramId || !p.IsOrganizer() {
+		return &kh
+	}
+	if len(p.reserve.Teams) == 0 {
+		kh.Text += "\n" + telegram.EscapeText(rview.ParseMode, res.NoTeamsMessage)
+		return &kh
+	}
+	courts := len(p.reserve.Teams) / 2
+	for c := 1; c <= courts; c++ {
+		txt := res.ScoreBtn
+		if courts > 1 {
+			txt = fmt.Sprintf(res.CourtBtn, c)
+		}
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "rscore", Value: strconv.Itoa(c), Text: txt})
+	}
+	if len(p.reserve.Results) > 0 {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "undo", Text: res.UndoBtn})
+		if p.RatingRepository != nil {
+			kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "rate", Text: res.RateBtn})
+		}
+	}
+	return &kh
+}
+
+func (p *ResultsStateProvider) Proceed() (telegram.State, error) {
+	bp := p.BaseStateProvider
+	if !p.IsOrganizer() || p.reserve.Rated {
+		bp.State.Action = p.State.State
+		return bp.Proceed()
+	}
+	var err error
+	switch p.State.Action {
+	case "undo":
+		bp.reserve.RemoveLastSet()
+		bp.State.Updated = true
+		bp.State.Action = p.State.State
+	case "rate":
+		if p.changes, err = p.Rate(); err == nil && len(p.changes) > 0 {
+			bp.reserve.Rated = true
+			bp.State.Updated = true
+		}
+		bp.State.Action = p.State.State
+	}
+	st, uerr := bp.Proceed()
+	if err == nil {
+		err = uerr
+	}
+	return st, err
+}
+
+// Rate applies the game results to ratings of its members at the game location.
+func (p ResultsStateProvider) Rate() (changes []volley.RatingChange, err error) {
+	if p.RatingRepository == nil || len(p.reserve.Results) == 0 {
+		return
+	}
+	ratings := map[uuid.UUID]volley.Rating{}
+	for _, tm := range p.reserve.Teams {
+		for _, tp := range tm.Players {
+			if _, ok := ratings[tp.Id]; ok || tp.Guest > 0 {
+				continue
+			}
+			r, rerr := p.RatingRepository.Get(p.ctx, p.reserve.Location, tp.Id)
+			if errors.Is(rerr, volley.ErrRatingNotFound) {
+				r = volley.NewRating(p.reserve.Location, tp.Id)
+			} else if rerr != nil {
+				log.WithFields(log.Fields{
+					"package":  "bvbot",
+					"function": "Rate",
+					"struct":   "ResultsStateProvider",
+					"state":    p.State,
+					"error":    rerr,
+				}).Error("can't get rating of person: " + tp.Id.String())
+				return nil, rerr
+			}
+			ratings[tp.Id] = r
+		}
+	}
+	changes = volley.RateGame(p.reserve, ratings, time.Now())
+	if err = p.RatingRepository.Apply(p.ctx, changes); err != nil {
+		log.WithFields(log.Fields{
+			"package":  "bvbot",
+			"function": "Rate",
+			"struct":   "ResultsStateProvider",
+			"state":    p.State,
+			"error":    err,
+		}).Error("can't apply ratings for reserve: " + p.reserve.Id.String())
+		return nil, err
+	}
+	return
+}
+
+type ScoreStateProvider struct {
+	BaseStateProvider
+	Resources ResultsResources
+}
+
+// GetRequests asks for the score of the court from the value. Values are not
+// saved with states, so the awaiting state keeps the court in its action.
+func (p ScoreStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	switch {
+	case p.State.Action == "rscore" || strings.HasPrefix(p.State.Action, "court"):
+		court := p.Court()
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: fmt.Sprintf(p.Resources.ScoreMessage, court),
+			ReplyMarkup: p.GetKeyboardHelper().GetKeyboard()}
+		p.State.MessageId = -1
+		p.State.Action = "court" + strconv.Itoa(court)
+		p.State.Value = ""
+		return append(rlist, telegram.StateRequest{State: p.State, Request: &req})
+	case p.State.Action == "retry":
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.ErrorMessage}
+		return append(rlist, telegram.StateRequest{Request: &req})
+	case p.State.Action == "done":
+		return append(rlist, telegram.StateRequest{Clear: true, State: p.State})
+	}
+	return
+}
+
+func (p ScoreStateProvider) Court() (court int) {
+	if p.State.Action == "rscore" {
+		court, _ = strconv.Atoi(p.State.Value)
+	} else {
+		court, _ = strconv.Atoi(strings.TrimPrefix(p.State.Action, "court"))
+	}
+	return
+}
+
+func (p ScoreStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	return telegram.ForceReplyHelper{Placeholder: p.Resources.Placeholder}
+}
+
+func (p *ScoreStateProvider) Proceed() (telegram.State, error) {
+	st := p.State
+	if !strings.HasPrefix(st.Action, "court") || st.MessageId != -1 {
+		return st, nil
+	}
+	court := p.Court()
+	m := scoreRe.FindStringSubmatch(p.Message.Text)
+	if m == nil || !p.IsOrganizer() || p.reserve.Rated {
+		p.State.Action = "retry"
+		return st, nil
+	}
+	a, _ := strconv.Atoi(m[1])
+	b, _ := strconv.Atoi(m[2])
+	if err := p.reserve.AddSet(court, a, b); err != nil {
+		p.State.Action = "retry"
+		return st, nil
+	}
+	p.State.Action = "done"
+	bp := p.BaseStateProvider
+	bp.State.State = "rscore"
+	bp.State.Action = "results"
+	bp.State.MessageId = 0
+	bp.State.Value = ""
+	bp.State.Updated = true
+	return bp.Proceed()
+}
@@ -0,0 +1,219 @@
+package bvbot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+)
+
+type ScheduleResources struct {
+	DaysAhead     int    `json:"days_ahead"`
+	DaysBtn       string `json:"days_btn"`
+	DaysMessage   string `json:"days_message"`
+	DeleteBtn     string `json:"delete_btn"`
+	FutureBtn     string `json:"future_btn"`
+	FutureMessage string `json:"future_message"`
+	Hint          string `json:"hint"`
+	PauseBtn      string `json:"pause_btn"`
+	ResumeBtn     string `json:"resume_btn"`
+}
+
+func NewScheduleResourcesRu() (r ScheduleResources) {
+	r.DaysAhead = 14
+	r.DaysBtn = "📅 Дни недели"
+	r.DaysMessage = "Выбери дни недели для игр"
+	r.DeleteBtn = "🗑 Удалить"
+	r.FutureBtn = "🔁 Ко всем будущим"
+	r.FutureMessage = "Настройки игры применены к будущим играм расписания: %d"
+	r.Hint = "Изменения игры применяются только к ней. Чтобы перенести их на все будущие игры, нажми «Ко всем будущим»."
+	r.PauseBtn = "⏸ Пауза"
+	r.ResumeBtn = "▶️ Возобновить"
+	return
+}
+
+func (p BaseStateProvider) GetSchedule() (s volley.Schedule, err error) {
+	if p.ScheduleRepository == nil || p.reserve.ScheduleId == uuid.Nil {
+		return s, volley.ErrScheduleNotFound
+	}
+	return p.ScheduleRepository.Get(p.ctx, p.reserve.ScheduleId)
+}
+
+func (p BaseStateProvider) IsOrganizer() bool {
+	return p.reserve.Person.TelegramId == p.Person.TelegramId || p.Person.CheckLocationRole(p.reserve.Location, "admin")
+}
+
+type ScheduleStateProvider struct {
+	BaseStateProvider
+	Resources ScheduleResources
+	applied   int
+}
+
+func (p ScheduleStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	if p.State.Action == "future" {
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: fmt.Sprintf(p.Resources.FutureMessage, p.applied)}
+		return append(rlist, telegram.StateRequest{Request: &req})
+	}
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p ScheduleStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	res := p.Resources
+	kh := telegram.ActionsKeyboardHelper{Columns: 2}
+	sch, err := p.GetSchedule()
+	if err != nil {
+		kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper("")
+		return &kh
+	}
+	sview := volley.NewScheduleTelegramViewRu(sch)
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(sview.GetText())
+	if p.State.ChatId != p.Person.TelegramId || !p.IsOrganizer() {
+		return &kh
+	}
+	kh.Text += "\n\n" + telegram.EscapeText(sview.ParseMode, res.Hint)
+	kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "sdays", Text: res.DaysBtn})
+	if sch.Paused {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "pause", Text: res.ResumeBtn})
+	} else {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "pause", Text: res.PauseBtn})
+	}
+	kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "future", Text: res.FutureBtn})
+	kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "del", Text: res.DeleteBtn})
+	return &kh
+}
+
+func (p *ScheduleStateProvider) Proceed() (telegram.State, error) {
+	bp := p.BaseStateProvider
+	sch, err := p.GetSchedule()
+	if err != nil || !p.IsOrganizer() {
+		bp.State.Action = p.BackState.State
+		return bp.Proceed()
+	}
+	switch p.State.Action {
+	case "pause":
+		sch.Paused = !sch.Paused
+		err = p.ScheduleRepository.Update(p.ctx, sch)
+	case "future":
+		sch.SetTemplate(p.reserve)
+		if err = p.ScheduleRepository.Update(p.ctx, sch); err == nil {
+			p.applied, err = p.ApplyToFuture(sch)
+		}
+	case "del":
+		if err = p.ScheduleRepository.Delete(p.ctx, sch.Id); err == nil {
+			bp.reserve.ScheduleId = uuid.Nil
+			bp.State.Updated = true
+			bp.State.Action = p.BackState.State
+			return bp.Proceed()
+		}
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"package":  "bvbot",
+			"function": "Proceed",
+			"struct":   "ScheduleStateProvider",
+			"state":    p.State,
+			"error":    err,
+		}).Error("can't update schedule: " + sch.Id.String())
+	}
+	if p.State.Action != "sdays" {
+		bp.State.Action = p.State.State
+	}
+	st, _ := bp.Proceed()
+	return st, err
+}
+
+// ApplyToFuture copies template settings to not canceled games of the schedule
+// starting after the current one.
+func (p ScheduleStateProvider) ApplyToFuture(sch volley.Schedule) (count int, err error) {
+	filter := volley.Volley{ScheduleId: sch.Id}
+	filter.StartTime = p.reserve.StartTime
+	vlist, err := p.Repository.GetByFilter(p.ctx, filter, false, false)
+	if err != nil {
+		return
+	}
+	for _, v := range vlist {
+		if v.Id == p.reserve.Id {
+			continue
+		}
+		applied, aerr := p.applyToGame(sch, v)
+		if aerr != nil {
+			return count, aerr
+		}
+		if applied {
+			count++
+		}
+	}
+	return
+}
+
+// applyToGame applies the template to the game got again under its lock,
+// so members joined or left since the game was listed are kept.
+func (p ScheduleStateProvider) applyToGame(sch volley.Schedule, v volley.Volley) (applied bool, err error) {
+	if p.LockGame != nil {
+		defer p.LockGame(v.Base64Id())()
+	}
+	if v, err = p.Repository.Get(p.ctx, v.Id); err != nil {
+		return
+	}
+	if v.Canceled || v.ScheduleId != sch.Id || !v.StartTime.After(p.reserve.StartTime) {
+		return
+	}
+	sch.Apply(&v)
+	err = p.Repository.Update(p.ctx, v)
+	return err == nil, err
+}
+
+type ScheduleDaysStateProvider struct {
+	BaseStateProvider
+	Resources ScheduleResources
+}
+
+func (p ScheduleDaysStateProvider) GetRequests() []telegram.StateRequest {
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p ScheduleDaysStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	sch, _ := p.GetSchedule()
+	days := []telegram.EnumItem{}
+	for i := 1; i <= 7; i++ {
+		d := time.Weekday(i % 7)
+		item := volley.WeekdayNamesRu[d]
+		if sch.HasWeekday(d) {
+			item = "✅ " + item
+		}
+		days = append(days, telegram.EnumItem{Id: strconv.Itoa(int(d)), Item: item})
+	}
+	kh := telegram.NewEnumKeyboardHelper(days)
+	kh.Columns = 4
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(p.Resources.DaysMessage)
+	return &kh
+}
+
+func (p ScheduleDaysStateProvider) Proceed() (telegram.State, error) {
+	if p.State.Action == "set" {
+		kh := p.GetKeyboardHelper().(*telegram.EnumKeyboardHelper)
+		sch, err := p.GetSchedule()
+		day, perr := strconv.Atoi(kh.Value)
+		if err == nil && perr == nil && p.IsOrganizer() {
+			sch.ToggleWeekday(time.Weekday(day))
+			err = p.ScheduleRepository.Update(p.ctx, sch)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"package":  "bvbot",
+				"function": "Proceed",
+				"struct":   "ScheduleDaysStateProvider",
+				"state":    p.State,
+				"error":    err,
+			}).Error("can't update schedule days")
+		}
+		p.State.Action = p.State.State
+	}
+	return p.BaseStateProvider.Proceed()
+}
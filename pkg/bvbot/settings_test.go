@@ -1,6 +1,7 @@
 package bvbot
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -63,7 +64,7 @@ func TestSettingsStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_settings_settings_" + test.res.Id.String())
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, test.res.Location, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, test.res.Location, nil, nil, "")
 			bp.reserve = test.res
 			sp := SettingsStateProvider{BaseStateProvider: bp, Resources: res}
 			acts := sp.GetKeyboardHelper().GetKeyboard().(telegram.InlineKeyboardMarkup).InlineKeyboard
@@ -60,6 +60,11 @@ func (p ShowStateProvider) GetKeyboardHelper() (kh telegram.KeyboardHelper) {
 			ah.Actions = append(ah.Actions, telegram.ActionButton{
 				Action: "jtime", Text: res.JoinTimeBtn})
 		}
+		if mb := p.reserve.GetMemberByTelegramId(p.Person.TelegramId); p.State.ChatId > 0 && res.PayBtn != "" &&
+			p.reserve.MainMembers()[mb.Id] && p.reserve.Price > 0 && !mb.GetPaid() {
+			ah.Actions = append(ah.Actions, telegram.ActionButton{
+				Action: "pay", Text: res.PayBtn})
+		}
 		if p.State.ChatId <= 0 || p.reserve.HasPlayerByTelegramId(p.Person.TelegramId) {
 			ah.Actions = append(ah.Actions, telegram.ActionButton{
 				Action: "leave", Text: res.JoinLeaveBtn})
@@ -334,12 +339,14 @@ type DescResources struct {
 	BackBtn     string `json:"back_btn"`
 	Message     string `json:"message"`
 	DoneMessage string `json:"done_message"`
+	Placeholder string `json:"placeholder"`
 }
 
 func NewDescResourcesRu() (r DescResources) {
 	r.BackBtn = "Назад"
 	r.Message = "Отлично. Отправь в чат описание активности."
 	r.DoneMessage = "Успешно! Описание обновлено."
+	r.Placeholder = "Описание активности"
 	return
 }
 
@@ -351,11 +358,18 @@ type DescStateProvider struct {
 func (p DescStateProvider) GetRequests() (rlist []telegram.StateRequest) {
 	if p.State.Action == "done" {
 		rlist = append(rlist, telegram.StateRequest{Clear: true, State: p.State})
+		if p.Message.MessageId > 0 {
+			st := p.State
+			st.Action = "edited"
+			st.MessageId = p.Message.MessageId
+			rlist = append(rlist, telegram.StateRequest{State: st})
+		}
 		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.DoneMessage}
 		return append(rlist, telegram.StateRequest{Request: &req})
 	}
 	if p.State.Action == "desc" {
-		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.Message}
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.Message,
+			ReplyMarkup: p.GetKeyboardHelper().GetKeyboard()}
 		p.State.MessageId = -1
 		return append(rlist, telegram.StateRequest{State: p.State, Request: &req})
 	}
@@ -363,16 +377,23 @@ func (p DescStateProvider) GetRequests() (rlist []telegram.StateRequest) {
 }
 
 func (p DescStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
-	return nil
+	return telegram.ForceReplyHelper{Placeholder: p.Resources.Placeholder}
 }
 
 func (p *DescStateProvider) Proceed() (telegram.State, error) {
 	if p.State.Action == "desc" {
 		p.reserve.Description = p.Message.Text
-		err := p.Repository.Update(p.reserve)
+		err := p.Repository.Update(p.ctx, p.reserve)
 		p.State.Action = "done"
 		p.BackState.Updated = true
 		return p.BackState, err
 	}
+	if p.State.Action == "edited" {
+		p.reserve.Description = p.Message.Text
+		err := p.Repository.Update(p.ctx, p.reserve)
+		st := p.State
+		st.Updated = true
+		return st, err
+	}
 	return p.State, nil
 }
@@ -1,6 +1,7 @@
 package bvbot
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -36,6 +37,8 @@ func TestShowKbd(t *testing.T) {
 			{Player: volley.Player{Person: pl3}, Count: 1},
 		},
 	}
+	paid := r
+	paid.Price = 500
 	admin := person.NewPerson("Admin")
 	admin.LocationRoles[r.Location.Id] = []string{"admin"}
 	admin.TelegramId = 321
@@ -69,6 +72,20 @@ func TestShowKbd(t *testing.T) {
 					{Text: res.RefreshBtn, CallbackData: "res_show_refresh_" + r.Id.String()},
 				},
 			}},
+		"Joined Person with price": {res: paid, p: person.Person{TelegramId: 123}, cid: 123,
+			kbd: [][]telegram.InlineKeyboardButton{
+				{
+					{Text: res.JoinMultiBtn, CallbackData: "res_show_joinm_" + r.Id.String()},
+					{Text: res.JoinTimeBtn, CallbackData: "res_show_jtime_" + r.Id.String()},
+				},
+				{
+					{Text: res.PayBtn, CallbackData: "res_show_pay_" + r.Id.String()},
+					{Text: res.JoinLeaveBtn, CallbackData: "res_show_leave_" + r.Id.String()},
+				},
+				{
+					{Text: res.RefreshBtn, CallbackData: "res_show_refresh_" + r.Id.String()},
+				},
+			}},
 		"Admin Person": {res: r, p: admin, cid: admin.TelegramId,
 			kbd: [][]telegram.InlineKeyboardButton{
 				{
@@ -91,6 +108,17 @@ func TestShowKbd(t *testing.T) {
 					{Text: res.RefreshBtn, CallbackData: "res_show_refresh_" + r.Id.String()},
 				},
 			}},
+		"Waiting Person with price": {res: paid, p: pl3, cid: pl3.TelegramId,
+			kbd: [][]telegram.InlineKeyboardButton{
+				{
+					{Text: res.JoinMultiBtn, CallbackData: "res_show_joinm_" + r.Id.String()},
+					{Text: res.JoinTimeBtn, CallbackData: "res_show_jtime_" + r.Id.String()},
+				},
+				{
+					{Text: res.JoinLeaveBtn, CallbackData: "res_show_leave_" + r.Id.String()},
+					{Text: res.RefreshBtn, CallbackData: "res_show_refresh_" + r.Id.String()},
+				},
+			}},
 		"Author Person Joined": {res: r, p: oauthor, cid: oauthor.TelegramId,
 			kbd: [][]telegram.InlineKeyboardButton{
 				{
@@ -121,7 +149,7 @@ func TestShowKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_show_show_" + test.res.Id.String())
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, test.res.Location, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, test.res.Location, nil, nil, "")
 			bp.reserve = test.res
 			sp := ShowStateProvider{BaseStateProvider: bp, Resources: res}
 			acts := sp.GetKeyboardHelper().GetKeyboard().(telegram.InlineKeyboardMarkup).InlineKeyboard
@@ -160,7 +188,7 @@ func TestJoinmStateKbd(t *testing.T) {
 			msg := telegram.Message{}
 			st, _ := telegram.NewState().Parse("res_joinm_joinm_" + test.res.Id.String())
 			st.ChatId = test.cid
-			bp, _ := NewBaseStateProvider(st, msg, test.p, test.res.Location, nil, nil, "")
+			bp, _ := NewBaseStateProvider(context.Background(), st, msg, test.p, test.res.Location, nil, nil, "")
 			bp.reserve = test.res
 			sp := JoinPlayersStateProvider{BaseStateProvider: bp, Resources: res}
 			kbd := sp.GetKeyboardHelper().(*telegram.CountKeyboardHelper)
@@ -171,3 +199,36 @@ func TestJoinmStateKbd(t *testing.T) {
 		})
 	}
 }
+
+func TestShowStateNewMessage(t *testing.T) {
+	r := volley.NewVolley(person.Person{Id: uuid.New()}, time.Now(), time.Now().Add(time.Hour))
+	r.Location.ChatId = -100
+	tests := map[string]struct {
+		cid  int
+		mid  int
+		edit bool
+	}{
+		"Deep link without message": {cid: 100, mid: 0, edit: false},
+		"Callback message":          {cid: 100, mid: 123, edit: true},
+		"Location chat":             {cid: -100, mid: 123, edit: true},
+		"Other group chat":          {cid: -300, mid: 123, edit: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			st, _ := telegram.NewState().Parse("res_show_show")
+			st.ChatId = test.cid
+			st.MessageId = test.mid
+			bp, _ := NewBaseStateProvider(context.Background(), st, telegram.Message{}, person.Person{TelegramId: 100}, r.Location, nil, nil, "")
+			bp.reserve = r
+			rlist := ShowStateProvider{BaseStateProvider: bp, Resources: NewShowResourcesRu()}.GetRequests()
+			if len(rlist) != 1 {
+				t.FailNow()
+			}
+			_, edit := rlist[0].Request.(*telegram.EditMessageTextRequest)
+			if edit != test.edit {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package bvbot
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+)
+
+type TeamsResources struct {
+	BalanceBtn  string `json:"balance_btn"`
+	PairBtn     string `json:"pair_btn"`
+	PairMessage string `json:"pair_message"`
+	PublishBtn  string `json:"publish_btn"`
+	ShuffleBtn  string `json:"shuffle_btn"`
+}
+
+func NewTeamsResourcesRu() (r TeamsResources) {
+	r.BalanceBtn = "⚖️ Составить"
+	r.PairBtn = "🤝 Вместе"
+	r.PairMessage = "Выбери двух игроков, которые должны играть в одной команде. Повторный выбор пары ее разъединит."
+	r.PublishBtn = "📣 В чат"
+	r.ShuffleBtn = "🔀 Перемешать"
+	return
+}
+
+type TeamsStateProvider struct {
+	BaseStateProvider
+	Resources TeamsResources
+}
+
+func (p TeamsStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	if p.State.Action == "tpub" {
+		if p.Location.ChatId == 0 || len(p.reserve.Teams) == 0 || !p.IsOrganizer() {
+			return
+		}
+		rview := volley.NewTelegramViewRu(p.reserve)
+		tview := volley.NewTeamsTelegramViewRu(p.reserve)
+		tb := telegram.NewTextBuilder(tview.ParseMode)
+		tb.Bold(rview.GetTitle()).Text("\n\n").Raw(tview.GetText())
+		mr := p.CreateMR(p.Location.ChatId, tb.String(), tview.ParseMode, nil)
+		return append(rlist, telegram.StateRequest{Request: mr})
+	}
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p TeamsStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	res := p.Resources
+	tview := volley.NewTeamsTelegramViewRu(p.reserve)
+	kh := telegram.ActionsKeyboardHelper{Columns: 2}
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(tview.GetText())
+	if p.reserve.Canceled || p.State.ChatId != p.Person.TelegramId || !p.IsOrganizer() {
+		return &kh
+	}
+	if len(p.reserve.Teams) == 0 {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "shuffle", Text: res.BalanceBtn})
+	} else {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "shuffle", Text: res.ShuffleBtn})
+	}
+	kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tpair", Text: res.PairBtn})
+	if p.Location.ChatId != 0 && len(p.reserve.Teams) > 0 {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tpub", Text: res.PublishBtn})
+	}
+	return &kh
+}
+
+func (p TeamsStateProvider) Proceed() (telegram.State, error) {
+	if !p.IsOrganizer() {
+		p.State.Action = p.State.State
+		return p.BaseStateProvider.Proceed()
+	}
+	switch p.State.Action {
+	case "shuffle":
+		p.reserve.BalanceTeams(rand.New(rand.NewSource(time.Now().UnixNano())))
+		p.State.Updated = true
+		p.State.Action = p.State.State
+	case "tpub":
+		p.State.Action = p.State.State
+	}
+	return p.BaseStateProvider.Proceed()
+}
+
+type TeamPairStateProvider struct {
+	BaseStateProvider
+	Resources TeamsResources
+}
+
+func (p TeamPairStateProvider) GetRequests() []telegram.StateRequest {
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p TeamPairStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	first, _ := strconv.Atoi(p.State.Value)
+	main := p.reserve.MainMembers()
+	pllist := []telegram.EnumItem{}
+	for _, mb := range p.reserve.Members {
+		if !main[mb.Id] || mb.TelegramId == 0 {
+			continue
+		}
+		item := telegram.EnumItem{Id: strconv.Itoa(mb.TelegramId), Item: mb.String()}
+		if first != 0 {
+			item.Id = strconv.Itoa(first) + "-" + item.Id
+		}
+		if mb.TelegramId == first {
+			item.Item = "👉 " + item.Item
+		}
+		pllist = append(pllist, item)
+	}
+	kh := telegram.NewEnumKeyboardHelper(pllist)
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(p.Resources.PairMessage)
+	return &kh
+}
+
+func (p TeamPairStateProvider) Proceed() (telegram.State, error) {
+	if p.State.Action != "set" {
+		return p.BaseStateProvider.Proceed()
+	}
+	p.State.Action = p.State.State
+	if tids := strings.Split(p.State.Value, "-"); len(tids) == 2 && p.IsOrganizer() {
+		a, _ := strconv.Atoi(tids[0])
+		b, _ := strconv.Atoi(tids[1])
+		p.reserve.TogglePair(p.reserve.GetMemberByTelegramId(a).Id, p.reserve.GetMemberByTelegramId(b).Id)
+		if len(p.reserve.Teams) > 0 {
+			p.reserve.BalanceTeams(nil)
+		}
+		p.State.Value = ""
+		p.State.Action = p.BackState.State
+		p.State.Updated = true
+	}
+	return p.BaseStateProvider.Proceed()
+}
@@ -0,0 +1,328 @@
+package bvbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type TournamentResources struct {
+	ErrorMessage     string `json:"error_message"`
+	ExportBtn        string `json:"export_btn"`
+	ExportFile       string `json:"export_file"`
+	FormatBtn        string `json:"format_btn"`
+	MatchBtn         string `json:"match_btn"`
+	MatchMessage     string `json:"match_message"`
+	NotEnoughMessage string `json:"not_enough_message"`
+	Placeholder      string `json:"placeholder"`
+	PublishBtn       string `json:"publish_btn"`
+	ResetBtn         string `json:"reset_btn"`
+	ResetConfirmBtn  string `json:"reset_confirm_btn"`
+	ResetMessage     string `json:"reset_message"`
+	ScoreMessage     string `json:"score_message"`
+	SeedingBtn       string `json:"seeding_btn"`
+	SizeBtn          string `json:"size_btn"`
+	StartBtn         string `json:"start_btn"`
+}
+
+func NewTournamentResourcesRu() (r TournamentResources) {
+	r.ErrorMessage = "Не понял счет. Отправь его в виде 2:1 или 21:15, ничьих не бывает."
+	r.ExportBtn = "💾 JSON"
+	r.ExportFile = "tournament.json"
+	r.FormatBtn = "🗂 Формат"
+	r.MatchBtn = "🏐 Счет матча"
+	r.MatchMessage = "Выбери матч, счет которого нужно записать."
+	r.NotEnoughMessage = "Для турнира нужно хотя бы две команды."
+	r.Placeholder = "21:15"
+	r.PublishBtn = "📣 В чат"
+	r.ResetBtn = "🗑 Сбросить"
+	r.ResetConfirmBtn = "🧨 Уверен"
+	r.ResetMessage = "🧨 Сетка и все счета турнира будут удалены."
+	r.ScoreMessage = "Отправь счет матча #%d: %s — %s, например 2:1 или 21:15. Первым укажи счет первой команды."
+	r.SeedingBtn = "🎯 Посев"
+	r.SizeBtn = "👥 По %d"
+	r.StartBtn = "▶️ Начать"
+	return
+}
+
+var tournamentTeamSizes = []int{2, 3, 4, 6}
+
+func nextTeamSize(size int) int {
+	for i, sz := range tournamentTeamSizes[:len(tournamentTeamSizes)-1] {
+		if sz == size {
+			return tournamentTeamSizes[i+1]
+		}
+	}
+	return tournamentTeamSizes[0]
+}
+
+type TournamentStateProvider struct {
+	BaseStateProvider
+	Resources TournamentResources
+}
+
+func (p TournamentStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	switch p.State.Action {
+	case "tjson":
+		if !p.reserve.Bracket.Started() {
+			return
+		}
+		data, err := json.MarshalIndent(p.reserve.Bracket, "", "  ")
+		if err != nil {
+			log.WithFields(log.Fields{
+				"package":  "bvbot",
+				"function": "GetRequests",
+				"struct":   "TournamentStateProvider",
+				"state":    p.State,
+				"error":    err,
+			}).Error("can't export bracket of reserve: " + p.reserve.Id.String())
+			return
+		}
+		rview := volley.NewTelegramViewRu(p.reserve)
+		req := telegram.SendDocumentRequest{ChatId: p.State.ChatId, Caption: rview.GetTitle(),
+			Document: telegram.InputFile{Name: p.Resources.ExportFile, Reader: bytes.NewReader(data)}}
+		return append(rlist, telegram.StateRequest{Request: req})
+	case "tpub":
+		if p.Location.ChatId == 0 || !p.reserve.Bracket.Started() || !p.IsOrganizer() {
+			return
+		}
+		rview := volley.NewTelegramViewRu(p.reserve)
+		bview := volley.NewBracketTelegramViewRu(p.reserve)
+		tb := telegram.NewTextBuilder(bview.ParseMode)
+		tb.Bold(rview.GetTitle()).Text("\n\n").Raw(bview.GetText())
+		mr := p.CreateMR(p.Location.ChatId, tb.String(), bview.ParseMode, nil)
+		return append(rlist, telegram.StateRequest{Request: mr})
+	}
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p TournamentStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	res := p.Resources
+	br := p.reserve.Bracket
+	bview := volley.NewBracketTelegramViewRu(p.reserve)
+	kh := telegram.ActionsKeyboardHelper{Columns: 2}
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(bview.GetText())
+	if p.State.ChatId != p.Person.TelegramId {
+		return &kh
+	}
+	organizer := p.IsOrganizer() && !p.reserve.Canceled
+	if !br.Started() {
+		if !organizer {
+			return &kh
+		}
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tfmt", Text: res.FormatBtn})
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tseed", Text: res.SeedingBtn})
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tsize", Text: fmt.Sprintf(res.SizeBtn, br.Size())})
+		if len(p.reserve.TournamentTeams(br.Size())) < 2 {
+			kh.Text += "\n" + telegram.EscapeText(bview.ParseMode, res.NotEnoughMessage)
+		} else {
+			kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tstart", Text: res.StartBtn})
+		}
+		return &kh
+	}
+	if organizer {
+		if len(br.ReadyMatches()) > 0 {
+			kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tmatch", Text: res.MatchBtn})
+		}
+		if p.Location.ChatId != 0 {
+			kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tpub", Text: res.PublishBtn})
+		}
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "treset", Text: res.ResetBtn})
+	}
+	kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "tjson", Text: res.ExportBtn})
+	return &kh
+}
+
+func (p TournamentStateProvider) Proceed() (telegram.State, error) {
+	if !p.IsOrganizer() || p.reserve.Canceled {
+		p.State.Action = p.State.State
+		return p.BaseStateProvider.Proceed()
+	}
+	br := &p.reserve.Bracket
+	switch p.State.Action {
+	case "tmatch", "treset":
+		return p.BaseStateProvider.Proceed()
+	case "tfmt":
+		if !br.Started() {
+			br.Format = br.Format.Next()
+			p.State.Updated = true
+		}
+	case "tseed":
+		if !br.Started() {
+			if br.Seeding == volley.SeedByRating {
+				br.Seeding = volley.SeedByLevel
+			} else {
+				br.Seeding = volley.SeedByRating
+			}
+			p.State.Updated = true
+		}
+	case "tsize":
+		if !br.Started() {
+			br.TeamSize = nextTeamSize(br.Size())
+			p.State.Updated = true
+		}
+	case "tstart":
+		if !br.Started() && br.Start(p.GetTeams()) == nil {
+			br.Schedule(p.reserve.StartTime, p.reserve.EndTime, p.reserve.CourtCount)
+			p.State.Updated = true
+		}
+	}
+	p.State.Action = p.State.State
+	return p.BaseStateProvider.Proceed()
+}
+
+// GetTeams returns teams of the roster with average ratings of their members at the location.
+func (p TournamentStateProvider) GetTeams() (teams []volley.TournamentTeam) {
+	teams = p.reserve.TournamentTeams(p.reserve.Bracket.Size())
+	if p.reserve.Bracket.Seeding != volley.SeedByRating || p.RatingRepository == nil {
+		return
+	}
+	for i, tm := range teams {
+		sum := 0
+		for _, tp := range tm.Players {
+			r, err := p.RatingRepository.Get(p.ctx, p.reserve.Location, tp.Id)
+			if err != nil {
+				r = volley.NewRating(p.reserve.Location, tp.Id)
+			}
+			sum += r.Value
+		}
+		teams[i].Rating = sum / len(tm.Players)
+	}
+	return
+}
+
+type TournamentMatchStateProvider struct {
+	BaseStateProvider
+	Resources TournamentResources
+}
+
+func (p TournamentMatchStateProvider) GetRequests() []telegram.StateRequest {
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p TournamentMatchStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	br := p.reserve.Bracket
+	mlist := []telegram.EnumItem{}
+	for _, m := range br.ReadyMatches() {
+		item := fmt.Sprintf("#%d %s — %s", m.Id, br.Team(m.Teams[0]).Name, br.Team(m.Teams[1]).Name)
+		mlist = append(mlist, telegram.EnumItem{Id: strconv.Itoa(m.Id), Item: item})
+	}
+	kh := telegram.NewEnumKeyboardHelper(mlist)
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(p.Resources.MatchMessage)
+	return &kh
+}
+
+func (p TournamentMatchStateProvider) Proceed() (telegram.State, error) {
+	if p.State.Action != "set" {
+		return p.BaseStateProvider.Proceed()
+	}
+	p.State.Action = p.BackState.State
+	if _, err := strconv.Atoi(p.State.Value); err == nil && p.IsOrganizer() {
+		p.State.Action = "tscore"
+	}
+	st, err := p.BaseStateProvider.Proceed()
+	if st.State == "tscore" {
+		// values are not saved with states, so the awaiting state keeps the match in its action
+		st.Action = "match" + st.Value
+	}
+	st.Value = ""
+	return st, err
+}
+
+// TournamentResetStateProvider asks the organizer to confirm the reset of the
+// started bracket.
+type TournamentResetStateProvider struct {
+	BaseStateProvider
+	Resources TournamentResources
+}
+
+func (p TournamentResetStateProvider) GetRequests() []telegram.StateRequest {
+	p.kh = p.GetKeyboardHelper()
+	return p.BaseStateProvider.GetRequests()
+}
+
+func (p TournamentResetStateProvider) GetKeyboardHelper() telegram.KeyboardHelper {
+	kh := telegram.ActionsKeyboardHelper{}
+	kh.BaseKeyboardHelper = p.GetBaseKeyboardHelper(p.Resources.ResetMessage)
+	kh.Actions = []telegram.ActionButton{}
+	if p.State.ChatId == p.Person.TelegramId && p.IsOrganizer() && !p.reserve.Canceled {
+		kh.Actions = append(kh.Actions, telegram.ActionButton{Action: "confirm", Text: p.Resources.ResetConfirmBtn})
+	}
+	return &kh
+}
+
+func (p TournamentResetStateProvider) Proceed() (telegram.State, error) {
+	if p.State.Action == "confirm" && p.IsOrganizer() && !p.reserve.Canceled {
+		p.reserve.Bracket.Reset()
+		p.State.Updated = true
+	}
+	p.State.Action = p.BackState.State
+	return p.BaseStateProvider.Proceed()
+}
+
+type TournamentScoreStateProvider struct {
+	BaseStateProvider
+	Resources TournamentResources
+}
+
+func (p TournamentScoreStateProvider) GetRequests() (rlist []telegram.StateRequest) {
+	switch {
+	case strings.HasPrefix(p.State.Action, "match"):
+		br := p.reserve.Bracket
+		m := br.Match(p.MatchId())
+		if m == nil {
+			return
+		}
+		txt := fmt.Sprintf(p.Resources.ScoreMessage, m.Id, br.Team(m.Teams[0]).Name, br.Team(m.Teams[1]).Name)
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: txt,
+			ReplyMarkup: telegram.ForceReplyHelper{Placeholder: p.Resources.Placeholder}.GetKeyboard()}
+		p.State.MessageId = -1
+		return append(rlist, telegram.StateRequest{State: p.State, Request: &req})
+	case p.State.Action == "retry":
+		req := telegram.MessageRequest{ChatId: p.State.ChatId, Text: p.Resources.ErrorMessage}
+		return append(rlist, telegram.StateRequest{Request: &req})
+	case p.State.Action == "done":
+		return append(rlist, telegram.StateRequest{Clear: true, State: p.State})
+	}
+	return
+}
+
+func (p TournamentScoreStateProvider) MatchId() int {
+	id, _ := strconv.Atoi(strings.TrimPrefix(p.State.Action, "match"))
+	return id
+}
+
+func (p *TournamentScoreStateProvider) Proceed() (telegram.State, error) {
+	st := p.State
+	if !strings.HasPrefix(st.Action, "match") || st.MessageId != -1 {
+		return st, nil
+	}
+	m := scoreRe.FindStringSubmatch(p.Message.Text)
+	if m == nil || !p.IsOrganizer() {
+		p.State.Action = "retry"
+		return st, nil
+	}
+	a, _ := strconv.Atoi(m[1])
+	b, _ := strconv.Atoi(m[2])
+	err := p.reserve.Bracket.SetScore(p.MatchId(), a, b)
+	if err == volley.ErrInvalidScore {
+		p.State.Action = "retry"
+		return st, nil
+	}
+	p.State.Action = "done"
+	bp := p.BaseStateProvider
+	bp.State.State = "tscore"
+	bp.State.Action = "tour"
+	bp.State.MessageId = 0
+	bp.State.Value = ""
+	bp.State.Updated = err == nil
+	return bp.Proceed()
+}
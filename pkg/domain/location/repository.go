@@ -1,18 +1,20 @@
 package location
 
 import (
+	"context"
+
 	uuid "github.com/google/uuid"
 )
 
 type LocationRepository interface {
-	Get(uuid.UUID) (Location, error)
-	GetByName(string) (Location, error)
-	Add(Location) (Location, error)
-	Update(Location) error
+	Get(context.Context, uuid.UUID) (Location, error)
+	GetByName(context.Context, string) (Location, error)
+	Add(context.Context, Location) (Location, error)
+	Update(context.Context, Location) error
 }
 
 type LocationConfigRepository interface {
-	Add(loc Location, service string, config interface{}) error
-	Get(loc Location, service string, config interface{}) error
-	Update(loc Location, service string, config interface{}) error
+	Add(ctx context.Context, loc Location, service string, config interface{}) error
+	Get(ctx context.Context, loc Location, service string, config interface{}) error
+	Update(ctx context.Context, loc Location, service string, config interface{}) error
 }
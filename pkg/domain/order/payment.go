@@ -8,9 +8,13 @@ import (
 )
 
 type Payment struct {
-	Id     uuid.UUID     `json:"id"`
-	Person person.Person `json:"person"`
-	Sum    int           `json:"sum"`
+	Id               uuid.UUID     `json:"id"`
+	Person           person.Person `json:"person"`
+	Sum              int           `json:"sum"`
+	Currency         string        `json:"currency"`
+	ChargeId         string        `json:"charge_id"`
+	ProviderChargeId string        `json:"provider_charge_id"`
+	Date             time.Time     `json:"date"`
 }
 
 type TelegramPay struct {
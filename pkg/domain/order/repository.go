@@ -1,17 +1,19 @@
 package order
 
 import (
+	"context"
+
 	uuid "github.com/google/uuid"
 )
 
 type OrderRepository interface {
-	Get(uuid.UUID) (Order, error)
-	Add(Order) (Order, error)
-	Update(Order) error
+	Get(context.Context, uuid.UUID) (Order, error)
+	Add(context.Context, Order) (Order, error)
+	Update(context.Context, Order) error
 }
 
 type PaymentRepository interface {
-	Get(Payment) (Payment, error)
-	Add(Payment) (Order, error)
-	Update(Payment) error
+	Get(context.Context, Payment) (Payment, error)
+	Add(context.Context, Payment) (Order, error)
+	Update(context.Context, Payment) error
 }
@@ -1,6 +1,7 @@
 package person
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -18,7 +19,7 @@ func NewMemoryRepository() *MemoryRepository {
 	}
 }
 
-func (mr *MemoryRepository) Get(id uuid.UUID) (Person, error) {
+func (mr *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (Person, error) {
 	if person, ok := mr.persons[id]; ok {
 		return person, nil
 	}
@@ -26,7 +27,7 @@ func (mr *MemoryRepository) Get(id uuid.UUID) (Person, error) {
 	return Person{}, ErrPersonNotFound
 }
 
-func (mr *MemoryRepository) GetByTelegramId(id int) (Person, error) {
+func (mr *MemoryRepository) GetByTelegramId(ctx context.Context, id int) (Person, error) {
 	for _, person := range mr.persons {
 		if person.TelegramId == id {
 			return person, nil
@@ -36,7 +37,7 @@ func (mr *MemoryRepository) GetByTelegramId(id int) (Person, error) {
 	return Person{}, ErrPersonNotFound
 }
 
-func (mr *MemoryRepository) Add(p Person) (per Person, err error) {
+func (mr *MemoryRepository) Add(ctx context.Context, p Person) (per Person, err error) {
 	if mr.persons == nil {
 		mr.Lock()
 		mr.persons = make(map[uuid.UUID]Person)
@@ -54,7 +55,7 @@ func (mr *MemoryRepository) Add(p Person) (per Person, err error) {
 	return
 }
 
-func (mr *MemoryRepository) Update(memp Person) error {
+func (mr *MemoryRepository) Update(ctx context.Context, memp Person) error {
 	if _, ok := mr.persons[memp.Id]; !ok {
 		return fmt.Errorf("person does not exist: %w", ErrUpdatePerson)
 	}
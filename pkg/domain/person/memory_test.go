@@ -1,6 +1,7 @@
 package person
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -35,7 +36,7 @@ func TestMemory_GetPerson(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 
-			_, err := repo.Get(tc.id)
+			_, err := repo.Get(context.Background(), tc.id)
 			if err != tc.expectedErr {
 				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
 			}
@@ -65,12 +66,12 @@ func TestMemory_AddPerson(t *testing.T) {
 			}
 
 			p := NewPerson(tc.firstname)
-			_, err := repo.Add(p)
+			_, err := repo.Add(context.Background(), p)
 			if err != tc.expectedErr {
 				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
 			}
 
-			found, err := repo.Get(p.Id)
+			found, err := repo.Get(context.Background(), p.Id)
 			if err != nil {
 				t.Fatal(err)
 			}
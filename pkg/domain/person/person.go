@@ -55,6 +55,7 @@ type Person struct {
 	Lastname      string                 `json:"lastname"`
 	Fullname      string                 `json:"fullname"`
 	Sex           Sex                    `json:"sex"`
+	Phone         string                 `json:"phone"`
 	LocationRoles map[uuid.UUID][]string `json:"roles"`
 	Settings      map[string]string      `json:"settings"`
 }
@@ -78,6 +79,16 @@ func (user *Person) String() string {
 	return firstname
 }
 
+// LocationNotifyParam is the setting which turns notifications about games of the location off.
+func LocationNotifyParam(lid uuid.UUID) string {
+	return "notify@" + lid.String()
+}
+
+// Notify checks the notification param and the notification setting of the location.
+func (user *Person) Notify(param string, lid uuid.UUID) bool {
+	return user.Settings[param] == "on" && user.Settings[LocationNotifyParam(lid)] != "off"
+}
+
 func (user *Person) CheckLocationRole(l location.Location, role string) bool {
 	for _, r := range user.LocationRoles[l.Id] {
 		if r == role {
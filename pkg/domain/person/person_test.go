@@ -1,6 +1,10 @@
 package person
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
 
 func TestPersonGetDisplayname(t *testing.T) {
 	tests := map[string]struct {
@@ -57,3 +61,28 @@ func TestPersonGetDisplayname(t *testing.T) {
 		})
 	}
 }
+
+func TestPersonNotify(t *testing.T) {
+	lid := uuid.New()
+	tests := map[string]struct {
+		settings map[string]string
+		want     bool
+	}{
+		"Not set":          {settings: map[string]string{}, want: false},
+		"On":               {settings: map[string]string{"notify": "on"}, want: true},
+		"Off":              {settings: map[string]string{"notify": "off"}, want: false},
+		"Location off":     {settings: map[string]string{"notify": "on", LocationNotifyParam(lid): "off"}, want: false},
+		"Location on":      {settings: map[string]string{"notify": "on", LocationNotifyParam(lid): "on"}, want: true},
+		"Other location":   {settings: map[string]string{"notify": "on", LocationNotifyParam(uuid.New()): "off"}, want: true},
+		"Only location on": {settings: map[string]string{LocationNotifyParam(lid): "on"}, want: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := Person{Settings: test.settings}
+			if p.Notify("notify", lid) != test.want {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package person
 
 import (
+	"context"
+
 	uuid "github.com/google/uuid"
 )
 
 type PersonRepository interface {
-	Get(uuid.UUID) (Person, error)
-	GetByTelegramId(int) (Person, error)
-	Add(Person) (Person, error)
-	Update(Person) error
+	Get(context.Context, uuid.UUID) (Person, error)
+	GetByTelegramId(context.Context, int) (Person, error)
+	Add(context.Context, Person) (Person, error)
+	Update(context.Context, Person) error
 }
@@ -1,6 +1,9 @@
 package person
 
-import "fmt"
+import (
+	"fmt"
+	"volleybot/pkg/telegram"
+)
 
 type PersonView interface {
 	GetText() (text string)
@@ -14,16 +17,20 @@ type TelegramView struct {
 func NewTelegramViewRu(p Person) TelegramView {
 	return TelegramView{
 		Person:    p,
-		ParseMode: "Markdown",
+		ParseMode: telegram.ParseModeHTML,
 	}
 }
 
 func (tgv *TelegramView) GetText() (text string) {
-	text = fmt.Sprintf("*Имя*: %s", tgv.Person.Firstname)
-	text += fmt.Sprintf("\n*Фамилия*: %s", tgv.Person.Lastname)
-	text += fmt.Sprintf("\n*Полное имя*: %s", tgv.Person.String())
-	text += fmt.Sprintf("\n*Пол*: %s", tgv.GetSexText())
-	return
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Bold("Имя").Text(": " + tgv.Person.Firstname)
+	tb.Text("\n").Bold("Фамилия").Text(": " + tgv.Person.Lastname)
+	tb.Text("\n").Bold("Полное имя").Text(": " + tgv.Person.String())
+	tb.Text("\n").Bold("Пол").Text(": " + tgv.GetSexText())
+	if tgv.Person.Phone != "" {
+		tb.Text("\n").Bold("Телефон").Text(": " + tgv.Person.Phone)
+	}
+	return tb.String()
 }
 
 func (tgv *TelegramView) GetSexText() (text string) {
@@ -38,11 +45,14 @@ func (tgv *TelegramView) GetSexText() (text string) {
 func (tgv *TelegramView) String() (text string) {
 	text = fmt.Sprintf("%s %s", Sex.Emoji(tgv.Person.Sex), tgv.Person.String())
 
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
 	if tgv.Person.TelegramId != 0 {
-		text = fmt.Sprintf("[%s](tg://user?id=%d)", text, tgv.Person.TelegramId)
+		tb.Mention(text, tgv.Person.TelegramId)
+	} else {
+		tb.Text(text)
 	}
 
-	return
+	return tb.String()
 }
 
 type TelegramSettingsView struct {
@@ -53,19 +63,20 @@ type TelegramSettingsView struct {
 func NewTelegramSettingsViewRu(p Person) TelegramSettingsView {
 	return TelegramSettingsView{
 		Person:    p,
-		ParseMode: "Markdown",
+		ParseMode: telegram.ParseModeHTML,
 	}
 }
 
 func (tgv *TelegramSettingsView) GetText() (text string) {
-	text = "⚙️*Настройки оповещений:*"
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("⚙️").Bold("Настройки оповещений:")
 	for _, param := range Params {
+		tb.Text("\n").Bold(ParamNames[param])
 		if val, ok := tgv.Person.Settings[param]; ok && val != "undef" {
-			text += fmt.Sprintf("\n*%s*: %s", ParamNames[param], ParamValText[val])
+			tb.Textf(": %s", ParamValText[val])
 		} else {
-			text += fmt.Sprintf("\n*%s*: %s (%s)", ParamNames[param],
-				ParamValText[ParamDefaults[param]], ParamValText["undef"])
+			tb.Textf(": %s (%s)", ParamValText[ParamDefaults[param]], ParamValText["undef"])
 		}
 	}
-	return
+	return tb.String()
 }
@@ -14,19 +14,19 @@ func TestTelegramView(t *testing.T) {
 		"Fullname": {
 			p:    Person{Fullname: "Full Name"},
 			str:  "👤 Full Name",
-			text: "*Имя*: \n*Фамилия*: \n*Полное имя*: Full Name\n*Пол*: Не определен",
+			text: "<b>Имя</b>: \n<b>Фамилия</b>: \n<b>Полное имя</b>: Full Name\n<b>Пол</b>: Не определен",
 		},
 		"Firstname": {
 			p:    Person{Firstname: "Firstname"},
 			str:  "👤 Firstname",
-			text: "*Имя*: Firstname\n*Фамилия*: \n*Полное имя*: Firstname\n*Пол*: Не определен",
+			text: "<b>Имя</b>: Firstname\n<b>Фамилия</b>: \n<b>Полное имя</b>: Firstname\n<b>Пол</b>: Не определен",
 		},
 		"Firstname_Lastname": {
 			p: Person{
 				Firstname: "Firstname",
 				Lastname:  "Lastname"},
 			str:  "👤 Firstname Lastname",
-			text: "*Имя*: Firstname\n*Фамилия*: Lastname\n*Полное имя*: Firstname Lastname\n*Пол*: Не определен",
+			text: "<b>Имя</b>: Firstname\n<b>Фамилия</b>: Lastname\n<b>Полное имя</b>: Firstname Lastname\n<b>Пол</b>: Не определен",
 		},
 		"Fullname_Firstname_Lastname": {
 			p: Person{
@@ -35,13 +35,27 @@ func TestTelegramView(t *testing.T) {
 				Lastname:  "Lastname",
 				Sex:       1},
 			str:  "👦🏻 Full Name",
-			text: "*Имя*: Firstname\n*Фамилия*: Lastname\n*Полное имя*: Full Name\n*Пол*: 👦🏻 мальчик",
+			text: "<b>Имя</b>: Firstname\n<b>Фамилия</b>: Lastname\n<b>Полное имя</b>: Full Name\n<b>Пол</b>: 👦🏻 мальчик",
+		},
+		"Phone": {
+			p:    Person{Firstname: "Firstname", Phone: "+79990001122"},
+			str:  "👤 Firstname",
+			text: "<b>Имя</b>: Firstname\n<b>Фамилия</b>: \n<b>Полное имя</b>: Firstname\n<b>Пол</b>: Не определен\n<b>Телефон</b>: +79990001122",
+		},
+		"Hostile name with mention": {
+			p: Person{
+				Firstname:  "Ivan_Petrov",
+				Lastname:   "<b>*Boss*</b> & Co",
+				TelegramId: 123},
+			str: "<a href=\"tg://user?id=123\">👤 Ivan_Petrov &lt;b&gt;*Boss*&lt;/b&gt; &amp; Co</a>",
+			text: "<b>Имя</b>: Ivan_Petrov\n<b>Фамилия</b>: &lt;b&gt;*Boss*&lt;/b&gt; &amp; Co" +
+				"\n<b>Полное имя</b>: Ivan_Petrov &lt;b&gt;*Boss*&lt;/b&gt; &amp; Co\n<b>Пол</b>: Не определен",
 		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			tgv := TelegramView{Person: test.p}
+			tgv := NewTelegramViewRu(test.p)
 			str := tgv.String()
 			text := tgv.GetText()
 			if str != test.str {
@@ -1,6 +1,7 @@
 package reserve
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -54,7 +55,7 @@ func NewMemoryRepository(reserves *[]Reserve, filter Reserve, ordered bool) (mr
 	return
 }
 
-func (mr *MemoryRepository) Get(id uuid.UUID) (Reserve, error) {
+func (mr *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (Reserve, error) {
 	for _, res := range mr.reserves {
 		if res.Id == id {
 			return res, nil
@@ -63,12 +64,12 @@ func (mr *MemoryRepository) Get(id uuid.UUID) (Reserve, error) {
 	return Reserve{}, ErrReserveNotFound
 }
 
-func (rep *MemoryRepository) GetByFilter(filter Reserve, ordered bool, sorted bool) (res []Reserve, err error) {
+func (rep *MemoryRepository) GetByFilter(ctx context.Context, filter Reserve, ordered bool, sorted bool) (res []Reserve, err error) {
 	newrep := NewMemoryRepository(&rep.reserves, filter, ordered)
 	return newrep.reserves, nil
 }
 
-func (rep *MemoryRepository) Add(r Reserve) (res Reserve, err error) {
+func (rep *MemoryRepository) Add(ctx context.Context, r Reserve) (res Reserve, err error) {
 	if rep.reserves == nil {
 		rep.Lock()
 		rep.reserves = []Reserve{}
@@ -88,7 +89,7 @@ func (rep *MemoryRepository) Add(r Reserve) (res Reserve, err error) {
 	return
 }
 
-func (mr *MemoryRepository) Update(memr Reserve) error {
+func (mr *MemoryRepository) Update(ctx context.Context, memr Reserve) error {
 	for idx, res := range mr.reserves {
 		if res.Id == memr.Id {
 			mr.Lock()
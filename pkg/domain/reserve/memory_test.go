@@ -1,6 +1,7 @@
 package reserve
 
 import (
+	"context"
 	"reflect"
 	"testing"
 	"time"
@@ -75,7 +76,7 @@ func TestMemory_GetReserve(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 
-			_, err := repo.Get(tc.id)
+			_, err := repo.Get(context.Background(), tc.id)
 			if err != tc.expectedErr {
 				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
 			}
@@ -107,12 +108,12 @@ func TestMemory_AddReserve(t *testing.T) {
 			duration, _ := time.ParseDuration(tc.duration)
 			res := NewReserve(person.Person{Firstname: "Lily"}, time.Now(), time.Now().Add(duration))
 
-			_, err := repo.Add(res)
+			_, err := repo.Add(context.Background(), res)
 			if err != tc.expectedErr {
 				t.Errorf("Expected error %v, got %v", tc.expectedErr, err)
 			}
 
-			found, err := repo.Get(res.Id)
+			found, err := repo.Get(context.Background(), res.Id)
 			if err != nil {
 				t.Fatal(err)
 			}
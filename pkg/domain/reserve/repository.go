@@ -1,12 +1,14 @@
 package reserve
 
 import (
+	"context"
+
 	uuid "github.com/google/uuid"
 )
 
 type ReserveRepository interface {
-	Add(Reserve) (Reserve, error)
-	Get(uuid.UUID) (Reserve, error)
-	GetByFilter(res Reserve, oredered bool, sorted bool) ([]Reserve, error)
-	Update(Reserve) error
+	Add(context.Context, Reserve) (Reserve, error)
+	Get(context.Context, uuid.UUID) (Reserve, error)
+	GetByFilter(ctx context.Context, res Reserve, oredered bool, sorted bool) ([]Reserve, error)
+	Update(context.Context, Reserve) error
 }
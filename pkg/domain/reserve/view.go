@@ -2,6 +2,7 @@ package reserve
 
 import (
 	"fmt"
+	"volleybot/pkg/telegram"
 
 	"github.com/goodsign/monday"
 )
@@ -16,11 +17,11 @@ type TelegramViewResources struct {
 
 func NewTelegramResourcesRu() TelegramViewResources {
 	return TelegramViewResources{
-		CancelLabel: "🔥 *ОТМЕНА* 🔥",
+		CancelLabel: "🔥 ОТМЕНА 🔥",
 		DateLabel:   "📆",
 		TimeLabel:   "⏰",
 		Locale:      monday.LocaleRuRU,
-		ParseMode:   "Markdown",
+		ParseMode:   telegram.ParseModeHTML,
 	}
 }
 
@@ -44,30 +45,33 @@ func (tgv *TelegramView) String() string {
 }
 
 func (tgv *TelegramView) GetText() (text string) {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
 	if tgv.Reserve.Canceled {
-		text = tgv.CancelLabel + "\n\n"
+		tb.Bold(tgv.CancelLabel).Text("\n\n")
 	}
-	var uname string
-	if tgv.Reserve.Person.TelegramId != 0 {
-		uname = fmt.Sprintf("[%s](tg://user?id=%d)", tgv.Reserve.Person.String(), tgv.Reserve.Person.TelegramId)
-	} else {
-		uname = fmt.Sprintf("*%s*", tgv.Reserve.Person.String())
-	}
-	text += fmt.Sprintf("%s\n%s %s\n%s %s", uname,
+	tgv.WriteAuthor(tb)
+	tb.Textf("\n%s %s\n%s %s",
 		tgv.DateLabel, monday.Format(tgv.Reserve.StartTime, "Monday, 02.01.2006", tgv.Locale),
 		tgv.TimeLabel, tgv.GetTimeText())
 
 	if tgv.Reserve.Price > 0 {
-		text += fmt.Sprintf("\n💰 %d ₽", tgv.Reserve.Price)
+		tb.Textf("\n💰 %d ₽", tgv.Reserve.Price)
 	}
 
-	text += tgv.GetPlayersText()
-	return
+	return tb.String() + tgv.GetPlayersText()
+}
+
+func (tgv *TelegramView) WriteAuthor(tb *telegram.TextBuilder) {
+	if tgv.Reserve.Person.TelegramId != 0 {
+		tb.Mention(tgv.Reserve.Person.String(), tgv.Reserve.Person.TelegramId)
+	} else {
+		tb.Bold(tgv.Reserve.Person.String())
+	}
 }
 
 func (tgv *TelegramView) GetPlayersText() (text string) {
 	if tgv.Reserve.Description != "" {
-		text += "\n\n" + tgv.Reserve.Description
+		text += "\n\n" + telegram.EscapeText(tgv.ParseMode, tgv.Reserve.Description)
 	}
 	return
 }
@@ -24,7 +24,7 @@ func TestTelegramView(t *testing.T) {
 				EndTime:   time.Date(2021, 12, 04, 17, 0, 0, 0, time.UTC),
 				Price:     600,
 			},
-			text: "*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n💰 600 ₽",
+			text: "<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n💰 600 ₽",
 			str:  "Сб, 04.12 15:00-17:00",
 		},
 		"With description": {
@@ -35,10 +35,21 @@ func TestTelegramView(t *testing.T) {
 				Price:       600,
 				Description: "Some description.",
 			},
-			text: "*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n💰 600 ₽" +
+			text: "<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n💰 600 ₽" +
 				"\n\nSome description.",
 			str: "Сб, 04.12 15:00-17:00",
 		},
+		"Hostile description": {
+			res: Reserve{
+				Person:      person.Person{Firstname: "Ivan_Petrov", TelegramId: 123},
+				StartTime:   time.Date(2021, 12, 04, 15, 0, 0, 0, time.UTC),
+				EndTime:     time.Date(2021, 12, 04, 17, 0, 0, 0, time.UTC),
+				Description: "*Bring* <water> & [balls](x)",
+			},
+			text: "<a href=\"tg://user?id=123\">Ivan_Petrov</a>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00" +
+				"\n\n*Bring* &lt;water&gt; &amp; [balls](x)",
+			str: "Сб, 04.12 15:00-17:00",
+		},
 		"Canceled": {
 			res: Reserve{
 				Person:    pl1,
@@ -46,7 +57,7 @@ func TestTelegramView(t *testing.T) {
 				EndTime:   time.Date(2021, 12, 04, 17, 0, 0, 0, time.UTC),
 				Canceled:  true,
 			},
-			text: "🔥 *ОТМЕНА* 🔥\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00",
+			text: "<b>🔥 ОТМЕНА 🔥</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00",
 			str:  "Сб, 04.12 15:00-17:00",
 		},
 	}
@@ -1,6 +1,7 @@
 package volley
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -27,6 +28,10 @@ func NewMemoryRepository(reserves *[]Volley, filter Volley, ordered bool) (mr Me
 			continue
 		}
 
+		if filter.ScheduleId != uuid.Nil && v.ScheduleId != filter.ScheduleId {
+			continue
+		}
+
 		if filter.StartTime != (time.Time{}) && filter.StartTime.After(v.EndTime) {
 			continue
 		}
@@ -52,7 +57,7 @@ func NewMemoryRepository(reserves *[]Volley, filter Volley, ordered bool) (mr Me
 	return
 }
 
-func (mr *MemoryRepository) Get(id uuid.UUID) (Volley, error) {
+func (mr *MemoryRepository) Get(ctx context.Context, id uuid.UUID) (Volley, error) {
 	for _, res := range mr.reserves {
 		if res.Id == id {
 			return res, nil
@@ -61,12 +66,12 @@ func (mr *MemoryRepository) Get(id uuid.UUID) (Volley, error) {
 	return Volley{}, reserve.ErrReserveNotFound
 }
 
-func (rep *MemoryRepository) GetByFilter(filter Volley, ordered bool, sorted bool) (res []Volley, err error) {
+func (rep *MemoryRepository) GetByFilter(ctx context.Context, filter Volley, ordered bool, sorted bool) (res []Volley, err error) {
 	newrep := NewMemoryRepository(&rep.reserves, filter, ordered)
 	return newrep.reserves, nil
 }
 
-func (rep *MemoryRepository) Add(r Volley) (res Volley, err error) {
+func (rep *MemoryRepository) Add(ctx context.Context, r Volley) (res Volley, err error) {
 	if rep.reserves == nil {
 		rep.Lock()
 		rep.reserves = []Volley{}
@@ -86,7 +91,7 @@ func (rep *MemoryRepository) Add(r Volley) (res Volley, err error) {
 	return
 }
 
-func (mr *MemoryRepository) Update(memr Volley) error {
+func (mr *MemoryRepository) Update(ctx context.Context, memr Volley) error {
 	for idx, res := range mr.reserves {
 		if res.Id == memr.Id {
 			mr.Lock()
@@ -98,7 +103,7 @@ func (mr *MemoryRepository) Update(memr Volley) error {
 	return fmt.Errorf("reserve does not exist: %w", reserve.ErrUpdateReserve)
 }
 
-func (mr *MemoryRepository) AddMember(r Volley, mb Member) (Volley, error) {
+func (mr *MemoryRepository) AddMember(ctx context.Context, r Volley, mb Member) (Volley, error) {
 	for i, p := range r.Members {
 		if p.Id == mb.Id {
 			r.Members[i] = mb
@@ -109,7 +114,7 @@ func (mr *MemoryRepository) AddMember(r Volley, mb Member) (Volley, error) {
 	return r, nil
 }
 
-func (mr *MemoryRepository) UpdateMember(r Volley, mb Member) (Volley, error) {
+func (mr *MemoryRepository) UpdateMember(ctx context.Context, r Volley, mb Member) (Volley, error) {
 	for i, p := range r.Members {
 		if p.Id == mb.Id {
 			r.Members[i] = mb
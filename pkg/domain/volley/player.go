@@ -15,7 +15,8 @@ func NewPlayer(prsn person.Person) Player {
 
 type Player struct {
 	person.Person
-	Level PlayerLevel `json:"level"`
+	Level  PlayerLevel `json:"level"`
+	Rating int         `json:"rating"`
 }
 
 func (pl Player) String() string {
@@ -37,9 +38,16 @@ type Member struct {
 	MemberId   int
 	Count      int
 	ArriveTime time.Time
+	PromotedAt time.Time
 	paid       bool
 }
 
+// Pending reports whether the member was moved up from the reserve
+// and has not confirmed the seat yet.
+func (m Member) Pending() bool {
+	return m.Count > 0 && !m.PromotedAt.IsZero()
+}
+
 func (m Member) GetPaid() bool {
 	return m.paid
 }
@@ -0,0 +1,145 @@
+package volley
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+	"volleybot/pkg/domain/location"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultRating = 1500
+	// ProvisionalGames is the number of games while rating changes faster.
+	ProvisionalGames = 10
+)
+
+var (
+	ErrRatingNotFound = errors.New("the rating was not found in the repository")
+	ErrInvalidScore   = errors.New("invalid set score")
+)
+
+type RatingRepository interface {
+	Get(ctx context.Context, loc location.Location, pid uuid.UUID) (Rating, error)
+	GetHistory(ctx context.Context, loc location.Location, pid uuid.UUID) ([]RatingChange, error)
+	Apply(ctx context.Context, changes []RatingChange) error
+}
+
+func NewRating(loc location.Location, pid uuid.UUID) Rating {
+	return Rating{PersonId: pid, LocationId: loc.Id, Value: DefaultRating}
+}
+
+// Rating is an Elo rating of the player at the location.
+type Rating struct {
+	PersonId   uuid.UUID `json:"person_id"`
+	LocationId uuid.UUID `json:"location_id"`
+	Value      int       `json:"value"`
+	Games      int       `json:"games"`
+}
+
+func (r Rating) K() float64 {
+	if r.Games < ProvisionalGames {
+		return 40
+	}
+	return 20
+}
+
+type RatingChange struct {
+	PersonId   uuid.UUID `json:"person_id"`
+	LocationId uuid.UUID `json:"location_id"`
+	ReserveId  uuid.UUID `json:"reserve_id"`
+	Before     int       `json:"before"`
+	After      int       `json:"after"`
+	Games      int       `json:"games"`
+	Time       time.Time `json:"time"`
+}
+
+type SetResult struct {
+	Court int    `json:"court"`
+	Score [2]int `json:"score"`
+}
+
+// CourtTeams returns the two teams playing on the court.
+func (v *Volley) CourtTeams(court int) (teams []Team) {
+	for _, tm := range v.Teams {
+		if tm.Court == court {
+			teams = append(teams, tm)
+		}
+	}
+	return
+}
+
+func (v *Volley) AddSet(court int, a int, b int) error {
+	if len(v.CourtTeams(court)) != 2 || a < 0 || b < 0 || a == b {
+		return ErrInvalidScore
+	}
+	v.Results = append(v.Results, SetResult{Court: court, Score: [2]int{a, b}})
+	return nil
+}
+
+func (v *Volley) RemoveLastSet() {
+	if len(v.Results) > 0 {
+		v.Results = v.Results[:len(v.Results)-1]
+	}
+}
+
+// RateGame applies set results to ratings of members, guests are not rated.
+// Every set is an Elo match of teams with average ratings of their players.
+func RateGame(v Volley, ratings map[uuid.UUID]Rating, now time.Time) (changes []RatingChange) {
+	current := map[uuid.UUID]float64{}
+	for id, r := range ratings {
+		current[id] = float64(r.Value)
+	}
+	players := func(tm Team) (ids []uuid.UUID) {
+		for _, tp := range tm.Players {
+			if _, ok := ratings[tp.Id]; ok && tp.Guest == 0 {
+				ids = append(ids, tp.Id)
+			}
+		}
+		return
+	}
+	average := func(ids []uuid.UUID) (sum float64) {
+		for _, id := range ids {
+			sum += current[id]
+		}
+		return sum / float64(len(ids))
+	}
+	played := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, set := range v.Results {
+		teams := v.CourtTeams(set.Court)
+		if len(teams) != 2 {
+			continue
+		}
+		a, b := players(teams[0]), players(teams[1])
+		if len(a) == 0 || len(b) == 0 {
+			continue
+		}
+		ra, rb := average(a), average(b)
+		ea := 1 / (1 + math.Pow(10, (rb-ra)/400))
+		sa := 0.0
+		if set.Score[0] > set.Score[1] {
+			sa = 1
+		}
+		for _, id := range a {
+			current[id] += ratings[id].K() * (sa - ea)
+		}
+		for _, id := range b {
+			current[id] += ratings[id].K() * (ea - sa)
+		}
+		for _, id := range append(a, b...) {
+			if !seen[id] {
+				seen[id] = true
+				played = append(played, id)
+			}
+		}
+	}
+	for _, id := range played {
+		r := ratings[id]
+		changes = append(changes, RatingChange{PersonId: id, LocationId: r.LocationId, ReserveId: v.Id,
+			Before: r.Value, After: int(math.Round(current[id])), Games: r.Games + 1, Time: now})
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package volley
+
+import (
+	"testing"
+	"time"
+	"volleybot/pkg/domain/location"
+
+	"github.com/google/uuid"
+)
+
+func TestRateGame(t *testing.T) {
+	loc := location.Location{Id: uuid.New()}
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	newVolley := func(results ...SetResult) Volley {
+		v := Volley{CourtCount: 1, MaxPlayers: 4}
+		v.Id = uuid.New()
+		v.Teams = []Team{
+			{Court: 1, Players: []TeamPlayer{{Id: ids[0]}, {Id: ids[1]}, {Id: ids[1], Guest: 1}}},
+			{Court: 1, Players: []TeamPlayer{{Id: ids[2]}, {Id: ids[3]}}},
+		}
+		v.Results = results
+		return v
+	}
+	newRatings := func(values ...int) map[uuid.UUID]Rating {
+		ratings := map[uuid.UUID]Rating{}
+		for i, val := range values {
+			r := NewRating(loc, ids[i])
+			r.Value = val
+			r.Games = ProvisionalGames
+			ratings[ids[i]] = r
+		}
+		return ratings
+	}
+	after := func(changes []RatingChange) map[uuid.UUID]int {
+		res := map[uuid.UUID]int{}
+		for _, c := range changes {
+			res[c.PersonId] = c.After
+		}
+		return res
+	}
+
+	tests := map[string]struct {
+		v       Volley
+		ratings map[uuid.UUID]Rating
+		after   []int
+	}{
+		"Equal teams":     {v: newVolley(SetResult{Court: 1, Score: [2]int{21, 15}}), ratings: newRatings(1500, 1500, 1500, 1500), after: []int{1510, 1510, 1490, 1490}},
+		"Favorite wins":   {v: newVolley(SetResult{Court: 1, Score: [2]int{21, 15}}), ratings: newRatings(1700, 1700, 1500, 1500), after: []int{1705, 1705, 1495, 1495}},
+		"Underdog wins":   {v: newVolley(SetResult{Court: 1, Score: [2]int{15, 21}}), ratings: newRatings(1700, 1700, 1500, 1500), after: []int{1685, 1685, 1515, 1515}},
+		"Two sets split":  {v: newVolley(SetResult{Court: 1, Score: [2]int{21, 15}}, SetResult{Court: 1, Score: [2]int{19, 21}}), ratings: newRatings(1500, 1500, 1500, 1500), after: []int{1499, 1499, 1501, 1501}},
+		"No results":      {v: newVolley(), ratings: newRatings(1500, 1500, 1500, 1500)},
+		"Unknown court":   {v: newVolley(SetResult{Court: 2, Score: [2]int{21, 15}}), ratings: newRatings(1500, 1500, 1500, 1500)},
+		"Unrated players": {v: newVolley(SetResult{Court: 1, Score: [2]int{21, 15}}), ratings: newRatings(1500, 1500)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			changes := RateGame(test.v, test.ratings, time.Now())
+			if len(changes) != len(test.after) {
+				t.FailNow()
+			}
+			res := after(changes)
+			for i, val := range test.after {
+				if res[ids[i]] != val {
+					t.Errorf("player %d: %d != %d", i, res[ids[i]], val)
+				}
+			}
+			for _, c := range changes {
+				if c.ReserveId != test.v.Id || c.Games != ProvisionalGames+1 || c.LocationId != loc.Id {
+					t.Fail()
+				}
+			}
+		})
+	}
+
+	t.Run("Provisional rating", func(t *testing.T) {
+		ratings := newRatings(1500, 1500, 1500, 1500)
+		r := ratings[ids[0]]
+		r.Games = 0
+		ratings[ids[0]] = r
+		res := after(RateGame(newVolley(SetResult{Court: 1, Score: [2]int{21, 15}}), ratings, time.Now()))
+		if res[ids[0]] != 1520 || res[ids[1]] != 1510 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Add set", func(t *testing.T) {
+		v := newVolley()
+		if v.AddSet(1, 21, 21) == nil || v.AddSet(2, 21, 15) == nil || v.AddSet(1, -1, 15) == nil {
+			t.Fail()
+		}
+		if v.AddSet(1, 21, 15) != nil || v.AddSet(1, 10, 15) != nil || len(v.Results) != 2 {
+			t.FailNow()
+		}
+		v.RemoveLastSet()
+		if len(v.Results) != 1 || v.Results[0].Score != [2]int{21, 15} {
+			t.Fail()
+		}
+	})
+}
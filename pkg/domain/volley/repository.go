@@ -1,19 +1,20 @@
 package volley
 
 import (
+	"context"
 	"volleybot/pkg/domain/person"
 
 	uuid "github.com/google/uuid"
 )
 
 type Repository interface {
-	Add(Volley) (Volley, error)
-	AddMember(Volley, Member) (Volley, error)
-	AddPlayer(Player) (Player, error)
-	Get(uuid.UUID) (Volley, error)
-	GetByFilter(res Volley, oredered bool, sorted bool) ([]Volley, error)
-	GetPlayer(person.Person) (Player, error)
-	UpdateMember(Volley, Member) (Volley, error)
-	UpdatePlayer(Player) error
-	Update(Volley) error
+	Add(context.Context, Volley) (Volley, error)
+	AddMember(context.Context, Volley, Member) (Volley, error)
+	AddPlayer(context.Context, Player) (Player, error)
+	Get(context.Context, uuid.UUID) (Volley, error)
+	GetByFilter(ctx context.Context, res Volley, oredered bool, sorted bool) ([]Volley, error)
+	GetPlayer(context.Context, person.Person) (Player, error)
+	UpdateMember(context.Context, Volley, Member) (Volley, error)
+	UpdatePlayer(context.Context, Player) error
+	Update(context.Context, Volley) error
 }
@@ -0,0 +1,232 @@
+package volley
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"volleybot/pkg/domain/location"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("the schedule was not found in the repository")
+	ErrInvalidRule      = errors.New("invalid schedule rule")
+
+	ruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+)
+
+type ScheduleRepository interface {
+	Add(context.Context, Schedule) (Schedule, error)
+	Get(context.Context, uuid.UUID) (Schedule, error)
+	GetByLocation(context.Context, location.Location) ([]Schedule, error)
+	Update(context.Context, Schedule) error
+	Delete(context.Context, uuid.UUID) error
+}
+
+// NewSchedule creates a weekly schedule repeating the game on its weekday.
+func NewSchedule(v Volley) (s Schedule) {
+	s.Id = uuid.New()
+	s.Interval = 1
+	s.Weekdays = []time.Weekday{v.StartTime.Weekday()}
+	s.Generated = v.StartTime
+	s.SetTemplate(v)
+	return
+}
+
+// Schedule is a weekly template for games. Template keeps game settings,
+// its start time is the first occurrence and its duration is the game length.
+type Schedule struct {
+	Id        uuid.UUID      `json:"id"`
+	Template  Volley         `json:"template"`
+	Weekdays  []time.Weekday `json:"weekdays"`
+	Interval  int            `json:"interval"`
+	Paused    bool           `json:"paused"`
+	Generated time.Time      `json:"generated"`
+}
+
+func (s *Schedule) SetTemplate(v Volley) {
+	v.Id = s.Id
+	v.Members = []Member{}
+	v.PollId = ""
+	v.ScheduleId = uuid.Nil
+	v.Teams = nil
+	v.Together = nil
+	v.Results = nil
+	v.Rated = false
+	v.Bracket.Reset()
+	v.Canceled = false
+	if !s.Template.StartTime.IsZero() {
+		first, dur := s.Template.StartTime, v.GetDuration()
+		v.StartTime = time.Date(first.Year(), first.Month(), first.Day(),
+			v.StartTime.Hour(), v.StartTime.Minute(), 0, 0, v.StartTime.Location())
+		v.EndTime = v.StartTime.Add(dur)
+	}
+	s.Template = v
+}
+
+// Rule returns the schedule as an RRULE-like string: FREQ=WEEKLY;INTERVAL=1;BYDAY=TU,TH
+func (s Schedule) Rule() string {
+	days := []string{}
+	for _, d := range s.sortedWeekdays() {
+		days = append(days, ruleDays[d])
+	}
+	return fmt.Sprintf("FREQ=WEEKLY;INTERVAL=%d;BYDAY=%s", s.Interval, strings.Join(days, ","))
+}
+
+func (s *Schedule) SetRule(rule string) (err error) {
+	interval := 1
+	days := []time.Weekday{}
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("%w: %s", ErrInvalidRule, rule)
+		}
+		switch kv[0] {
+		case "FREQ":
+			if kv[1] != "WEEKLY" {
+				return fmt.Errorf("%w: %s", ErrInvalidRule, rule)
+			}
+		case "INTERVAL":
+			if interval, err = strconv.Atoi(kv[1]); err != nil || interval < 1 {
+				return fmt.Errorf("%w: %s", ErrInvalidRule, rule)
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(kv[1], ",") {
+				found := false
+				for i, name := range ruleDays {
+					if name == day {
+						days = append(days, time.Weekday(i))
+						found = true
+					}
+				}
+				if !found && day != "" {
+					return fmt.Errorf("%w: %s", ErrInvalidRule, rule)
+				}
+			}
+		}
+	}
+	s.Interval = interval
+	s.Weekdays = days
+	return nil
+}
+
+func (s Schedule) HasWeekday(d time.Weekday) bool {
+	for _, wd := range s.Weekdays {
+		if wd == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) ToggleWeekday(d time.Weekday) {
+	days := []time.Weekday{}
+	for _, wd := range s.Weekdays {
+		if wd != d {
+			days = append(days, wd)
+		}
+	}
+	if len(days) == len(s.Weekdays) {
+		days = append(days, d)
+	}
+	s.Weekdays = days
+}
+
+func (s Schedule) sortedWeekdays() []time.Weekday {
+	days := append([]time.Weekday{}, s.Weekdays...)
+	sort.Slice(days, func(i, j int) bool {
+		return (days[i]+6)%7 < (days[j]+6)%7
+	})
+	return days
+}
+
+// Occurrences returns start times of games after from and not later than to.
+func (s Schedule) Occurrences(from, to time.Time) (starts []time.Time) {
+	first := s.Template.StartTime
+	if first.IsZero() || len(s.Weekdays) == 0 {
+		return
+	}
+	interval := s.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	tz := first.Location()
+	firstWeek := time.Date(first.Year(), first.Month(), first.Day()-(int(first.Weekday())+6)%7, 0, 0, 0, 0, tz)
+	from, to = from.In(tz), to.In(tz)
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, tz); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !s.HasWeekday(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), first.Hour(), first.Minute(), 0, 0, tz)
+		if !start.After(from) || start.After(to) || start.Before(first) {
+			continue
+		}
+		weeks := int(day.Sub(firstWeek).Hours()+12) / (24 * 7)
+		if weeks%interval == 0 {
+			starts = append(starts, start)
+		}
+	}
+	return
+}
+
+// NewVolley materialises the game of the schedule starting at start.
+func (s Schedule) NewVolley(start time.Time) (v Volley) {
+	v = s.Template
+	v.Id = uuid.New()
+	v.Members = []Member{}
+	v.StartTime = start
+	v.EndTime = start.Add(s.Template.GetDuration())
+	v.ScheduleId = s.Id
+	return
+}
+
+// Generate returns games of the occurrences after the generation mark up to
+// the horizon and moves the mark forward. Occurrences up to the mark are never
+// generated again, so moved and deleted games stay as the organizer left them.
+// Existing games of the schedule skip their occurrences, they are there when
+// the games were added but the mark was not saved.
+func (s *Schedule) Generate(now, horizon time.Time, existing []Volley) (vlist []Volley) {
+	if s.Paused {
+		return
+	}
+	from := now
+	if s.Generated.After(from) {
+		from = s.Generated
+	}
+	for _, start := range s.Occurrences(from, horizon) {
+		s.Generated = start
+		if !s.hasGame(existing, start) {
+			vlist = append(vlist, s.NewVolley(start))
+		}
+	}
+	return
+}
+
+func (s Schedule) hasGame(vlist []Volley, start time.Time) bool {
+	for _, v := range vlist {
+		if v.ScheduleId == s.Id && v.StartTime.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply copies template settings and time of the day to the game keeping its date.
+func (s Schedule) Apply(v *Volley) {
+	t := s.Template
+	v.Price = t.Price
+	v.MinLevel = t.MinLevel
+	v.CourtCount = t.CourtCount
+	v.MaxPlayers = t.MaxPlayers
+	v.NetType = t.NetType
+	v.Activity = t.Activity
+	v.Description = t.Description
+	v.StartTime = time.Date(v.StartTime.Year(), v.StartTime.Month(), v.StartTime.Day(),
+		t.StartTime.Hour(), t.StartTime.Minute(), 0, 0, v.StartTime.Location())
+	v.EndTime = v.StartTime.Add(t.GetDuration())
+}
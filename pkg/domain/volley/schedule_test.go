@@ -0,0 +1,123 @@
+package volley
+
+import (
+	"testing"
+	"time"
+	"volleybot/pkg/domain/person"
+
+	"github.com/google/uuid"
+)
+
+func TestScheduleRule(t *testing.T) {
+	tests := map[string]struct {
+		rule     string
+		want     string
+		interval int
+		err      bool
+	}{
+		"Weekly":        {rule: "FREQ=WEEKLY;BYDAY=TH,TU", want: "FREQ=WEEKLY;INTERVAL=1;BYDAY=TU,TH", interval: 1},
+		"Every 2 weeks": {rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU", interval: 2},
+		"Daily":         {rule: "FREQ=DAILY", err: true},
+		"Bad day":       {rule: "FREQ=WEEKLY;BYDAY=XX", err: true},
+		"Bad interval":  {rule: "FREQ=WEEKLY;INTERVAL=0;BYDAY=MO", err: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := Schedule{}
+			err := s.SetRule(test.rule)
+			if (err != nil) != test.err {
+				t.FailNow()
+			}
+			if !test.err && (s.Rule() != test.want || s.Interval != test.interval) {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestScheduleGenerate(t *testing.T) {
+	author := person.Person{Id: uuid.New(), Firstname: "Elly"}
+	// Tuesday
+	start := time.Date(2023, 3, 7, 19, 0, 0, 0, time.UTC)
+	v := NewVolley(author, start, start.Add(2*time.Hour))
+	v.Price = 600
+	v.Description = "Weekly"
+	v.Members = []Member{{Player: Player{Person: author}, Count: 1}}
+
+	t.Run("Occurrences", func(t *testing.T) {
+		s := NewSchedule(v)
+		s.ToggleWeekday(time.Thursday)
+		got := s.Occurrences(start, start.AddDate(0, 0, 14))
+		want := []int{9, 14, 16, 21}
+		if len(got) != len(want) {
+			t.FailNow()
+		}
+		for i, day := range want {
+			if got[i].Day() != day || got[i].Hour() != 19 {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Interval", func(t *testing.T) {
+		s := NewSchedule(v)
+		s.Interval = 2
+		got := s.Occurrences(start.AddDate(0, 0, -7), start.AddDate(0, 0, 28))
+		if len(got) != 3 || got[0].Day() != 7 || got[1].Day() != 21 || got[2].Day() != 4 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Generate", func(t *testing.T) {
+		s := NewSchedule(v)
+		vlist := s.Generate(start, start.AddDate(0, 0, 10), nil)
+		if len(vlist) != 1 || !s.Generated.Equal(start.AddDate(0, 0, 7)) {
+			t.FailNow()
+		}
+		g := vlist[0]
+		if g.ScheduleId != s.Id || g.Price != 600 || g.Description != "Weekly" || len(g.Members) != 0 ||
+			g.GetDuration() != 2*time.Hour || g.Id == v.Id {
+			t.Fail()
+		}
+		if len(s.Generate(start, start.AddDate(0, 0, 10), nil)) != 0 {
+			t.Error("occurrence before the generation mark generated again")
+		}
+		unsaved := NewSchedule(v)
+		unsaved.Id = s.Id
+		if len(unsaved.Generate(start, start.AddDate(0, 0, 10), vlist)) != 0 || !unsaved.Generated.Equal(s.Generated) {
+			t.Error("occurrence of the existing game generated twice")
+		}
+		other := vlist[0]
+		other.ScheduleId = uuid.New()
+		unsaved = NewSchedule(v)
+		unsaved.Id = s.Id
+		if len(unsaved.Generate(start, start.AddDate(0, 0, 10), []Volley{other})) != 1 {
+			t.Error("game of other schedule skipped the occurrence")
+		}
+		s.Paused = true
+		if len(s.Generate(start, start.AddDate(0, 0, 30), nil)) != 0 {
+			t.Error("paused schedule generated games")
+		}
+	})
+
+	t.Run("Apply to future", func(t *testing.T) {
+		s := NewSchedule(v)
+		edited := v
+		edited.Price = 800
+		edited.StartTime = start.AddDate(0, 0, 7).Add(time.Hour)
+		edited.EndTime = edited.StartTime.Add(3 * time.Hour)
+		s.SetTemplate(edited)
+		if !s.Template.StartTime.Equal(start.Add(time.Hour)) || s.Template.GetDuration() != 3*time.Hour {
+			t.FailNow()
+		}
+		future := s.NewVolley(start.AddDate(0, 0, 14))
+		future.Price = 100
+		future.StartTime = start.AddDate(0, 0, 14)
+		s.Apply(&future)
+		if future.Price != 800 || future.StartTime.Hour() != 20 || future.StartTime.Day() != 21 ||
+			future.GetDuration() != 3*time.Hour {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package volley
+
+import (
+	"math/rand"
+	"sort"
+	"volleybot/pkg/domain/person"
+
+	"github.com/google/uuid"
+)
+
+// TeamPlayer is a seat of the main roster. Guest is 0 for the member
+// and N for the member's N-th guest, guests take level of the member.
+type TeamPlayer struct {
+	Id    uuid.UUID   `json:"id"`
+	Guest int         `json:"guest"`
+	Level PlayerLevel `json:"level"`
+	Sex   person.Sex  `json:"sex"`
+}
+
+type Team struct {
+	Court   int          `json:"court"`
+	Players []TeamPlayer `json:"players"`
+}
+
+func (t Team) Level() (sum int) {
+	for _, pl := range t.Players {
+		sum += int(pl.Level)
+	}
+	return
+}
+
+func (t Team) SexCount(s person.Sex) (count int) {
+	for _, pl := range t.Players {
+		if pl.Sex == s {
+			count++
+		}
+	}
+	return
+}
+
+// Pair keeps two members in the same team.
+type Pair [2]uuid.UUID
+
+func (p Pair) Has(id uuid.UUID) bool {
+	return p[0] == id || p[1] == id
+}
+
+// Seats returns players of the main roster including guests.
+// Players without level are counted as middle ones.
+func (v *Volley) Seats() (seats []TeamPlayer) {
+	for _, mb := range v.Members {
+		lvl := mb.Level
+		if lvl == Nothing {
+			lvl = Middle
+		}
+		for g := 0; g < mb.Count && len(seats) < v.MaxPlayers; g++ {
+			tp := TeamPlayer{Id: mb.Id, Guest: g, Level: lvl}
+			if g == 0 {
+				tp.Sex = mb.Sex
+			}
+			seats = append(seats, tp)
+		}
+	}
+	return
+}
+
+func (v *Volley) TogglePair(a, b uuid.UUID) {
+	if a == b {
+		return
+	}
+	pairs := []Pair{}
+	for _, p := range v.Together {
+		if !(p.Has(a) && p.Has(b)) {
+			pairs = append(pairs, p)
+		}
+	}
+	if len(pairs) == len(v.Together) {
+		pairs = append(pairs, Pair{a, b})
+	}
+	v.Together = pairs
+}
+
+func (v *Volley) BalanceTeams(rnd *rand.Rand) {
+	v.Teams = BalanceTeams(v.Seats(), v.CourtCount, v.Together, rnd)
+}
+
+// BalanceTeams splits seats into two teams per court. Players kept together
+// go first, then stronger ones, each to the team with fewer players of the same
+// sex and lower total level. rnd shuffles players of close levels.
+func BalanceTeams(seats []TeamPlayer, courts int, pairs []Pair, rnd *rand.Rand) (teams []Team) {
+	if courts < 1 {
+		courts = 1
+	}
+	count := courts * 2
+	for count > 2 && len(seats) < count*2 {
+		count -= 2
+	}
+	for i := 0; i < count; i++ {
+		teams = append(teams, Team{Court: i/2 + 1, Players: []TeamPlayer{}})
+	}
+	if len(seats) == 0 {
+		return
+	}
+	capacity := (len(seats) + count - 1) / count
+
+	units := groupSeats(seats, pairs)
+	if rnd != nil {
+		rnd.Shuffle(len(units), func(i, j int) { units[i], units[j] = units[j], units[i] })
+	}
+	unitLevel := func(u []TeamPlayer) (sum int) {
+		for _, pl := range u {
+			sum += int(pl.Level)
+		}
+		return sum / len(u) / 20
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		if len(units[i]) != len(units[j]) {
+			return len(units[i]) > len(units[j])
+		}
+		return unitLevel(units[i]) > unitLevel(units[j])
+	})
+
+	for _, u := range units {
+		best := -1
+		for i, t := range teams {
+			if best < 0 {
+				best = i
+				continue
+			}
+			b := teams[best]
+			fits, bfits := len(t.Players)+len(u) <= capacity, len(b.Players)+len(u) <= capacity
+			if fits != bfits {
+				if fits {
+					best = i
+				}
+				continue
+			}
+			if sc, bsc := t.SexCount(u[0].Sex), b.SexCount(u[0].Sex); u[0].Sex != 0 && sc != bsc {
+				if sc < bsc {
+					best = i
+				}
+				continue
+			}
+			if t.Level() != b.Level() {
+				if t.Level() < b.Level() {
+					best = i
+				}
+				continue
+			}
+			if len(t.Players) < len(b.Players) {
+				best = i
+			}
+		}
+		teams[best].Players = append(teams[best].Players, u...)
+	}
+	return
+}
+
+// groupSeats joins seats of paired members into units.
+func groupSeats(seats []TeamPlayer, pairs []Pair) (units [][]TeamPlayer) {
+	group := map[uuid.UUID]uuid.UUID{}
+	var root func(id uuid.UUID) uuid.UUID
+	root = func(id uuid.UUID) uuid.UUID {
+		if r, ok := group[id]; ok && r != id {
+			return root(r)
+		}
+		return id
+	}
+	for _, p := range pairs {
+		if ra, rb := root(p[0]), root(p[1]); ra != rb {
+			group[ra] = rb
+		}
+	}
+	index := map[uuid.UUID]int{}
+	for _, s := range seats {
+		if s.Guest > 0 {
+			units = append(units, []TeamPlayer{s})
+			continue
+		}
+		r := root(s.Id)
+		if i, ok := index[r]; ok {
+			units[i] = append(units[i], s)
+			continue
+		}
+		index[r] = len(units)
+		units = append(units, []TeamPlayer{s})
+	}
+	return
+}
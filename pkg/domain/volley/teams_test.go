@@ -0,0 +1,100 @@
+package volley
+
+import (
+	"math/rand"
+	"testing"
+	"volleybot/pkg/domain/person"
+
+	"github.com/google/uuid"
+)
+
+func TestBalanceTeams(t *testing.T) {
+	newMember := func(name string, lvl PlayerLevel, sex person.Sex, count int) Member {
+		p := person.Person{Id: uuid.New(), Firstname: name, Sex: sex}
+		return Member{Player: Player{Person: p, Level: lvl}, Count: count}
+	}
+	anna := newMember("Anna", Advanced, 2, 1)
+	bob := newMember("Bob", Advanced, 1, 1)
+	carl := newMember("Carl", Novice, 1, 1)
+	dina := newMember("Dina", Novice, 2, 1)
+	eve := newMember("Eve", Middle, 2, 1)
+	fred := newMember("Fred", Middle, 1, 2)
+
+	newVolley := func(courts int, members ...Member) Volley {
+		v := Volley{CourtCount: courts, MaxPlayers: 12}
+		v.Members = members
+		return v
+	}
+	placed := func(teams []Team) map[uuid.UUID]int {
+		res := map[uuid.UUID]int{}
+		for i, tm := range teams {
+			for _, pl := range tm.Players {
+				if pl.Guest == 0 {
+					res[pl.Id] = i
+				}
+			}
+		}
+		return res
+	}
+
+	t.Run("Level and sex", func(t *testing.T) {
+		v := newVolley(1, anna, bob, carl, dina)
+		v.BalanceTeams(nil)
+		if len(v.Teams) != 2 || len(v.Teams[0].Players) != 2 || len(v.Teams[1].Players) != 2 {
+			t.FailNow()
+		}
+		if v.Teams[0].Level() != v.Teams[1].Level() {
+			t.Fail()
+		}
+		for _, tm := range v.Teams {
+			if tm.SexCount(1) != 1 || tm.SexCount(2) != 1 {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Guests", func(t *testing.T) {
+		v := newVolley(1, anna, fred, eve)
+		seats := v.Seats()
+		if len(seats) != 4 || seats[2].Id != fred.Id || seats[2].Guest != 1 || seats[2].Sex != 0 {
+			t.FailNow()
+		}
+		v.MaxPlayers = 3
+		if len(v.Seats()) != 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Together", func(t *testing.T) {
+		v := newVolley(1, anna, bob, carl, dina)
+		v.TogglePair(anna.Id, bob.Id)
+		for i := int64(0); i < 10; i++ {
+			v.BalanceTeams(rand.New(rand.NewSource(i)))
+			if p := placed(v.Teams); p[anna.Id] != p[bob.Id] {
+				t.FailNow()
+			}
+		}
+		v.TogglePair(bob.Id, anna.Id)
+		if len(v.Together) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Courts", func(t *testing.T) {
+		v := newVolley(2, anna, bob, carl, dina, eve, fred)
+		v.BalanceTeams(nil)
+		if len(v.Teams) != 2 {
+			t.Error("few players should play on one court")
+		}
+		v.Members = append(v.Members, newMember("Gleb", Middle, 1, 1), newMember("Hope", Middle, 2, 1))
+		v.BalanceTeams(nil)
+		if len(v.Teams) != 4 || v.Teams[3].Court != 2 {
+			t.FailNow()
+		}
+		for _, tm := range v.Teams {
+			if len(tm.Players) < 2 || len(tm.Players) > 3 {
+				t.Fail()
+			}
+		}
+	})
+}
@@ -0,0 +1,609 @@
+package volley
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TournamentFormat int
+
+const (
+	RoundRobin        TournamentFormat = 0
+	GroupsPlayoff     TournamentFormat = 10
+	SingleElimination TournamentFormat = 20
+	DoubleElimination TournamentFormat = 30
+)
+
+func (f TournamentFormat) String() string {
+	lnames := make(map[int]string)
+	lnames[0] = "Круговая система"
+	lnames[10] = "Группы и плей-офф"
+	lnames[20] = "Олимпийская система"
+	lnames[30] = "Двойное выбывание"
+	return lnames[int(f)]
+}
+
+// Next is used to switch formats by one button.
+func (f TournamentFormat) Next() TournamentFormat {
+	if f >= DoubleElimination {
+		return RoundRobin
+	}
+	return f + 10
+}
+
+type Seeding int
+
+const (
+	SeedByLevel  Seeding = 0
+	SeedByRating Seeding = 10
+)
+
+func (s Seeding) String() string {
+	if s == SeedByRating {
+		return "по рейтингу"
+	}
+	return "по уровню"
+}
+
+type Stage string
+
+const (
+	GroupStage Stage = "group"
+	UpperStage Stage = "upper"
+	LowerStage Stage = "lower"
+	FinalStage Stage = "final"
+)
+
+const (
+	// Bye is a team id of an empty bracket slot.
+	Bye              = -1
+	DefaultTeamSize  = 2
+	MinMatchDuration = 10 * time.Minute
+)
+
+var (
+	ErrNotEnoughTeams = errors.New("not enough teams for the tournament")
+	ErrMatchNotFound  = errors.New("the match was not found in the bracket")
+	ErrMatchNotReady  = errors.New("the match is not ready to be scored")
+)
+
+type TournamentTeam struct {
+	Id      int          `json:"id"`
+	Name    string       `json:"name"`
+	Players []TeamPlayer `json:"players"`
+	Level   int          `json:"level"`
+	Rating  int          `json:"rating"`
+}
+
+// Link points to the match slot where a team goes after the match.
+type Link struct {
+	Match int `json:"match"`
+	Slot  int `json:"slot"`
+}
+
+// Match keeps team ids, 0 is for a team that is not known yet.
+// Next links winner and loser of the match to the following matches.
+type Match struct {
+	Id     int       `json:"id"`
+	Stage  Stage     `json:"stage"`
+	Group  int       `json:"group"`
+	Round  int       `json:"round"`
+	Wave   int       `json:"wave"`
+	Teams  [2]int    `json:"teams"`
+	Score  [2]int    `json:"score"`
+	Winner int       `json:"winner"`
+	Next   [2]Link   `json:"next"`
+	Court  int       `json:"court"`
+	Start  time.Time `json:"start"`
+}
+
+func (m Match) Bye() bool {
+	return m.Teams[0] == Bye || m.Teams[1] == Bye
+}
+
+func (m Match) Ready() bool {
+	return m.Winner == 0 && m.Teams[0] > 0 && m.Teams[1] > 0
+}
+
+func (m Match) Loser() int {
+	if m.Winner == m.Teams[0] {
+		return m.Teams[1]
+	}
+	return m.Teams[0]
+}
+
+type Standing struct {
+	Team   int `json:"team"`
+	Played int `json:"played"`
+	Wins   int `json:"wins"`
+	Diff   int `json:"diff"`
+}
+
+// Bracket keeps tournament settings and, after the start, its teams numbered by seed and matches.
+type Bracket struct {
+	Format   TournamentFormat `json:"format"`
+	Seeding  Seeding          `json:"seeding"`
+	TeamSize int              `json:"team_size"`
+	Groups   int              `json:"groups"`
+	Teams    []TournamentTeam `json:"teams"`
+	Matches  []Match          `json:"matches"`
+}
+
+func (b Bracket) Size() int {
+	if b.TeamSize < 1 {
+		return DefaultTeamSize
+	}
+	return b.TeamSize
+}
+
+func (b Bracket) Started() bool {
+	return len(b.Matches) > 0
+}
+
+func (b Bracket) Done() bool {
+	for _, m := range b.Matches {
+		if m.Winner == 0 {
+			return false
+		}
+	}
+	return b.Started()
+}
+
+func (b Bracket) Team(id int) (tm TournamentTeam) {
+	for _, tm := range b.Teams {
+		if tm.Id == id {
+			return tm
+		}
+	}
+	return
+}
+
+func (b *Bracket) Match(id int) *Match {
+	if id < 1 || id > len(b.Matches) {
+		return nil
+	}
+	return &b.Matches[id-1]
+}
+
+func (b Bracket) ReadyMatches() (mlist []Match) {
+	for _, m := range b.Matches {
+		if m.Ready() {
+			mlist = append(mlist, m)
+		}
+	}
+	return
+}
+
+// Champion returns the winner of the final or the leader of the finished round robin.
+func (b Bracket) Champion() int {
+	for _, m := range b.Matches {
+		if m.Stage == FinalStage {
+			if m.Winner > 0 {
+				return m.Winner
+			}
+			return 0
+		}
+	}
+	if b.Format == RoundRobin && b.Done() {
+		return b.Standings(1)[0].Team
+	}
+	return 0
+}
+
+func (b *Bracket) Reset() {
+	b.Teams = nil
+	b.Matches = nil
+}
+
+// Start seeds teams by rating or level and builds matches of the format.
+func (b *Bracket) Start(teams []TournamentTeam) error {
+	if len(teams) < 2 {
+		return ErrNotEnoughTeams
+	}
+	b.Reset()
+	b.Teams = append(b.Teams, teams...)
+	seedValue := func(tm TournamentTeam) int {
+		if b.Seeding == SeedByRating {
+			return tm.Rating
+		}
+		return tm.Level
+	}
+	sort.SliceStable(b.Teams, func(i, j int) bool {
+		return seedValue(b.Teams[i]) > seedValue(b.Teams[j])
+	})
+	seeds := []int{}
+	for i := range b.Teams {
+		b.Teams[i].Id = i + 1
+		seeds = append(seeds, i+1)
+	}
+	switch b.Format {
+	case RoundRobin:
+		b.addGroups(1)
+	case GroupsPlayoff:
+		waves := b.addGroups(b.Groups)
+		first := b.addElimination(b.groupCount()*2, false, waves+1)
+		b.seedRound(first, make([]int, b.groupCount()*2))
+	case SingleElimination, DoubleElimination:
+		first := b.addElimination(len(seeds), b.Format == DoubleElimination, 1)
+		b.seedRound(first, seeds)
+	}
+	b.setWaves()
+	b.resolve()
+	return nil
+}
+
+// SetScore saves the score of the ready match and moves its teams further.
+func (b *Bracket) SetScore(id int, sa int, sb int) error {
+	m := b.Match(id)
+	if m == nil {
+		return ErrMatchNotFound
+	}
+	if !m.Ready() {
+		return ErrMatchNotReady
+	}
+	if sa < 0 || sb < 0 || sa == sb {
+		return ErrInvalidScore
+	}
+	m.Score = [2]int{sa, sb}
+	m.Winner = m.Teams[0]
+	if sb > sa {
+		m.Winner = m.Teams[1]
+	}
+	b.advance(*m)
+	if m.Stage == GroupStage && b.Format == GroupsPlayoff && b.groupsDone() {
+		b.seedRound(b.firstRound(), b.qualifiers())
+	}
+	b.resolve()
+	return nil
+}
+
+// Standings sorts teams of the group by wins and score difference.
+func (b Bracket) Standings(group int) (slist []Standing) {
+	index := map[int]int{}
+	for _, m := range b.Matches {
+		if m.Stage != GroupStage || m.Group != group {
+			continue
+		}
+		for s, id := range m.Teams {
+			i, ok := index[id]
+			if !ok {
+				i = len(slist)
+				index[id] = i
+				slist = append(slist, Standing{Team: id})
+			}
+			if m.Winner == 0 {
+				continue
+			}
+			slist[i].Played++
+			slist[i].Diff += m.Score[s] - m.Score[1-s]
+			if m.Winner == id {
+				slist[i].Wins++
+			}
+		}
+	}
+	sort.SliceStable(slist, func(i, j int) bool {
+		if slist[i].Wins != slist[j].Wins {
+			return slist[i].Wins > slist[j].Wins
+		}
+		if slist[i].Diff != slist[j].Diff {
+			return slist[i].Diff > slist[j].Diff
+		}
+		return slist[i].Team < slist[j].Team
+	})
+	return
+}
+
+// Schedule puts matches to courts and time slots between start and end.
+// Matches of the next wave wait for the previous one, byes are not played.
+func (b *Bracket) Schedule(start time.Time, end time.Time, courts int) {
+	if courts < 1 {
+		courts = 1
+	}
+	mlist := []*Match{}
+	for i := range b.Matches {
+		if !b.Matches[i].Bye() {
+			mlist = append(mlist, &b.Matches[i])
+		}
+	}
+	sort.SliceStable(mlist, func(i, j int) bool {
+		return mlist[i].Wave < mlist[j].Wave
+	})
+	slots := make([]int, len(mlist))
+	slot, used := 0, 0
+	for i, m := range mlist {
+		if i > 0 && (m.Wave != mlist[i-1].Wave || used == courts) {
+			slot++
+			used = 0
+		}
+		used++
+		m.Court = used
+		slots[i] = slot
+	}
+	dur := MinMatchDuration
+	if len(mlist) > 0 {
+		if d := (end.Sub(start) / time.Duration(slot+1)).Truncate(5 * time.Minute); d > dur {
+			dur = d
+		}
+	}
+	for i, m := range mlist {
+		m.Start = start.Add(dur * time.Duration(slots[i]))
+	}
+}
+
+func (b *Bracket) add(m Match) int {
+	m.Id = len(b.Matches) + 1
+	b.Matches = append(b.Matches, m)
+	return m.Id
+}
+
+// addGroups splits seeded teams into groups by snake and returns the number of rounds.
+func (b *Bracket) addGroups(count int) (waves int) {
+	if count < 1 {
+		count = len(b.Teams) / 4
+	}
+	if count > len(b.Teams)/2 {
+		count = len(b.Teams) / 2
+	}
+	if count < 1 {
+		count = 1
+	}
+	groups := make([][]int, count)
+	for i, tm := range b.Teams {
+		g := i % count
+		if (i/count)%2 == 1 {
+			g = count - 1 - g
+		}
+		groups[g] = append(groups[g], tm.Id)
+	}
+	for g, ids := range groups {
+		for r, pairs := range roundRobin(ids) {
+			for _, p := range pairs {
+				b.add(Match{Stage: GroupStage, Group: g + 1, Round: r + 1, Wave: r + 1, Teams: p})
+			}
+			if r+1 > waves {
+				waves = r + 1
+			}
+		}
+	}
+	return
+}
+
+// roundRobin pairs teams by rounds with the circle method.
+func roundRobin(ids []int) (rounds [][][2]int) {
+	list := append([]int{}, ids...)
+	if len(list)%2 == 1 {
+		list = append(list, 0)
+	}
+	n := len(list)
+	for r := 0; r < n-1; r++ {
+		pairs := [][2]int{}
+		for i := 0; i < n/2; i++ {
+			if a, c := list[i], list[n-1-i]; a != 0 && c != 0 {
+				pairs = append(pairs, [2]int{a, c})
+			}
+		}
+		rounds = append(rounds, pairs)
+		list = append([]int{list[0], list[n-1]}, list[1:n-1]...)
+	}
+	return
+}
+
+// addElimination builds the upper bracket for count teams and, if double,
+// the lower bracket with the final. It returns ids of first round matches.
+func (b *Bracket) addElimination(count int, double bool, wave int) []int {
+	size, rounds := 2, 1
+	for size < count {
+		size *= 2
+		rounds++
+	}
+	upper := make([][]int, rounds)
+	for r := 1; r <= rounds; r++ {
+		for i := 0; i < size>>r; i++ {
+			upper[r-1] = append(upper[r-1], b.add(Match{Stage: UpperStage, Round: r, Wave: wave}))
+		}
+	}
+	for r := 0; r < rounds-1; r++ {
+		for i, id := range upper[r] {
+			b.Match(id).Next[0] = Link{Match: upper[r+1][i/2], Slot: i % 2}
+		}
+	}
+	last := upper[rounds-1][0]
+	if !double {
+		b.Match(last).Stage = FinalStage
+		return upper[0]
+	}
+	final := b.add(Match{Stage: FinalStage, Round: 1, Wave: wave})
+	b.Match(last).Next[0] = Link{Match: final, Slot: 0}
+	if rounds == 1 {
+		b.Match(last).Next[1] = Link{Match: final, Slot: 1}
+		return upper[0]
+	}
+	lr := 1
+	prev := []int{}
+	for i := 0; i < size/4; i++ {
+		id := b.add(Match{Stage: LowerStage, Round: lr, Wave: wave})
+		b.Match(upper[0][2*i]).Next[1] = Link{Match: id, Slot: 0}
+		b.Match(upper[0][2*i+1]).Next[1] = Link{Match: id, Slot: 1}
+		prev = append(prev, id)
+	}
+	for r := 1; r < rounds; r++ {
+		lr++
+		losers := upper[r]
+		drop := []int{}
+		for i, pid := range prev {
+			id := b.add(Match{Stage: LowerStage, Round: lr, Wave: wave})
+			b.Match(pid).Next[0] = Link{Match: id, Slot: 0}
+			b.Match(losers[len(losers)-1-i]).Next[1] = Link{Match: id, Slot: 1}
+			drop = append(drop, id)
+		}
+		prev = drop
+		if r == rounds-1 {
+			break
+		}
+		lr++
+		next := []int{}
+		for i := 0; i+1 < len(prev); i += 2 {
+			id := b.add(Match{Stage: LowerStage, Round: lr, Wave: wave})
+			b.Match(prev[i]).Next[0] = Link{Match: id, Slot: 0}
+			b.Match(prev[i+1]).Next[0] = Link{Match: id, Slot: 1}
+			next = append(next, id)
+		}
+		prev = next
+	}
+	b.Match(prev[0]).Next[0] = Link{Match: final, Slot: 1}
+	return upper[0]
+}
+
+// seedOrder returns seeds of the first round slots, so top seeds meet as late as possible.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		n := len(order) * 2
+		next := make([]int, 0, n)
+		for _, s := range order {
+			next = append(next, s, n+1-s)
+		}
+		order = next
+	}
+	return order
+}
+
+// seedRound puts teams to the first round, seeds without a team get a bye.
+func (b *Bracket) seedRound(first []int, teams []int) {
+	order := seedOrder(len(first) * 2)
+	for i, id := range first {
+		m := b.Match(id)
+		for s := 0; s < 2; s++ {
+			m.Teams[s] = Bye
+			if seed := order[2*i+s]; seed <= len(teams) {
+				m.Teams[s] = teams[seed-1]
+			}
+		}
+	}
+}
+
+func (b *Bracket) firstRound() (first []int) {
+	for _, m := range b.Matches {
+		if (m.Stage == UpperStage || m.Stage == FinalStage) && m.Round == 1 {
+			first = append(first, m.Id)
+		}
+	}
+	return
+}
+
+func (b Bracket) groupCount() (count int) {
+	for _, m := range b.Matches {
+		if m.Stage == GroupStage && m.Group > count {
+			count = m.Group
+		}
+	}
+	return
+}
+
+func (b Bracket) groupsDone() bool {
+	for _, m := range b.Matches {
+		if m.Stage == GroupStage && m.Winner == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// qualifiers returns group winners and then runners-up seeded so that
+// they do not meet the winner of their group in the first round.
+func (b Bracket) qualifiers() (teams []int) {
+	count := b.groupCount()
+	for g := 1; g <= count; g++ {
+		teams = append(teams, b.Standings(g)[0].Team)
+	}
+	for j := 1; j <= count; j++ {
+		g := j
+		if count%2 == 1 {
+			g = j%count + 1
+		}
+		if st := b.Standings(g); len(st) > 1 {
+			teams = append(teams, st[1].Team)
+		}
+	}
+	return
+}
+
+// setWaves delays every match until its previous matches are played.
+func (b *Bracket) setWaves() {
+	for _, m := range b.Matches {
+		for _, l := range m.Next {
+			if next := b.Match(l.Match); next != nil && next.Wave <= m.Wave {
+				next.Wave = m.Wave + 1
+			}
+		}
+	}
+}
+
+func (b *Bracket) advance(m Match) {
+	for s, l := range m.Next {
+		next := b.Match(l.Match)
+		if next == nil {
+			continue
+		}
+		if s == 0 {
+			next.Teams[l.Slot] = m.Winner
+		} else {
+			next.Teams[l.Slot] = m.Loser()
+		}
+	}
+}
+
+// resolve passes teams through matches against byes.
+func (b *Bracket) resolve() {
+	for changed := true; changed; {
+		changed = false
+		for i := range b.Matches {
+			m := &b.Matches[i]
+			if m.Winner != 0 || m.Teams[0] == 0 || m.Teams[1] == 0 || !m.Bye() {
+				continue
+			}
+			m.Winner = m.Teams[0]
+			if m.Winner == Bye {
+				m.Winner = m.Teams[1]
+			}
+			b.advance(*m)
+			changed = true
+		}
+	}
+}
+
+// TournamentTeams splits the main roster into teams of size,
+// members kept together and guests of a member join the same team if they fit.
+func (v *Volley) TournamentTeams(size int) (teams []TournamentTeam) {
+	if size < 1 {
+		size = DefaultTeamSize
+	}
+	for _, u := range groupSeats(v.Seats(), v.Together) {
+		i := 0
+		for i < len(teams) && len(teams[i].Players)+len(u) > size {
+			i++
+		}
+		if i == len(teams) {
+			teams = append(teams, TournamentTeam{Id: i + 1})
+		}
+		teams[i].Players = append(teams[i].Players, u...)
+	}
+	for i, tm := range teams {
+		names := []string{}
+		sum := 0
+		for _, tp := range tm.Players {
+			name := v.GetMember(tp.Id).String()
+			if tp.Guest > 0 {
+				name += fmt.Sprintf("+%d", tp.Guest)
+			}
+			names = append(names, name)
+			sum += int(tp.Level)
+		}
+		teams[i].Name = strings.Join(names, " / ")
+		teams[i].Level = sum / len(tm.Players)
+	}
+	return
+}
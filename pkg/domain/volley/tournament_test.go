@@ -0,0 +1,175 @@
+package volley
+
+import (
+	"testing"
+	"time"
+	"volleybot/pkg/domain/person"
+
+	"github.com/google/uuid"
+)
+
+func TestTournament(t *testing.T) {
+	newTeams := func(count int) (teams []TournamentTeam) {
+		for i := 0; i < count; i++ {
+			teams = append(teams, TournamentTeam{Id: i + 1, Level: 100 - i, Rating: 1500 + i})
+		}
+		return
+	}
+	// play finishes the bracket, teams with the better seed always win
+	play := func(t *testing.T, b *Bracket) {
+		for mlist := b.ReadyMatches(); len(mlist) > 0; mlist = b.ReadyMatches() {
+			for _, m := range mlist {
+				sa, sb := 21, 15
+				if m.Teams[1] < m.Teams[0] {
+					sa, sb = sb, sa
+				}
+				if err := b.SetScore(m.Id, sa, sb); err != nil {
+					t.FailNow()
+				}
+			}
+		}
+	}
+	count := func(b Bracket, stage Stage) (c int) {
+		for _, m := range b.Matches {
+			if m.Stage == stage {
+				c++
+			}
+		}
+		return
+	}
+
+	t.Run("Round robin", func(t *testing.T) {
+		b := Bracket{Format: RoundRobin}
+		if b.Start(newTeams(5)) != nil || len(b.Matches) != 10 {
+			t.FailNow()
+		}
+		met := map[[2]int]bool{}
+		for _, m := range b.Matches {
+			if met[m.Teams] || met[[2]int{m.Teams[1], m.Teams[0]}] {
+				t.Fail()
+			}
+			met[m.Teams] = true
+		}
+		play(t, &b)
+		st := b.Standings(1)
+		if !b.Done() || b.Champion() != 1 || st[0].Wins != 4 || st[4].Wins != 0 || st[0].Diff != 24 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Seeding by rating", func(t *testing.T) {
+		b := Bracket{Format: SingleElimination, Seeding: SeedByRating}
+		b.Start(newTeams(4))
+		if b.Team(1).Rating != 1503 || b.Matches[0].Teams != [2]int{1, 4} || b.Matches[1].Teams != [2]int{2, 3} {
+			t.Fail()
+		}
+	})
+
+	t.Run("Single elimination with byes", func(t *testing.T) {
+		b := Bracket{Format: SingleElimination}
+		b.Start(newTeams(5))
+		if len(b.Matches) != 7 || count(b, FinalStage) != 1 {
+			t.FailNow()
+		}
+		if ready := b.ReadyMatches(); len(ready) != 2 || ready[0].Teams != [2]int{4, 5} || ready[1].Teams != [2]int{2, 3} {
+			t.FailNow()
+		}
+		if b.SetScore(b.Matches[0].Id, 21, 15) != ErrMatchNotReady {
+			t.Fail()
+		}
+		if b.SetScore(b.ReadyMatches()[0].Id, 15, 15) != ErrInvalidScore {
+			t.Fail()
+		}
+		play(t, &b)
+		if b.Champion() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Double elimination", func(t *testing.T) {
+		b := Bracket{Format: DoubleElimination}
+		b.Start(newTeams(4))
+		if len(b.Matches) != 6 || count(b, LowerStage) != 2 || count(b, FinalStage) != 1 {
+			t.FailNow()
+		}
+		ready := b.ReadyMatches()
+		b.SetScore(ready[0].Id, 15, 21)
+		b.SetScore(ready[1].Id, 21, 15)
+		play(t, &b)
+		final := b.Matches[3]
+		if final.Stage != FinalStage || final.Teams != [2]int{2, 1} || b.Champion() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Double elimination of two teams", func(t *testing.T) {
+		b := Bracket{Format: DoubleElimination}
+		b.Start(newTeams(2))
+		play(t, &b)
+		if len(b.Matches) != 2 || b.Matches[1].Teams != [2]int{1, 2} || b.Champion() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Groups and playoff", func(t *testing.T) {
+		b := Bracket{Format: GroupsPlayoff}
+		b.Start(newTeams(8))
+		if count(b, GroupStage) != 12 || count(b, UpperStage) != 2 || len(b.Standings(1)) != 4 {
+			t.FailNow()
+		}
+		for _, st := range b.Standings(1) {
+			if st.Team != 1 && st.Team != 4 && st.Team != 5 && st.Team != 8 {
+				t.Fail()
+			}
+		}
+		play(t, &b)
+		semi := b.firstRound()
+		if b.Match(semi[0]).Teams != [2]int{1, 3} || b.Match(semi[1]).Teams != [2]int{2, 4} || b.Champion() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		b := Bracket{Format: RoundRobin}
+		b.Start(newTeams(4))
+		start := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+		b.Schedule(start, start.Add(2*time.Hour), 2)
+		for _, m := range b.Matches {
+			if m.Start != start.Add(time.Duration(m.Wave-1)*40*time.Minute) || m.Court < 1 || m.Court > 2 {
+				t.Fail()
+			}
+		}
+		b.Schedule(start, start.Add(time.Hour), 1)
+		if last := b.Matches[len(b.Matches)-1]; last.Court != 1 || last.Start != start.Add(50*time.Minute) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Not enough teams", func(t *testing.T) {
+		b := Bracket{}
+		if b.Start(newTeams(1)) != ErrNotEnoughTeams || b.Started() {
+			t.Fail()
+		}
+	})
+}
+
+func TestTournamentTeams(t *testing.T) {
+	newMember := func(name string, lvl PlayerLevel, count int) Member {
+		p := person.Person{Id: uuid.New(), Firstname: name}
+		return Member{Player: Player{Person: p, Level: lvl}, Count: count}
+	}
+	anna := newMember("Anna", Advanced, 1)
+	bob := newMember("Bob", Novice, 2)
+	carl := newMember("Carl", Middle, 1)
+	dina := newMember("Dina", Middle, 1)
+	v := Volley{MaxPlayers: 12, Members: []Member{anna, bob, carl, dina}}
+	v.TogglePair(anna.Id, dina.Id)
+
+	teams := v.TournamentTeams(2)
+	if len(teams) != 3 {
+		t.FailNow()
+	}
+	if teams[0].Name != "Anna / Dina" || teams[0].Level != 60 || teams[1].Name != "Bob / Bob+1" || teams[2].Name != "Carl" {
+		t.Fail()
+	}
+}
@@ -2,8 +2,11 @@ package volley
 
 import (
 	"fmt"
+	"strings"
+	"unicode/utf8"
 	"volleybot/pkg/domain/person"
 	"volleybot/pkg/domain/reserve"
+	"volleybot/pkg/telegram"
 
 	"github.com/goodsign/monday"
 )
@@ -19,10 +22,10 @@ type TelegramViewResources struct {
 func NewTelegramResourcesRu() TelegramViewResources {
 	rres := reserve.NewTelegramResourcesRu()
 	return TelegramViewResources{TelegramViewResources: rres,
-		GameLabel:       "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐",
-		TrainingLabel:   "‼️ *ТРЕНИРОВКА* ‼️",
-		TournamentLabel: "💥🔥 *ТУРНИР* 🔥💥",
-		TennisLabel:     "🎾 *ПЛЯЖНЫЙ ТЕННИС* 🎾",
+		GameLabel:       "🏐 СВОБОДНЫЕ ИГРЫ 🏐",
+		TrainingLabel:   "‼️ ТРЕНИРОВКА ‼️",
+		TournamentLabel: "💥🔥 ТУРНИР 🔥💥",
+		TennisLabel:     "🎾 ПЛЯЖНЫЙ ТЕННИС 🎾",
 	}
 }
 
@@ -40,95 +43,99 @@ func (tgv *TelegramView) String() string {
 	for _, pl := range tgv.Volley.Members {
 		plcount += pl.Count
 	}
-	return fmt.Sprintf("%s %s %s (%d/%d)", tgv.Volley.Activity.Emoji(),
-		monday.Format(tgv.Reserve.StartTime, "Mon, 02.01", tgv.Locale),
-		tgv.GetTimeText(), plcount, tgv.Volley.MaxPlayers)
+	return fmt.Sprintf("%s (%d/%d)", tgv.GetTitle(), plcount, tgv.Volley.MaxPlayers)
+}
+
+func (tgv *TelegramView) GetTitle() string {
+	return fmt.Sprintf("%s %s %s", tgv.Volley.Activity.Emoji(),
+		monday.Format(tgv.Reserve.StartTime, "Mon, 02.01", tgv.Locale), tgv.GetTimeText())
 }
 
 func (tgv *TelegramView) GetText() (text string) {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
 	if tgv.Volley.Canceled {
-		text = tgv.CancelLabel + "\n\n"
+		tb.Bold(tgv.CancelLabel)
 	} else if tgv.Volley.Activity == 10 {
-		text = tgv.TrainingLabel + "\n\n"
+		tb.Bold(tgv.TrainingLabel)
 	} else if tgv.Volley.Activity == 20 {
-		text = tgv.TournamentLabel + "\n\n"
+		tb.Bold(tgv.TournamentLabel)
 	} else if tgv.Volley.Activity == 30 {
-		text = tgv.TennisLabel + "\n\n"
+		tb.Bold(tgv.TennisLabel)
 	} else {
-		text = tgv.GameLabel + "\n\n"
+		tb.Bold(tgv.GameLabel)
 	}
-	var uname string
+	tb.Text("\n\n")
 	if tgv.Reserve.Person.TelegramId != 0 {
-		uname = fmt.Sprintf("[%s](tg://user?id=%d)", tgv.Reserve.Person.String(), tgv.Reserve.Person.TelegramId)
+		tb.Mention(tgv.Reserve.Person.String(), tgv.Reserve.Person.TelegramId)
 	} else {
-		uname = fmt.Sprintf("*%s*", tgv.Reserve.Person.String())
+		tb.Bold(tgv.Reserve.Person.String())
 	}
-	text += fmt.Sprintf("%s\n%s %s\n%s %s", uname,
+	tb.Textf("\n%s %s\n%s %s",
 		tgv.DateLabel, monday.Format(tgv.Reserve.StartTime, "Monday, 02.01.2006", tgv.Locale),
 		tgv.TimeLabel, tgv.GetTimeText())
 	if tgv.Volley.MinLevel > 0 {
-		text += fmt.Sprintf("\n💪*Уровень*: %s", PlayerLevel(tgv.Volley.MinLevel))
+		tb.Text("\n💪").Bold("Уровень").Textf(": %s", PlayerLevel(tgv.Volley.MinLevel))
 	}
 	if tgv.Volley.NetType > 0 {
-		text += fmt.Sprintf("\n*Сетка*: %s", NetType(tgv.Volley.NetType))
+		tb.Text("\n").Bold("Сетка").Textf(": %s", NetType(tgv.Volley.NetType))
 	}
 
 	if tgv.Volley.Price > 0 {
-		text += fmt.Sprintf("\n💰 %d ₽", tgv.Volley.Price)
+		tb.Textf("\n💰 %d ₽", tgv.Volley.Price)
 	}
 
 	if tgv.Volley.CourtCount > 0 {
-		text += fmt.Sprintf("\n*Корты:* %d", tgv.Volley.CourtCount)
+		tb.Text("\n").Bold("Корты:").Textf(" %d", tgv.Volley.CourtCount)
 	}
 	if tgv.Volley.MaxPlayers > 0 {
-		text += fmt.Sprintf("\n*Игроков:* %d", tgv.Volley.MaxPlayers)
+		tb.Text("\n").Bold("Игроков:").Textf(" %d", tgv.Volley.MaxPlayers)
 	}
-	text += tgv.GetMembersText()
-	return
+	return tb.String() + tgv.GetMembersText()
 }
 
 func (tgv *TelegramView) GetMembersText() (text string) {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
 	count := 1
 	over := false
+	reserve := func() {
+		if !over && count > tgv.Volley.MaxPlayers {
+			over = true
+			tb.Text("\n\n").Bold("Резерв:")
+			count = 1
+		}
+	}
 	for _, mb := range tgv.Volley.Members {
 		if mb.Count == 0 {
 			continue
 		}
 		pvw := NewPlayerTelegramView(mb.Player)
-		text += fmt.Sprintf("\n%d. %s", count, pvw.String())
+		pvw.ParseMode = tgv.ParseMode
+		tb.Textf("\n%d. ", count).Raw(pvw.String())
 		if !mb.ArriveTime.IsZero() {
-			text += fmt.Sprintf(" (%s)", mb.ArriveTime.Format("15:04"))
+			tb.Textf(" (%s)", mb.ArriveTime.Format("15:04"))
 		}
 		if mb.GetPaid() {
-			text += " 💴"
+			tb.Text(" 💴")
 		}
 		count++
-		if !over && count > tgv.Volley.MaxPlayers {
-			over = true
-			text += "\n\n*Резерв:*"
-			count = 1
-		}
+		reserve()
 		for i := 1; i < mb.Count; i++ {
-			text += fmt.Sprintf("\n%d. %s+%d", count, mb.String(), i)
+			tb.Textf("\n%d. %s+%d", count, mb.String(), i)
 			count++
-			if !over && count > tgv.Volley.MaxPlayers {
-				over = true
-				text += "\n\n*Резерв:*"
-				count = 1
-			}
+			reserve()
 		}
 	}
 	if !over && tgv.Volley.MaxPlayers-count > 3 {
-		text += fmt.Sprintf("\n%d.\n.\n.\n%d.", count, tgv.Volley.MaxPlayers)
+		tb.Textf("\n%d.\n.\n.\n%d.", count, tgv.Volley.MaxPlayers)
 	} else if !over && tgv.Volley.MaxPlayers > 0 {
 		for i := count; i <= tgv.Volley.MaxPlayers; i++ {
-			text += fmt.Sprintf("\n%d.", i)
+			tb.Textf("\n%d.", i)
 		}
 	}
 	if tgv.Reserve.Description != "" {
-		text += "\n\n" + tgv.Reserve.Description
+		tb.Text("\n\n" + tgv.Reserve.Description)
 	}
-	return
+	return tb.String()
 }
 
 func (tgv *TelegramView) GetTimeText() (text string) {
@@ -147,11 +154,12 @@ type PlayerTelegramView struct {
 }
 
 func NewPlayerTelegramView(p Player) PlayerTelegramView {
-	return PlayerTelegramView{Player: p, ParseMode: "Markdown"}
+	return PlayerTelegramView{Player: p, ParseMode: telegram.ParseModeHTML}
 }
 
 func (tgv *PlayerTelegramView) String() (text string) {
 	pv := person.NewTelegramViewRu(tgv.Person)
+	pv.ParseMode = tgv.ParseMode
 	text = PlayerLevel(tgv.Level).Emoji()
 	text += pv.String()
 	return
@@ -159,9 +167,13 @@ func (tgv *PlayerTelegramView) String() (text string) {
 
 func (tgv PlayerTelegramView) GetText() (text string) {
 	pv := person.NewTelegramViewRu(tgv.Person)
-	text = pv.GetText()
-	text += fmt.Sprintf("\n*Уровень*: %s", tgv.GetLevelText())
-	return
+	pv.ParseMode = tgv.ParseMode
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Raw(pv.GetText()).Text("\n").Bold("Уровень").Text(": " + tgv.GetLevelText())
+	if tgv.Rating > 0 {
+		tb.Text("\n").Bold("Рейтинг").Textf(": %d", tgv.Rating)
+	}
+	return tb.String()
 }
 
 func (tgv *PlayerTelegramView) GetLevelText() (text string) {
@@ -171,3 +183,261 @@ func (tgv *PlayerTelegramView) GetLevelText() (text string) {
 	text += PlayerLevel(tgv.Level).String()
 	return
 }
+
+var WeekdayNamesRu = []string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+
+type ScheduleTelegramView struct {
+	Schedule
+	ParseMode string
+}
+
+func NewScheduleTelegramViewRu(s Schedule) ScheduleTelegramView {
+	return ScheduleTelegramView{Schedule: s, ParseMode: telegram.ParseModeHTML}
+}
+
+func (tgv *ScheduleTelegramView) GetText() string {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("🔁 ").Bold("Расписание").Text(": ")
+	if tgv.Interval > 1 {
+		tb.Textf("раз в %d нед.", tgv.Interval)
+	} else {
+		tb.Text("каждую неделю")
+	}
+	days := []string{}
+	for _, d := range tgv.sortedWeekdays() {
+		days = append(days, WeekdayNamesRu[d])
+	}
+	if len(days) == 0 {
+		days = append(days, "дни не выбраны")
+	}
+	tb.Textf(" (%s)", strings.Join(days, ", "))
+	rview := NewTelegramViewRu(tgv.Template)
+	tb.Text(" " + rview.GetTimeText())
+	if tgv.Paused {
+		tb.Text("\n⏸ ").Bold("На паузе")
+	}
+	return tb.String()
+}
+
+type TeamsTelegramView struct {
+	Volley
+	ParseMode string
+}
+
+func NewTeamsTelegramViewRu(v Volley) TeamsTelegramView {
+	return TeamsTelegramView{Volley: v, ParseMode: telegram.ParseModeHTML}
+}
+
+func (tgv *TeamsTelegramView) GetText() string {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("👥 ").Bold("Команды")
+	if len(tgv.Teams) == 0 {
+		tb.Text("\nКоманды еще не составлены")
+	}
+	for i, tm := range tgv.Teams {
+		tb.Text("\n\n")
+		if len(tgv.Teams) > 2 {
+			tb.Bold(fmt.Sprintf("Корт %d, команда %d", tm.Court, i%2+1))
+		} else {
+			tb.Bold(fmt.Sprintf("Команда %d", i+1))
+		}
+		tb.Textf(" (⚖️ %d)", tm.Level())
+		for _, tp := range tm.Players {
+			mb := tgv.GetMember(tp.Id)
+			if mb.Count <= tp.Guest {
+				continue
+			}
+			if tp.Guest > 0 {
+				tb.Textf("\n• %s+%d", mb.String(), tp.Guest)
+				continue
+			}
+			pvw := NewPlayerTelegramView(mb.Player)
+			pvw.ParseMode = tgv.ParseMode
+			tb.Text("\n• ").Raw(pvw.String())
+		}
+	}
+	if len(tgv.Together) > 0 {
+		tb.Text("\n")
+	}
+	for _, p := range tgv.Together {
+		a, b := tgv.GetMember(p[0]), tgv.GetMember(p[1])
+		tb.Textf("\n🤝 %s + %s", a.String(), b.String())
+	}
+	return tb.String()
+}
+
+type ResultsTelegramView struct {
+	Volley
+	ParseMode string
+}
+
+func NewResultsTelegramViewRu(v Volley) ResultsTelegramView {
+	return ResultsTelegramView{Volley: v, ParseMode: telegram.ParseModeHTML}
+}
+
+func (tgv *ResultsTelegramView) GetText() string {
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("📝 ").Bold("Результаты")
+	if len(tgv.Results) == 0 {
+		tb.Text("\nСчет еще не записан")
+	}
+	sets := map[int]int{}
+	for _, set := range tgv.Results {
+		sets[set.Court]++
+		if len(tgv.Teams) > 2 {
+			tb.Textf("\nКорт %d, сет %d: %d:%d", set.Court, sets[set.Court], set.Score[0], set.Score[1])
+		} else {
+			tb.Textf("\nСет %d: %d:%d", sets[set.Court], set.Score[0], set.Score[1])
+		}
+	}
+	if tgv.Rated {
+		tb.Text("\n✅ Рейтинг обновлен")
+	}
+	return tb.String()
+}
+
+type BracketTelegramView struct {
+	Volley
+	ParseMode string
+}
+
+func NewBracketTelegramViewRu(v Volley) BracketTelegramView {
+	return BracketTelegramView{Volley: v, ParseMode: telegram.ParseModeHTML}
+}
+
+func (tgv *BracketTelegramView) GetText() string {
+	br := tgv.Bracket
+	tb := telegram.NewTextBuilder(tgv.ParseMode)
+	tb.Text("🏆 ").Bold("Турнир")
+	tb.Textf("\n%s, посев %s", br.Format, br.Seeding)
+	if !br.Started() {
+		teams := tgv.TournamentTeams(br.Size())
+		tb.Textf(", команды по %d", br.Size())
+		if len(teams) == 0 {
+			tb.Text("\nКоманд пока нет")
+		}
+		for _, tm := range teams {
+			tb.Textf("\n%d. %s", tm.Id, tm.Name)
+		}
+		return tb.String()
+	}
+	tb.Text("\n").Pre(tgv.GetBracket())
+	if id := br.Champion(); id > 0 {
+		tb.Text("\n🥇 ").Bold(br.Team(id).Name)
+	}
+	return tb.String()
+}
+
+// GetBracket draws group tables and matches by rounds with monospace columns.
+// Unknown teams are shown as W#N or L#N for the winner or loser of match N.
+func (tgv *BracketTelegramView) GetBracket() string {
+	br := tgv.Bracket
+	width := 0
+	for _, tm := range br.Teams {
+		if n := utf8.RuneCountInString(tm.Name); n > width {
+			width = n
+		}
+	}
+	if width < 5 {
+		width = 5
+	} else if width > 24 {
+		width = 24
+	}
+	pad := func(s string) string {
+		if r := []rune(s); len(r) > width {
+			return string(r[:width-1]) + "…"
+		}
+		return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+	}
+	sources := map[Link]Link{}
+	final := 0
+	for _, m := range br.Matches {
+		for s, l := range m.Next {
+			if l.Match != 0 {
+				sources[l] = Link{Match: m.Id, Slot: s}
+			}
+		}
+		if m.Stage == FinalStage {
+			final = m.Round
+		}
+	}
+	var label func(m Match, slot int) string
+	label = func(m Match, slot int) string {
+		if id := m.Teams[slot]; id > 0 {
+			return br.Team(id).Name
+		}
+		src, ok := sources[Link{Match: m.Id, Slot: slot}]
+		if !ok {
+			return "?"
+		}
+		// the winner of a bye match is the team from its other slot
+		if sm := br.Matches[src.Match-1]; sm.Bye() && src.Slot == 0 {
+			if sm.Teams[0] == Bye {
+				return label(sm, 1)
+			}
+			return label(sm, 0)
+		}
+		return fmt.Sprintf("%s#%d", []string{"W", "L"}[src.Slot], src.Match)
+	}
+	title := func(m Match) string {
+		switch {
+		case m.Stage == GroupStage:
+			return fmt.Sprintf("Группа %d, тур %d", m.Group, m.Round)
+		case m.Stage == LowerStage:
+			return fmt.Sprintf("Нижняя сетка, раунд %d", m.Round)
+		case m.Stage == FinalStage:
+			return "Финал"
+		case br.Format == DoubleElimination:
+			return fmt.Sprintf("Верхняя сетка, раунд %d", m.Round)
+		case final-m.Round == 1:
+			return "Полуфинал"
+		}
+		return fmt.Sprintf("1/%d финала", 1<<(final-m.Round))
+	}
+
+	var sb strings.Builder
+	groups := 0
+	for _, m := range br.Matches {
+		if m.Stage == GroupStage && m.Group > groups {
+			groups = m.Group
+		}
+	}
+	for g := 1; g <= groups; g++ {
+		fmt.Fprintf(&sb, "Группа %d\n", g)
+		for i, st := range br.Standings(g) {
+			fmt.Fprintf(&sb, "%d. %s %d-%d %+d\n", i+1, pad(br.Team(st.Team).Name), st.Wins, st.Played-st.Wins, st.Diff)
+		}
+		sb.WriteString("\n")
+	}
+	last := ""
+	for _, stage := range []Stage{GroupStage, UpperStage, LowerStage, FinalStage} {
+		for _, m := range br.Matches {
+			if m.Stage != stage || m.Bye() {
+				continue
+			}
+			if t := title(m); t != last {
+				if last != "" {
+					sb.WriteString("\n")
+				}
+				sb.WriteString(t + "\n")
+				last = t
+			}
+			sb.WriteString(fmt.Sprintf("#%d", m.Id))
+			if m.Court > 0 {
+				fmt.Fprintf(&sb, " К%d %s", m.Court, m.Start.Format("15:04"))
+			}
+			sb.WriteString("\n")
+			for s := 0; s < 2; s++ {
+				line := "  " + pad(label(m, s))
+				if m.Winner != 0 {
+					line += fmt.Sprintf(" %2d", m.Score[s])
+				}
+				if m.Winner != 0 && m.Winner == m.Teams[s] {
+					line += " *"
+				}
+				sb.WriteString(strings.TrimRight(line, " ") + "\n")
+			}
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -1,6 +1,7 @@
 package volley
 
 import (
+	"strings"
 	"testing"
 	"time"
 	"volleybot/pkg/domain/person"
@@ -31,8 +32,8 @@ func TestTelegramView(t *testing.T) {
 				MinLevel:   int(Middle),
 				MaxPlayers: 6,
 			},
-			text: "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"💪*Уровень*: Средний\n💰 600 ₽\n*Игроков:* 6\n1.\n.\n.\n6.",
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"💪<b>Уровень</b>: Средний\n💰 600 ₽\n<b>Игроков:</b> 6\n1.\n.\n.\n6.",
 			str: "🏐 Сб, 04.12 15:00-17:00 (0/6)",
 		},
 		"With description": {
@@ -45,8 +46,8 @@ func TestTelegramView(t *testing.T) {
 				MinLevel:   int(Middle),
 				MaxPlayers: 6,
 			},
-			text: "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"💪*Уровень*: Средний\n💰 600 ₽\n*Игроков:* 6\n1.\n.\n.\n6." +
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"💪<b>Уровень</b>: Средний\n💰 600 ₽\n<b>Игроков:</b> 6\n1.\n.\n.\n6." +
 				"\n\nSome description.",
 			str: "🏐 Сб, 04.12 15:00-17:00 (0/6)",
 		},
@@ -57,8 +58,8 @@ func TestTelegramView(t *testing.T) {
 				EndTime:   time.Date(2021, 12, 04, 17, 0, 0, 0, time.UTC)},
 				MaxPlayers: 4,
 			},
-			text: "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 4\n1.\n2.\n3.\n4.",
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 4\n1.\n2.\n3.\n4.",
 			str: "🏐 Сб, 04.12 15:00-17:00 (0/4)",
 		},
 		"3 players": {
@@ -71,9 +72,9 @@ func TestTelegramView(t *testing.T) {
 					{Player: Player{Person: pl1}, Count: 2},
 					{Player: Player{Person: pl2}, Count: 3},
 					{Player: Player{Person: pl3}, Count: 1}}},
-			text: "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 4\n1. 👤 Elly\n2. Elly+1\n3. 👤 Steve\n4. Steve+1" +
-				"\n\n*Резерв:*\n1. Steve+2\n2. [👤 Tina](tg://user?id=123456)",
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 4\n1. 👤 Elly\n2. Elly+1\n3. 👤 Steve\n4. Steve+1" +
+				"\n\n<b>Резерв:</b>\n1. Steve+2\n2. <a href=\"tg://user?id=123456\">👤 Tina</a>",
 			str: "🏐 Сб, 04.12 15:00-17:00 (6/4)",
 		},
 		"Leaved player": {
@@ -87,8 +88,8 @@ func TestTelegramView(t *testing.T) {
 					{Player: Player{Person: pl2}, Count: 0},
 					{Player: Player{Person: pl3}, Count: 1},
 				}},
-			text: "🏐 *СВОБОДНЫЕ ИГРЫ* 🏐\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 4\n1. 👤 Elly\n2. Elly+1\n3. [👤 Tina](tg://user?id=123456)\n4.",
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 4\n1. 👤 Elly\n2. Elly+1\n3. <a href=\"tg://user?id=123456\">👤 Tina</a>\n4.",
 			str: "🏐 Сб, 04.12 15:00-17:00 (3/4)",
 		},
 		"Canceled": {
@@ -100,8 +101,8 @@ func TestTelegramView(t *testing.T) {
 				MaxPlayers: 12,
 				Members:    []Member{{Player: Player{Person: pl1}, Count: 2}},
 			},
-			text: "🔥 *ОТМЕНА* 🔥\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 12\n1. 👤 Elly\n2. Elly+1\n3.\n.\n.\n12.",
+			text: "<b>🔥 ОТМЕНА 🔥</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 12\n1. 👤 Elly\n2. Elly+1\n3.\n.\n.\n12.",
 			str: "🏐 Сб, 04.12 15:00-17:00 (2/12)",
 		},
 		"Training": {
@@ -113,10 +114,26 @@ func TestTelegramView(t *testing.T) {
 				Activity:   10,
 				Members:    []Member{{Player: Player{Person: pl1}, Count: 2}},
 			},
-			text: "‼️ *ТРЕНИРОВКА* ‼️\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 12\n1. 👤 Elly\n2. Elly+1\n3.\n.\n.\n12.",
+			text: "<b>‼️ ТРЕНИРОВКА ‼️</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 12\n1. 👤 Elly\n2. Elly+1\n3.\n.\n.\n12.",
 			str: "‼️ Сб, 04.12 15:00-17:00 (2/12)",
 		},
+		"Hostile names": {
+			v: Volley{Reserve: reserve.Reserve{
+				Person:      person.Person{Firstname: "Ivan_Petrov", TelegramId: 42},
+				StartTime:   time.Date(2021, 12, 04, 15, 0, 0, 0, time.UTC),
+				EndTime:     time.Date(2021, 12, 04, 17, 0, 0, 0, time.UTC),
+				Description: "Bring *balls* & <water>"},
+				MaxPlayers: 2,
+				Members: []Member{
+					{Player: Player{Person: person.Person{Firstname: "<i>Max</i>_*"}}, Count: 2},
+					{Player: Player{Person: person.Person{Firstname: "[Bob](x)", TelegramId: 7}}, Count: 1}}},
+			text: "<b>🏐 СВОБОДНЫЕ ИГРЫ 🏐</b>\n\n<a href=\"tg://user?id=42\">Ivan_Petrov</a>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 2\n1. 👤 &lt;i&gt;Max&lt;/i&gt;_*\n2. &lt;i&gt;Max&lt;/i&gt;_*+1" +
+				"\n\n<b>Резерв:</b>\n1. <a href=\"tg://user?id=7\">👤 [Bob](x)</a>" +
+				"\n\nBring *balls* &amp; &lt;water&gt;",
+			str: "🏐 Сб, 04.12 15:00-17:00 (3/2)",
+		},
 		"Tennis": {
 			v: Volley{Reserve: reserve.Reserve{
 				Person:    pl1,
@@ -126,8 +143,8 @@ func TestTelegramView(t *testing.T) {
 				Activity:   30,
 				Members:    []Member{{Player: Player{Person: pl1}, Count: 2, ArriveTime: time.Date(0, 0, 0, 15, 15, 0, 0, time.UTC)}},
 			},
-			text: "🎾 *ПЛЯЖНЫЙ ТЕННИС* 🎾\n\n*Elly*\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
-				"*Игроков:* 12\n1. 👤 Elly (15:15)\n2. Elly+1\n3.\n.\n.\n12.",
+			text: "<b>🎾 ПЛЯЖНЫЙ ТЕННИС 🎾</b>\n\n<b>Elly</b>\n📆 Суббота, 04.12.2021\n⏰ 15:00-17:00\n" +
+				"<b>Игроков:</b> 12\n1. 👤 Elly (15:15)\n2. Elly+1\n3.\n.\n.\n12.",
 			str: "🎾 Сб, 04.12 15:00-17:00 (2/12)",
 		},
 	}
@@ -147,3 +164,80 @@ func TestTelegramView(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamsTelegramView(t *testing.T) {
+	elly := Member{Player: Player{Person: person.Person{Id: uuid.New(), Firstname: "Elly"}, Level: Middle}, Count: 2}
+	steve := Member{Player: Player{Person: person.Person{Id: uuid.New(), Firstname: "<Steve>"}}, Count: 1}
+	v := Volley{MaxPlayers: 6, CourtCount: 1, Members: []Member{elly, steve}}
+
+	tgv := NewTeamsTelegramViewRu(v)
+	if tgv.GetText() != "👥 <b>Команды</b>\nКоманды еще не составлены" {
+		t.Fail()
+	}
+	v.Teams = []Team{
+		{Court: 1, Players: []TeamPlayer{{Id: elly.Id, Level: Middle}}},
+		{Court: 1, Players: []TeamPlayer{{Id: steve.Id, Level: Middle}, {Id: elly.Id, Guest: 1, Level: Middle}}},
+	}
+	v.TogglePair(elly.Id, steve.Id)
+	tgv = NewTeamsTelegramViewRu(v)
+	text := "👥 <b>Команды</b>\n\n<b>Команда 1</b> (⚖️ 50)\n• 👍👤 Elly" +
+		"\n\n<b>Команда 2</b> (⚖️ 100)\n• 👤 &lt;Steve&gt;\n• Elly+1\n\n🤝 Elly + &lt;Steve&gt;"
+	if tgv.GetText() != text {
+		t.Error(tgv.GetText())
+	}
+}
+
+func TestPlayerTelegramView(t *testing.T) {
+	pl := Player{Person: person.Person{Firstname: "Elly"}, Level: Middle}
+	pview := NewPlayerTelegramView(pl)
+	if text := pview.GetText(); !strings.HasSuffix(text, "\n<b>Уровень</b>: 👍 Средний") {
+		t.Error(text)
+	}
+	pl.Rating = 1512
+	pview = NewPlayerTelegramView(pl)
+	if text := pview.GetText(); !strings.HasSuffix(text, "\n<b>Уровень</b>: 👍 Средний\n<b>Рейтинг</b>: 1512") {
+		t.Error(text)
+	}
+}
+
+func TestResultsTelegramView(t *testing.T) {
+	v := Volley{Teams: []Team{{Court: 1}, {Court: 1}}}
+	rview := NewResultsTelegramViewRu(v)
+	if rview.GetText() != "📝 <b>Результаты</b>\nСчет еще не записан" {
+		t.Fail()
+	}
+	v.Results = []SetResult{{Court: 1, Score: [2]int{21, 15}}, {Court: 1, Score: [2]int{19, 21}}}
+	v.Rated = true
+	rview = NewResultsTelegramViewRu(v)
+	if rview.GetText() != "📝 <b>Результаты</b>\nСет 1: 21:15\nСет 2: 19:21\n✅ Рейтинг обновлен" {
+		t.Error(rview.GetText())
+	}
+	v.Teams = append(v.Teams, Team{Court: 2}, Team{Court: 2})
+	v.Results = append(v.Results, SetResult{Court: 2, Score: [2]int{21, 10}})
+	v.Rated = false
+	rview = NewResultsTelegramViewRu(v)
+	if rview.GetText() != "📝 <b>Результаты</b>\nКорт 1, сет 1: 21:15\nКорт 1, сет 2: 19:21\nКорт 2, сет 1: 21:10" {
+		t.Error(rview.GetText())
+	}
+}
+
+func TestBracketTelegramView(t *testing.T) {
+	anna := Member{Player: Player{Person: person.Person{Id: uuid.New(), Firstname: "Anna"}}, Count: 1}
+	bob := Member{Player: Player{Person: person.Person{Id: uuid.New(), Firstname: "Bob"}}, Count: 1}
+	v := Volley{MaxPlayers: 6, Members: []Member{anna, bob}}
+	bview := NewBracketTelegramViewRu(v)
+	if bview.GetText() != "🏆 <b>Турнир</b>\nКруговая система, посев по уровню, команды по 2\n1. Anna / Bob" {
+		t.Fail()
+	}
+
+	v.Bracket.Format = SingleElimination
+	v.Bracket.Start([]TournamentTeam{{Name: "Team A", Level: 3}, {Name: "Team B", Level: 2}, {Name: "Team C", Level: 1}})
+	v.Bracket.SetScore(2, 21, 18)
+	v.Bracket.SetScore(3, 15, 21)
+	bview = NewBracketTelegramViewRu(v)
+	want := "🏆 <b>Турнир</b>\nОлимпийская система, посев по уровню\n<pre>Полуфинал\n#2\n  Team B 21 *\n  Team C 18\n\n" +
+		"Финал\n#3\n  Team A 15\n  Team B 21 *</pre>\n🥇 <b>Team B</b>"
+	if bview.GetText() != want {
+		t.Fail()
+	}
+}
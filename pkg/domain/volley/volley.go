@@ -45,17 +45,31 @@ func NewVolley(p person.Person, start time.Time, end time.Time) Volley {
 
 type Volley struct {
 	reserve.Reserve
-	Activity   Activity `json:"activity"`
-	MinLevel   int      `json:"min_level"`
-	CourtCount int      `json:"court_count"`
-	MaxPlayers int      `json:"max_players"`
-	NetType    NetType  `json:"net_type"`
-	Members    []Member `json:"members"`
+	Activity   Activity    `json:"activity"`
+	MinLevel   int         `json:"min_level"`
+	CourtCount int         `json:"court_count"`
+	MaxPlayers int         `json:"max_players"`
+	NetType    NetType     `json:"net_type"`
+	Members    []Member    `json:"members"`
+	PollId     string      `json:"poll_id"`
+	ScheduleId uuid.UUID   `json:"schedule_id"`
+	Teams      []Team      `json:"teams"`
+	Together   []Pair      `json:"together"`
+	Results    []SetResult `json:"results"`
+	Rated      bool        `json:"rated"`
+	Bracket    Bracket     `json:"bracket"`
 }
 
 func (res *Volley) Copy() (result Volley) {
 	result = *res
 	result.Id = uuid.New()
+	result.PollId = ""
+	result.ScheduleId = uuid.Nil
+	result.Teams = nil
+	result.Together = nil
+	result.Results = nil
+	result.Rated = false
+	result.Bracket.Reset()
 	return
 }
 
@@ -86,6 +100,10 @@ func (v *Volley) GetMember(pid uuid.UUID) (mb Member) {
 	return
 }
 
+func (v *Volley) GetMemberSum(pid uuid.UUID) int {
+	return v.Price * v.GetMember(pid).Count
+}
+
 func (v *Volley) GetMemberByTelegramId(tid int) (pl Member) {
 	for _, pl := range v.Members {
 		if pl.TelegramId == tid {
@@ -116,12 +134,74 @@ func (v *Volley) PlayerInReserve(pid uuid.UUID) bool {
 	return count >= v.MaxPlayers
 }
 
-func (v *Volley) JoinPlayer(mb Member) {
+// MainMembers returns ids of members which take at least one seat in the main roster.
+func (v *Volley) MainMembers() map[uuid.UUID]bool {
+	main := map[uuid.UUID]bool{}
+	count := 0
+	for _, mb := range v.Members {
+		if mb.Count == 0 {
+			continue
+		}
+		if count < v.MaxPlayers {
+			main[mb.Id] = true
+		}
+		count += mb.Count
+	}
+	return main
+}
+
+// JoinPlayer adds or updates the member and returns members moved up
+// from the reserve to the main roster by this change.
+func (v *Volley) JoinPlayer(mb Member) (promoted []Member) {
+	before := v.MainMembers()
+	if mb.Count == 0 {
+		mb.PromotedAt = time.Time{}
+	}
+	found := false
 	for i, m := range v.Members {
 		if m.Id == mb.Id {
 			v.Members[i] = mb
-			return
+			found = true
+			break
+		}
+	}
+	if !found {
+		v.Members = append(v.Members, mb)
+	}
+	after := v.MainMembers()
+	for i, m := range v.Members {
+		if m.Id == mb.Id || before[m.Id] || !after[m.Id] {
+			continue
+		}
+		v.Members[i].PromotedAt = time.Now()
+		promoted = append(promoted, v.Members[i])
+	}
+	return
+}
+
+func (v *Volley) ConfirmPlayer(pid uuid.UUID) bool {
+	for i, mb := range v.Members {
+		if mb.Id == pid && mb.Pending() {
+			v.Members[i].PromotedAt = time.Time{}
+			return true
+		}
+	}
+	return false
+}
+
+// ReleaseExpired frees seats of members promoted before the deadline
+// who haven't confirmed them, moving up the next ones from the reserve.
+func (v *Volley) ReleaseExpired(deadline time.Time) (released []Member, promoted []Member) {
+	expired := []Member{}
+	for _, mb := range v.Members {
+		if mb.Pending() && mb.PromotedAt.Before(deadline) {
+			expired = append(expired, mb)
 		}
 	}
-	v.Members = append(v.Members, mb)
+	for _, mb := range expired {
+		mb.Count = 0
+		released = append(released, mb)
+		promoted = append(promoted, v.JoinPlayer(mb)...)
+	}
+	return
 }
@@ -0,0 +1,104 @@
+package volley
+
+import (
+	"testing"
+	"time"
+	"volleybot/pkg/domain/person"
+
+	"github.com/google/uuid"
+)
+
+func TestJoinPlayerPromotion(t *testing.T) {
+	newMember := func(name string, count int) Member {
+		return Member{Player: Player{Person: person.Person{Id: uuid.New(), Firstname: name}}, Count: count}
+	}
+	anna, bob, carl, dina := newMember("Anna", 1), newMember("Bob", 1), newMember("Carl", 1), newMember("Dina", 2)
+	newVolley := func() Volley {
+		v := Volley{MaxPlayers: 2}
+		v.Members = []Member{anna, bob, carl, dina}
+		return v
+	}
+
+	tests := map[string]struct {
+		change   Member
+		promoted []uuid.UUID
+	}{
+		"Main player leaves":    {change: Member{Player: anna.Player, Count: 0}, promoted: []uuid.UUID{carl.Id}},
+		"Reserve player leaves": {change: Member{Player: carl.Player, Count: 0}},
+		"Main player adds seat": {change: Member{Player: anna.Player, Count: 2}},
+		"New player joins":      {change: newMember("Eve", 1)},
+		"Two seats freed":       {change: Member{Player: bob.Player, Count: 0}, promoted: []uuid.UUID{carl.Id}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := newVolley()
+			promoted := v.JoinPlayer(test.change)
+			if len(promoted) != len(test.promoted) {
+				t.FailNow()
+			}
+			for i, mb := range promoted {
+				if mb.Id != test.promoted[i] || !v.GetMember(mb.Id).Pending() {
+					t.Fail()
+				}
+			}
+		})
+	}
+
+	t.Run("Confirm", func(t *testing.T) {
+		v := newVolley()
+		v.JoinPlayer(Member{Player: anna.Player, Count: 0})
+		if !v.ConfirmPlayer(carl.Id) || v.GetMember(carl.Id).Pending() || v.ConfirmPlayer(carl.Id) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Release expired", func(t *testing.T) {
+		v := newVolley()
+		v.JoinPlayer(Member{Player: anna.Player, Count: 0})
+		if released, _ := v.ReleaseExpired(time.Now().Add(-time.Hour)); len(released) != 0 {
+			t.FailNow()
+		}
+		released, promoted := v.ReleaseExpired(time.Now().Add(time.Hour))
+		if len(released) != 1 || released[0].Id != carl.Id || v.GetMember(carl.Id).Count != 0 {
+			t.FailNow()
+		}
+		if len(promoted) != 1 || promoted[0].Id != dina.Id || !v.GetMember(dina.Id).Pending() {
+			t.Fail()
+		}
+	})
+}
+
+func TestCopy(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	v := Volley{PollId: "poll", ScheduleId: uuid.New(), Rated: true}
+	v.Id, v.MaxPlayers = uuid.New(), 12
+	v.Teams = []Team{{}, {}}
+	v.Together = []Pair{{a, b}}
+	v.Results = []SetResult{{}}
+	c := v.Copy()
+
+	t.Run("New game", func(t *testing.T) {
+		if c.Id == v.Id || c.PollId != "" || c.ScheduleId != uuid.Nil || c.MaxPlayers != v.MaxPlayers {
+			t.Fail()
+		}
+	})
+
+	t.Run("Teams reset", func(t *testing.T) {
+		if c.Teams != nil || c.Together != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Results reset", func(t *testing.T) {
+		if c.Results != nil || c.Rated {
+			t.Fail()
+		}
+	})
+
+	t.Run("Original kept", func(t *testing.T) {
+		if len(v.Teams) != 2 || len(v.Together) != 1 || len(v.Results) != 1 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+	"volleybot/pkg/telegram"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type CallbackDataPgRepository struct {
+	dbpool    *pgxpool.Pool
+	TableName string
+	TTL       time.Duration
+}
+
+func NewCallbackDataRepository(dbpool *pgxpool.Pool) (pgrep CallbackDataPgRepository, err error) {
+	pgrep.TableName = "tg_callback_data"
+	pgrep.TTL = telegram.DefaultCallbackDataTTL
+	pgrep.dbpool = dbpool
+
+	return
+}
+
+func (rep *CallbackDataPgRepository) UpdateDB(ctx context.Context) (err error) {
+	sql := "CREATE TABLE IF NOT EXISTS %[1]s " +
+		"(token varchar(32) PRIMARY KEY, data varchar(4096) NOT NULL, expires TIMESTAMP NOT NULL);" +
+		"CREATE UNIQUE INDEX IF NOT EXISTS %[1]s_data_md5 ON %[1]s (md5(data));"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	_, err = rep.dbpool.Exec(ctx, sql)
+
+	return
+}
+
+func (rep *CallbackDataPgRepository) Encode(ctx context.Context, data string) (token string, err error) {
+	if token, err = telegram.NewCallbackToken(); err != nil {
+		return
+	}
+	sql := "INSERT INTO %s (token, data, expires) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (md5(data)) DO UPDATE SET expires = EXCLUDED.expires RETURNING token"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	err = rep.dbpool.QueryRow(ctx, sql, token, data, time.Now().Add(rep.TTL)).Scan(&token)
+
+	return
+}
+
+func (rep *CallbackDataPgRepository) Decode(ctx context.Context, token string) (data string, err error) {
+	sql := "SELECT data FROM %s WHERE token = $1 AND expires > $2"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	err = rep.dbpool.QueryRow(ctx, sql, token, time.Now()).Scan(&data)
+	if err == pgx.ErrNoRows {
+		err = telegram.ErrCallbackDataNotFound
+	}
+	return
+}
+
+func (rep *CallbackDataPgRepository) DeleteExpired(ctx context.Context) (err error) {
+	sql := "DELETE FROM %s WHERE expires <= $1"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	_, err = rep.dbpool.Exec(ctx, sql, time.Now())
+
+	return
+}
+
+// RunCleaner deletes expired callback data at start and then every period until ctx is done.
+func (rep *CallbackDataPgRepository) RunCleaner(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		if err := rep.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+			log.Println(err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallbackDataRepositoryEncode(t *testing.T) {
+	ctx := context.Background()
+	dbpool := newTestPool(t)
+	rep, _ := NewCallbackDataRepository(dbpool)
+	rep.TableName = fmt.Sprintf("tg_callback_data_test_%d", time.Now().UnixNano())
+	if err := rep.UpdateDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dbpool.Exec(context.Background(), "DROP TABLE IF EXISTS "+rep.TableName)
+	})
+
+	data := strings.Repeat("res_show_", 450)
+	token, err := rep.Encode(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Large data encoded once", func(t *testing.T) {
+		again, err := rep.Encode(ctx, data)
+		if err != nil || again != token {
+			t.Fail()
+		}
+		if decoded, err := rep.Decode(ctx, token); err != nil || decoded != data {
+			t.Fail()
+		}
+	})
+
+	t.Run("Expired data deleted", func(t *testing.T) {
+		rep.TTL = -time.Minute
+		expired, _ := rep.Encode(ctx, "res_expired")
+		if err := rep.DeleteExpired(ctx); err != nil {
+			t.FailNow()
+		}
+		if _, err := rep.Decode(ctx, expired); err == nil {
+			t.Fail()
+		}
+		if _, err := rep.Decode(ctx, token); err != nil {
+			t.Fail()
+		}
+	})
+}
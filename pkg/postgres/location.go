@@ -25,11 +25,11 @@ func NewLocationRepository(dbpool *pgxpool.Pool) (pgrep LocationPgRepository, er
 	return
 }
 
-func (rep *LocationPgRepository) Get(id uuid.UUID) (loc location.Location, err error) {
+func (rep *LocationPgRepository) Get(ctx context.Context, id uuid.UUID) (loc location.Location, err error) {
 	sql := "SELECT location_id, location_name, location_descr, location_chat_id, location_court_count " +
 		"FROM %s " +
 		"WHERE location_id = $1"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.TableName), id)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), id)
 
 	err = row.Scan(&loc.Id, &loc.Name, &loc.Description, &loc.ChatId, &loc.CourtCount)
 
@@ -39,11 +39,11 @@ func (rep *LocationPgRepository) Get(id uuid.UUID) (loc location.Location, err e
 	return
 }
 
-func (rep *LocationPgRepository) GetByName(name string) (loc location.Location, err error) {
+func (rep *LocationPgRepository) GetByName(ctx context.Context, name string) (loc location.Location, err error) {
 	sql := "SELECT location_id, location_name, location_descr, location_chat_id, location_court_count " +
 		"FROM %s " +
 		"WHERE location_name = $1"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.TableName), name)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), name)
 
 	err = row.Scan(&loc.Id, &loc.Name, &loc.Description, &loc.ChatId, &loc.CourtCount)
 
@@ -53,14 +53,14 @@ func (rep *LocationPgRepository) GetByName(name string) (loc location.Location,
 	return
 }
 
-func (rep *LocationPgRepository) Add(l location.Location) (loc location.Location, err error) {
+func (rep *LocationPgRepository) Add(ctx context.Context, l location.Location) (loc location.Location, err error) {
 	sql := "INSERT INTO %s " +
 		"(location_id, location_name, location_descr, location_chat_id, location_court_count) " +
 		"VALUES ($1, $2, $3, $4, $5) " +
 		"RETURNING location_id"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	row := rep.dbpool.QueryRow(context.Background(), sql, l.Id, l.Name, l.Description, l.ChatId, l.CourtCount)
+	row := rep.dbpool.QueryRow(ctx, sql, l.Id, l.Name, l.Description, l.ChatId, l.CourtCount)
 
 	var LocationId uuid.UUID
 	err = row.Scan(&LocationId)
@@ -73,22 +73,22 @@ func (rep *LocationPgRepository) Add(l location.Location) (loc location.Location
 	return
 }
 
-func (rep *LocationPgRepository) Update(loc location.Location) (err error) {
+func (rep *LocationPgRepository) Update(ctx context.Context, loc location.Location) (err error) {
 	sql := "UPDATE %s SET " +
-		"location_name = $1, location_descr = $2, location_chat_id = $3, location_court_count = $4" +
+		"location_name = $1, location_descr = $2, location_chat_id = $3, location_court_count = $4 " +
 		"WHERE location_id = $5"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	_, err = rep.dbpool.Exec(context.Background(), sql, loc.Name, loc.Description, loc.ChatId, loc.CourtCount, loc.Id)
+	_, err = rep.dbpool.Exec(ctx, sql, loc.Name, loc.Description, loc.ChatId, loc.CourtCount, loc.Id)
 
 	return
 }
 
-func (rep *LocationPgRepository) UpdateDB() (err error) {
+func (rep *LocationPgRepository) UpdateDB(ctx context.Context) (err error) {
 	sql := "CREATE TABLE IF NOT EXISTS %s (" +
 		"location_id UUID PRIMARY KEY, location_name VARCHAR(20), location_descr VARCHAR(100), " +
 		"location_chat_id BIGINT, location_court_count INT) "
-	rows, err := rep.dbpool.Query(context.Background(), fmt.Sprintf(sql, rep.TableName))
+	rows, err := rep.dbpool.Query(ctx, fmt.Sprintf(sql, rep.TableName))
 
 	if err != nil {
 		return err
@@ -114,10 +114,10 @@ func NewLocationConfigRepository(dbpool *pgxpool.Pool) (pgrep LocationConfigPgRe
 	return
 }
 
-func (rep *LocationConfigPgRepository) UpdateDB() (err error) {
+func (rep *LocationConfigPgRepository) UpdateDB(ctx context.Context) (err error) {
 	sql := "CREATE TABLE IF NOT EXISTS %s (" +
 		"location_id UUID, service_name VARCHAR(20), location_config JSONB)"
-	rows, err := rep.dbpool.Query(context.Background(), fmt.Sprintf(sql, rep.TableName))
+	rows, err := rep.dbpool.Query(ctx, fmt.Sprintf(sql, rep.TableName))
 
 	if err != nil {
 		return err
@@ -127,20 +127,20 @@ func (rep *LocationConfigPgRepository) UpdateDB() (err error) {
 	return err
 }
 
-func (rep *LocationConfigPgRepository) Add(loc location.Location, service string, config interface{}) error {
+func (rep *LocationConfigPgRepository) Add(ctx context.Context, loc location.Location, service string, config interface{}) error {
 	sql := "INSERT INTO %s (location_id, service_name, location_config) VALUES ($1, $2, $3)"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	row := rep.dbpool.QueryRow(context.Background(), sql, loc.Id, service, config)
+	row := rep.dbpool.QueryRow(ctx, sql, loc.Id, service, config)
 
 	return row.Scan()
 }
 
-func (rep *LocationConfigPgRepository) Get(loc location.Location, service string, config interface{}) (err error) {
+func (rep *LocationConfigPgRepository) Get(ctx context.Context, loc location.Location, service string, config interface{}) (err error) {
 	sql := "SELECT location_config " +
 		"FROM %s " +
 		"WHERE location_id = $1 AND service_name = $2"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.TableName), loc.Id, service)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), loc.Id, service)
 
 	err = row.Scan(config)
 
@@ -150,13 +150,13 @@ func (rep *LocationConfigPgRepository) Get(loc location.Location, service string
 	return
 }
 
-func (rep *LocationConfigPgRepository) Update(loc location.Location, service string, config interface{}) error {
+func (rep *LocationConfigPgRepository) Update(ctx context.Context, loc location.Location, service string, config interface{}) error {
 	sql := "UPDATE %s SET " +
 		"location_config = $1 " +
 		"WHERE location_id = $2 AND service_name = $3"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	_, err := rep.dbpool.Exec(context.Background(), sql, config, loc.Id, service)
+	_, err := rep.dbpool.Exec(ctx, sql, config, loc.Id, service)
 
 	return err
 }
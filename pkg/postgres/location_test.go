@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"volleybot/pkg/domain/location"
+
+	"github.com/google/uuid"
+)
+
+func TestLocationRepositoryUpdate(t *testing.T) {
+	ctx := context.Background()
+	dbpool := newTestPool(t)
+	rep, _ := NewLocationRepository(dbpool)
+	rep.TableName = fmt.Sprintf("locations_test_%d", time.Now().UnixNano())
+	if err := rep.UpdateDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dbpool.Exec(context.Background(), "DROP TABLE IF EXISTS "+rep.TableName)
+	})
+
+	loc, err := rep.Add(ctx, location.Location{Id: uuid.New(), Name: "default", ChatId: -100, CourtCount: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc.ChatId = 0
+	err = rep.Update(ctx, loc)
+
+	t.Run("No error", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Location chat unbound", func(t *testing.T) {
+		got, err := rep.Get(ctx, loc.Id)
+		if err != nil || got.ChatId != 0 || got.CourtCount != 2 || got.Name != "default" {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type OffsetPgRepository struct {
+	dbpool    *pgxpool.Pool
+	TableName string
+	Name      string
+}
+
+func NewOffsetRepository(dbpool *pgxpool.Pool) (pgrep OffsetPgRepository, err error) {
+	pgrep.TableName = "tg_offsets"
+	pgrep.Name = "default"
+	pgrep.dbpool = dbpool
+
+	return
+}
+
+func (rep *OffsetPgRepository) UpdateDB(ctx context.Context) (err error) {
+	sql := "CREATE TABLE IF NOT EXISTS %s " +
+		"(name varchar(63) PRIMARY KEY, update_offset bigint NOT NULL DEFAULT 0);"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	_, err = rep.dbpool.Exec(ctx, sql)
+
+	return
+}
+
+func (rep *OffsetPgRepository) Get(ctx context.Context) (offset int, err error) {
+	sql := "SELECT update_offset FROM %s WHERE name = $1"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	err = rep.dbpool.QueryRow(ctx, sql, rep.Name).Scan(&offset)
+	if err == pgx.ErrNoRows {
+		err = nil
+	}
+	return
+}
+
+func (rep *OffsetPgRepository) Set(ctx context.Context, offset int) (err error) {
+	sql := "INSERT INTO %s (name, update_offset) VALUES ($1, $2) " +
+		"ON CONFLICT (name) DO UPDATE SET update_offset = EXCLUDED.update_offset"
+	sql = fmt.Sprintf(sql, rep.TableName)
+	_, err = rep.dbpool.Exec(ctx, sql, rep.Name, offset)
+
+	return
+}
@@ -29,32 +29,32 @@ func NewPersonPgRepository(dbpool *pgxpool.Pool) (pgrep PersonPgRepository, err
 	return
 }
 
-func (rep *PersonPgRepository) Get(pid uuid.UUID) (p person.Person, err error) {
+func (rep *PersonPgRepository) Get(ctx context.Context, pid uuid.UUID) (p person.Person, err error) {
 	p = person.NewPerson("")
-	sql := "SELECT person_id, telegram_id, firstname, lastname, fullname, sex " +
+	sql := "SELECT person_id, telegram_id, firstname, lastname, fullname, sex, COALESCE(phone, '') " +
 		"FROM %s " +
 		"WHERE person_id = $1"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.TableName), pid)
-	err = row.Scan(&p.Id, &p.TelegramId, &p.Firstname, &p.Lastname, &p.Fullname, &p.Sex)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), pid)
+	err = row.Scan(&p.Id, &p.TelegramId, &p.Firstname, &p.Lastname, &p.Fullname, &p.Sex, &p.Phone)
 	if err != nil {
 		return
 	}
-	p.LocationRoles, err = rep.GetRoles(p.Id)
+	p.LocationRoles, err = rep.GetRoles(ctx, p.Id)
 	if err != nil {
 		return
 	}
-	p.Settings, err = rep.GetSettings(p.Id)
+	p.Settings, err = rep.GetSettings(ctx, p.Id)
 	return
 }
 
-func (rep *PersonPgRepository) GetByTelegramId(tid int) (p person.Person, err error) {
+func (rep *PersonPgRepository) GetByTelegramId(ctx context.Context, tid int) (p person.Person, err error) {
 	p = person.NewPerson("")
-	sql := "SELECT person_id, telegram_id, firstname, lastname, fullname, sex " +
+	sql := "SELECT person_id, telegram_id, firstname, lastname, fullname, sex, COALESCE(phone, '') " +
 		"FROM %s " +
 		"WHERE telegram_id = $1"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.TableName), tid)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), tid)
 
-	err = row.Scan(&p.Id, &p.TelegramId, &p.Firstname, &p.Lastname, &p.Fullname, &p.Sex)
+	err = row.Scan(&p.Id, &p.TelegramId, &p.Firstname, &p.Lastname, &p.Fullname, &p.Sex, &p.Phone)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			err = person.ErrPersonNotFound
@@ -62,46 +62,46 @@ func (rep *PersonPgRepository) GetByTelegramId(tid int) (p person.Person, err er
 		}
 		return
 	}
-	p.LocationRoles, err = rep.GetRoles(p.Id)
+	p.LocationRoles, err = rep.GetRoles(ctx, p.Id)
 	if err != nil {
 		return
 	}
-	p.Settings, err = rep.GetSettings(p.Id)
+	p.Settings, err = rep.GetSettings(ctx, p.Id)
 	return
 }
 
-func (rep *PersonPgRepository) Add(p person.Person) (per person.Person, err error) {
+func (rep *PersonPgRepository) Add(ctx context.Context, p person.Person) (per person.Person, err error) {
 	sql := "INSERT INTO %s " +
-		"(person_id, telegram_id, firstname, lastname, fullname, sex) " +
-		"VALUES ($1, $2, $3, $4, $5, $6) " +
+		"(person_id, telegram_id, firstname, lastname, fullname, sex, phone) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7) " +
 		"RETURNING person_id"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	row := rep.dbpool.QueryRow(context.Background(), sql,
-		p.Id, p.TelegramId, p.Firstname, p.Lastname, p.Fullname, p.Sex)
+	row := rep.dbpool.QueryRow(ctx, sql,
+		p.Id, p.TelegramId, p.Firstname, p.Lastname, p.Fullname, p.Sex, p.Phone)
 
 	err = row.Scan(&p.Id)
 	return p, err
 }
 
-func (rep *PersonPgRepository) Update(p person.Person) (err error) {
+func (rep *PersonPgRepository) Update(ctx context.Context, p person.Person) (err error) {
 	sql := "UPDATE %s SET " +
-		"telegram_id = $1, firstname = $2, lastname = $3, fullname = $4, sex = $5 " +
-		"WHERE person_id = $6"
+		"telegram_id = $1, firstname = $2, lastname = $3, fullname = $4, sex = $5, phone = $6 " +
+		"WHERE person_id = $7"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	rows, err := rep.dbpool.Query(context.Background(), sql,
-		p.TelegramId, p.Firstname, p.Lastname, p.Fullname, p.Sex, p.Id)
+	rows, err := rep.dbpool.Query(ctx, sql,
+		p.TelegramId, p.Firstname, p.Lastname, p.Fullname, p.Sex, p.Phone, p.Id)
 
 	if err != nil {
 		return
 	}
-	err = rep.UpdateParams(p)
+	err = rep.UpdateParams(ctx, p)
 	defer rows.Close()
 	return
 }
 
-func (rep *PersonPgRepository) UpdateParams(p person.Person) (err error) {
+func (rep *PersonPgRepository) UpdateParams(ctx context.Context, p person.Person) (err error) {
 	usql := "UPDATE %s SET param_value = $3 " +
 		"WHERE person_id = $1 AND param_name =$2"
 	usql = fmt.Sprintf(usql, rep.SettingsTableName)
@@ -112,27 +112,29 @@ func (rep *PersonPgRepository) UpdateParams(p person.Person) (err error) {
 	isql = fmt.Sprintf(isql, rep.SettingsTableName)
 
 	for param, val := range p.Settings {
-		rres, _ := rep.dbpool.Exec(context.Background(), usql, p.Id, param, val)
+		rres, _ := rep.dbpool.Exec(ctx, usql, p.Id, param, val)
 
 		if rres.RowsAffected() < 1 {
-			_, err = rep.dbpool.Exec(context.Background(), isql, p.Id, param, val)
+			_, err = rep.dbpool.Exec(ctx, isql, p.Id, param, val)
 		}
 	}
 	return
 }
 
-func (rep *PersonPgRepository) UpdateDB() (err error) {
+func (rep *PersonPgRepository) UpdateDB(ctx context.Context) (err error) {
 	sql := "CREATE TABLE IF NOT EXISTS %s " +
 		"(person_id UUID PRIMARY KEY, telegram_id BIGINT, " +
 		"firstname VARCHAR(20), lastname VARCHAR(20), fullname VARCHAR(60), " +
 		"sex INT, level INT, " +
 		"roles varchar(250));"
+	sql += "ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS phone VARCHAR(20);"
 	sql += "CREATE TABLE IF NOT EXISTS %s " +
 		"(person_id UUID, location_id UUID, role VARCHAR(30));"
 	sql += "CREATE TABLE IF NOT EXISTS %s " +
 		"(person_id UUID, param_name VARCHAR(20), param_value VARCHAR(250));"
+	sql += "ALTER TABLE %[3]s ALTER COLUMN param_name TYPE VARCHAR(63);"
 	sql = fmt.Sprintf(sql, rep.TableName, rep.RolesTableName, rep.SettingsTableName)
-	_, err = rep.dbpool.Exec(context.Background(), sql)
+	_, err = rep.dbpool.Exec(ctx, sql)
 
 	if err != nil {
 		return
@@ -141,13 +143,13 @@ func (rep *PersonPgRepository) UpdateDB() (err error) {
 	return
 }
 
-func (rep *PersonPgRepository) GetRoles(pid uuid.UUID) (pmap map[uuid.UUID][]string, err error) {
+func (rep *PersonPgRepository) GetRoles(ctx context.Context, pid uuid.UUID) (pmap map[uuid.UUID][]string, err error) {
 	sql := "SELECT roles.person_id, roles.location_id, roles.role " +
 		"FROM %s AS roles " +
 		"INNER JOIN %s AS p ON roles.person_id = p.person_id " +
 		"WHERE roles.person_id = $1;"
 	sql = fmt.Sprintf(sql, rep.RolesTableName, rep.TableName)
-	rows, err := rep.dbpool.Query(context.Background(), sql, pid)
+	rows, err := rep.dbpool.Query(ctx, sql, pid)
 	pmap = make(map[uuid.UUID][]string)
 	var (
 		PersonId, LocationId uuid.UUID
@@ -161,13 +163,13 @@ func (rep *PersonPgRepository) GetRoles(pid uuid.UUID) (pmap map[uuid.UUID][]str
 	return
 }
 
-func (rep *PersonPgRepository) GetSettings(pid uuid.UUID) (pmap map[string]string, err error) {
+func (rep *PersonPgRepository) GetSettings(ctx context.Context, pid uuid.UUID) (pmap map[string]string, err error) {
 	sql := "SELECT params.param_name, params.param_value " +
 		"FROM %s AS params " +
 		"INNER JOIN %s AS p ON params.person_id = p.person_id " +
 		"WHERE params.person_id = $1;"
 	sql = fmt.Sprintf(sql, rep.SettingsTableName, rep.TableName)
-	rows, err := rep.dbpool.Query(context.Background(), sql, pid)
+	rows, err := rep.dbpool.Query(ctx, sql, pid)
 	pmap = make(map[string]string)
 	var Param, Value string
 
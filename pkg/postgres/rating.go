@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/volley"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type RatingPgRepository struct {
+	dbpool           *pgxpool.Pool
+	TableName        string
+	HistoryTableName string
+}
+
+func NewRatingPgRepository(dbpool *pgxpool.Pool) (pgrep RatingPgRepository, err error) {
+	pgrep.TableName = "bvratings"
+	pgrep.HistoryTableName = "bvrating_history"
+	pgrep.dbpool = dbpool
+	return
+}
+
+func (rep *RatingPgRepository) UpdateDB(ctx context.Context) (err error) {
+	sql := "CREATE TABLE IF NOT EXISTS %[1]s " +
+		"(person_id UUID, location_id UUID, rating INT, games INT, " +
+		"PRIMARY KEY (person_id, location_id));" +
+		"CREATE TABLE IF NOT EXISTS %[2]s " +
+		"(person_id UUID, location_id UUID, reserve_id UUID, rating_before INT, rating_after INT, " +
+		"games INT, change_time TIMESTAMP);" +
+		"CREATE UNIQUE INDEX IF NOT EXISTS %[2]s_person_reserve ON %[2]s (person_id, reserve_id);"
+	_, err = rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName, rep.HistoryTableName))
+	return
+}
+
+func (rep *RatingPgRepository) Get(ctx context.Context, loc location.Location, pid uuid.UUID) (r volley.Rating, err error) {
+	sql := "SELECT person_id, location_id, rating, games " +
+		"FROM %s " +
+		"WHERE person_id = $1 AND location_id = $2"
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), pid, loc.Id)
+	if err = row.Scan(&r.PersonId, &r.LocationId, &r.Value, &r.Games); err == pgx.ErrNoRows {
+		err = volley.ErrRatingNotFound
+	}
+	return
+}
+
+func (rep *RatingPgRepository) GetHistory(ctx context.Context, loc location.Location, pid uuid.UUID) (clist []volley.RatingChange, err error) {
+	sql := "SELECT person_id, location_id, reserve_id, rating_before, rating_after, games, change_time " +
+		"FROM %s " +
+		"WHERE person_id = $1 AND location_id = $2 " +
+		"ORDER BY change_time"
+	rows, err := rep.dbpool.Query(ctx, fmt.Sprintf(sql, rep.HistoryTableName), pid, loc.Id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c volley.RatingChange
+		if err = rows.Scan(&c.PersonId, &c.LocationId, &c.ReserveId, &c.Before, &c.After, &c.Games, &c.Time); err != nil {
+			return
+		}
+		clist = append(clist, c)
+	}
+	return
+}
+
+// Apply saves new ratings and their history in one transaction. A change of
+// the person already saved for the reserve is skipped, so the game can't be
+// rated twice.
+func (rep *RatingPgRepository) Apply(ctx context.Context, changes []volley.RatingChange) (err error) {
+	rsql := "INSERT INTO %s (person_id, location_id, rating, games) VALUES ($1, $2, $3, $4) " +
+		"ON CONFLICT (person_id, location_id) DO UPDATE SET rating = $3, games = $4"
+	hsql := "INSERT INTO %s " +
+		"(person_id, location_id, reserve_id, rating_before, rating_after, games, change_time) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7) " +
+		"ON CONFLICT (person_id, reserve_id) DO NOTHING"
+	rsql = fmt.Sprintf(rsql, rep.TableName)
+	hsql = fmt.Sprintf(hsql, rep.HistoryTableName)
+
+	tx, err := rep.dbpool.Begin(ctx)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback(ctx)
+	for _, c := range changes {
+		hres, herr := tx.Exec(ctx, hsql, c.PersonId, c.LocationId, c.ReserveId, c.Before, c.After, c.Games, c.Time)
+		if err = herr; err != nil {
+			return
+		}
+		if hres.RowsAffected() < 1 {
+			continue
+		}
+		if _, err = tx.Exec(ctx, rsql, c.PersonId, c.LocationId, c.After, c.Games); err != nil {
+			return
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/volley"
+
+	"github.com/google/uuid"
+)
+
+func TestRatingRepositoryApply(t *testing.T) {
+	ctx := context.Background()
+	dbpool := newTestPool(t)
+	rep, _ := NewRatingPgRepository(dbpool)
+	suffix := time.Now().UnixNano()
+	rep.TableName = fmt.Sprintf("bvratings_test_%d", suffix)
+	rep.HistoryTableName = fmt.Sprintf("bvrating_history_test_%d", suffix)
+	if err := rep.UpdateDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dbpool.Exec(context.Background(), "DROP TABLE IF EXISTS "+rep.TableName+", "+rep.HistoryTableName)
+	})
+
+	loc := location.Location{Id: uuid.New()}
+	pid, rid := uuid.New(), uuid.New()
+	c := volley.RatingChange{PersonId: pid, LocationId: loc.Id, ReserveId: rid,
+		Before: volley.DefaultRating, After: volley.DefaultRating + 10, Games: 1, Time: time.Now()}
+	err := rep.Apply(ctx, []volley.RatingChange{c})
+
+	t.Run("No error", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Repeated change skipped", func(t *testing.T) {
+		again := c
+		again.Before, again.After, again.Games = c.After, c.After+10, 2
+		if err := rep.Apply(ctx, []volley.RatingChange{again}); err != nil {
+			t.FailNow()
+		}
+		if r, err := rep.Get(ctx, loc, pid); err != nil || r.Value != c.After || r.Games != 1 {
+			t.Fail()
+		}
+		if clist, _ := rep.GetHistory(ctx, loc, pid); len(clist) != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Other game applied", func(t *testing.T) {
+		next := c
+		next.ReserveId, next.Before, next.After, next.Games = uuid.New(), c.After, c.After+10, 2
+		rep.Apply(ctx, []volley.RatingChange{next})
+		if r, _ := rep.Get(ctx, loc, pid); r.Value != next.After || r.Games != 2 {
+			t.Fail()
+		}
+	})
+}
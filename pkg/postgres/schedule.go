@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type SchedulePgRepository struct {
+	dbpool             *pgxpool.Pool
+	PersonRepository   person.PersonRepository
+	LocationRepository location.LocationRepository
+	TableName          string
+}
+
+func NewSchedulePgRepository(dbpool *pgxpool.Pool, prep person.PersonRepository, lrep location.LocationRepository) (pgrep SchedulePgRepository, err error) {
+	pgrep.TableName = "bvschedules"
+	pgrep.dbpool = dbpool
+	pgrep.PersonRepository = prep
+	pgrep.LocationRepository = lrep
+	return
+}
+
+func (rep *SchedulePgRepository) UpdateDB(ctx context.Context) (err error) {
+	sql := "CREATE TABLE IF NOT EXISTS %s " +
+		"(schedule_id UUID PRIMARY KEY, person_id UUID, location_id UUID, rule varchar(100), " +
+		"start_time TIMESTAMP, end_time TIMESTAMP, price INT, min_level INT, court_count INT, " +
+		"max_players INT, net_type INT, activity INT, description varchar(4000), " +
+		"paused BOOL, generated TIMESTAMP);"
+	_, err = rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName))
+	return
+}
+
+func (rep *SchedulePgRepository) scan(ctx context.Context, row pgx.Row) (s volley.Schedule, err error) {
+	var rule string
+	t := &s.Template
+	err = row.Scan(&s.Id, &t.Person.Id, &t.Location.Id, &rule, &t.StartTime, &t.EndTime, &t.Price,
+		&t.MinLevel, &t.CourtCount, &t.MaxPlayers, &t.NetType, &t.Activity, &t.Description,
+		&s.Paused, &s.Generated)
+	if err != nil {
+		return
+	}
+	if err = s.SetRule(rule); err != nil {
+		return
+	}
+	t.Id = s.Id
+	t.Members = []volley.Member{}
+	t.Person, _ = rep.PersonRepository.Get(ctx, t.Person.Id)
+	t.Location, _ = rep.LocationRepository.Get(ctx, t.Location.Id)
+	return
+}
+
+func (rep *SchedulePgRepository) Get(ctx context.Context, id uuid.UUID) (s volley.Schedule, err error) {
+	sql := "SELECT schedule_id, person_id, location_id, rule, start_time, end_time, price, " +
+		"min_level, court_count, max_players, net_type, activity, description, paused, generated " +
+		"FROM %s " +
+		"WHERE schedule_id = $1"
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.TableName), id)
+	if s, err = rep.scan(ctx, row); err == pgx.ErrNoRows {
+		err = volley.ErrScheduleNotFound
+	}
+	return
+}
+
+func (rep *SchedulePgRepository) GetByLocation(ctx context.Context, loc location.Location) (slist []volley.Schedule, err error) {
+	sql := "SELECT schedule_id, person_id, location_id, rule, start_time, end_time, price, " +
+		"min_level, court_count, max_players, net_type, activity, description, paused, generated " +
+		"FROM %s " +
+		"WHERE location_id = $1 " +
+		"ORDER BY start_time"
+	rows, err := rep.dbpool.Query(ctx, fmt.Sprintf(sql, rep.TableName), loc.Id)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		s, err := rep.scan(ctx, rows)
+		if err != nil {
+			return slist, err
+		}
+		slist = append(slist, s)
+	}
+	return
+}
+
+func (rep *SchedulePgRepository) Add(ctx context.Context, s volley.Schedule) (volley.Schedule, error) {
+	sql := "INSERT INTO %s " +
+		"(schedule_id, person_id, location_id, rule, start_time, end_time, price, " +
+		"min_level, court_count, max_players, net_type, activity, description, paused, generated) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)"
+	t := s.Template
+	_, err := rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName),
+		s.Id, t.Person.Id, t.Location.Id, s.Rule(), t.StartTime, t.GetEndTime(), t.Price,
+		t.MinLevel, t.CourtCount, t.MaxPlayers, t.NetType, t.Activity, t.Description, s.Paused, s.Generated)
+	return s, err
+}
+
+func (rep *SchedulePgRepository) Update(ctx context.Context, s volley.Schedule) (err error) {
+	sql := "UPDATE %s SET " +
+		"person_id = $1, location_id = $2, rule = $3, start_time = $4, end_time = $5, price = $6, " +
+		"min_level = $7, court_count = $8, max_players = $9, net_type = $10, activity = $11, " +
+		"description = $12, paused = $13, generated = $14 " +
+		"WHERE schedule_id = $15"
+	t := s.Template
+	_, err = rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName),
+		t.Person.Id, t.Location.Id, s.Rule(), t.StartTime, t.GetEndTime(), t.Price,
+		t.MinLevel, t.CourtCount, t.MaxPlayers, t.NetType, t.Activity, t.Description, s.Paused, s.Generated, s.Id)
+	return
+}
+
+func (rep *SchedulePgRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	sql := "DELETE FROM %s WHERE schedule_id = $1"
+	_, err = rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName), id)
+	return
+}
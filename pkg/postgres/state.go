@@ -24,27 +24,29 @@ func NewStateRepository(dbpool *pgxpool.Pool) (pgrep StatePgRepository, err erro
 	return
 }
 
-func (rep *StatePgRepository) UpdateDB() (err error) {
+func (rep *StatePgRepository) UpdateDB(ctx context.Context) (err error) {
 	sql := "CREATE TABLE IF NOT EXISTS %s " +
 		"(chat_id bigint, message_id bigint, prefix varchar(63), state varchar(63)," +
 		" action varchar(63), data varchar(240));"
 	sql = fmt.Sprintf(sql, rep.TableName)
-	_, err = rep.dbpool.Exec(context.Background(), sql)
+	_, err = rep.dbpool.Exec(ctx, sql)
 
 	if err != nil {
 		return
 	}
 
+	sql = "ALTER TABLE %s ADD COLUMN IF NOT EXISTS inline_message_id varchar(255) DEFAULT '';"
+	_, err = rep.dbpool.Exec(ctx, fmt.Sprintf(sql, rep.TableName))
 	return
 }
 
-func (rep *StatePgRepository) Get(ChatId int) (slist []telegram.State, err error) {
+func (rep *StatePgRepository) Get(ctx context.Context, ChatId int) (slist []telegram.State, err error) {
 	sql := "SELECT chat_id, message_id, prefix, state, action, data " +
 		"FROM %s " +
-		"WHERE chat_id = $1 and message_id <= 0" +
+		"WHERE chat_id = $1 and message_id <= 0 " +
 		"ORDER BY message_id DESC"
 	sql = fmt.Sprintf(sql, rep.TableName)
-	rows, err := rep.dbpool.Query(context.Background(), sql, ChatId)
+	rows, err := rep.dbpool.Query(ctx, sql, ChatId)
 	if err == nil {
 		defer rows.Close()
 		st := telegram.NewState()
@@ -56,28 +58,28 @@ func (rep *StatePgRepository) Get(ChatId int) (slist []telegram.State, err error
 	return
 }
 
-func (rep *StatePgRepository) GetByData(Data string) (slist []telegram.State, err error) {
-	sql := "SELECT chat_id, message_id, prefix, state, action, data " +
+func (rep *StatePgRepository) GetByData(ctx context.Context, Data string) (slist []telegram.State, err error) {
+	sql := "SELECT chat_id, message_id, prefix, state, action, data, COALESCE(inline_message_id, '') " +
 		"FROM %s " +
 		"WHERE data = $1 "
 	sql = fmt.Sprintf(sql, rep.TableName)
-	if rows, err := rep.dbpool.Query(context.Background(), sql, Data); err == nil {
+	if rows, err := rep.dbpool.Query(ctx, sql, Data); err == nil {
 		defer rows.Close()
 		st := telegram.NewState()
 		for rows.Next() {
-			rows.Scan(&st.ChatId, &st.MessageId, &st.Prefix, &st.State, &st.Action, &st.Data)
+			rows.Scan(&st.ChatId, &st.MessageId, &st.Prefix, &st.State, &st.Action, &st.Data, &st.InlineMessageId)
 			slist = append(slist, st)
 		}
 	}
 	return
 }
 
-func (rep *StatePgRepository) GetByMessage(msg telegram.Message) (state telegram.State, err error) {
+func (rep *StatePgRepository) GetByMessage(ctx context.Context, msg telegram.Message) (state telegram.State, err error) {
 	sql := "SELECT chat_id, message_id, prefix, state, action, data " +
 		"FROM %s " +
 		"WHERE chat_id = $1 AND message_id = $2 "
 	sql = fmt.Sprintf(sql, rep.TableName)
-	rows, err := rep.dbpool.Query(context.Background(), sql, msg.Chat.Id, msg.MessageId)
+	rows, err := rep.dbpool.Query(ctx, sql, msg.Chat.Id, msg.MessageId)
 	if err == nil {
 		defer rows.Close()
 		st := telegram.NewState()
@@ -89,37 +91,46 @@ func (rep *StatePgRepository) GetByMessage(msg telegram.Message) (state telegram
 	return
 }
 
-func (rep *StatePgRepository) Set(st telegram.State) (err error) {
+func (rep *StatePgRepository) Set(ctx context.Context, st telegram.State) (err error) {
 	sql := "UPDATE %s SET " +
 		"prefix =$1, state = $2, action =$3, data = $4 " +
-		"WHERE (chat_id = $5) AND (message_id = $6)"
+		"WHERE (chat_id = $5) AND (message_id = $6) AND (COALESCE(inline_message_id, '') = $7)"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	rres, err := rep.dbpool.Exec(context.Background(), sql,
-		st.Prefix, st.State, st.Action, st.Data, st.ChatId, st.MessageId)
+	rres, err := rep.dbpool.Exec(ctx, sql,
+		st.Prefix, st.State, st.Action, st.Data, st.ChatId, st.MessageId, st.InlineMessageId)
 	if rres.RowsAffected() < 1 {
-		rep.Add(st)
+		rep.Add(ctx, st)
 	}
 	return
 }
 
-func (rep *StatePgRepository) Add(st telegram.State) error {
+func (rep *StatePgRepository) Add(ctx context.Context, st telegram.State) error {
 	sql := "INSERT INTO %s " +
-		"(chat_id, message_id, prefix, state, action, data) " +
-		"VALUES ($1, $2, $3, $4, $5, $6);"
+		"(chat_id, message_id, prefix, state, action, data, inline_message_id) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7);"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	row := rep.dbpool.QueryRow(context.Background(), sql,
-		st.ChatId, st.MessageId, st.Prefix, st.State, st.Action, st.Data)
+	row := rep.dbpool.QueryRow(ctx, sql,
+		st.ChatId, st.MessageId, st.Prefix, st.State, st.Action, st.Data, st.InlineMessageId)
 	return row.Scan()
 }
 
-func (rep *StatePgRepository) Clear(st telegram.State) error {
+func (rep *StatePgRepository) Clear(ctx context.Context, st telegram.State) error {
+	sql := "DELETE FROM %s " +
+		"WHERE (chat_id = $1) AND (message_id = $2) AND (COALESCE(inline_message_id, '') = $3);"
+	sql = fmt.Sprintf(sql, rep.TableName)
+
+	_, err := rep.dbpool.Exec(ctx, sql,
+		st.ChatId, st.MessageId, st.InlineMessageId)
+	return err
+}
+
+func (rep *StatePgRepository) ClearChat(ctx context.Context, ChatId int) error {
 	sql := "DELETE FROM %s " +
-		"WHERE (chat_id = $1) AND (message_id = $2);"
+		"WHERE (chat_id = $1);"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	_, err := rep.dbpool.Exec(context.Background(), sql,
-		st.ChatId, st.MessageId)
+	_, err := rep.dbpool.Exec(ctx, sql, ChatId)
 	return err
 }
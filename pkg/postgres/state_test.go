@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"volleybot/pkg/telegram"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	url := os.Getenv("PGURL")
+	if url == "" {
+		t.Skip("PGURL is not set")
+	}
+	dbpool, err := pgxpool.Connect(context.Background(), url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(dbpool.Close)
+	return dbpool
+}
+
+func TestStateRepositoryGet(t *testing.T) {
+	ctx := context.Background()
+	dbpool := newTestPool(t)
+	rep, _ := NewStateRepository(dbpool)
+	rep.TableName = fmt.Sprintf("tg_states_test_%d", time.Now().UnixNano())
+	if err := rep.UpdateDB(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		dbpool.Exec(context.Background(), "DROP TABLE IF EXISTS "+rep.TableName)
+	})
+
+	st := telegram.NewState()
+	st.Prefix, st.State, st.Action, st.Data, st.ChatId = "res", "desc", "desc", "data", 100
+	for _, mid := range []int{-1, 0, 55} {
+		st.MessageId = mid
+		if err := rep.Set(ctx, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st.ChatId, st.MessageId = 200, -1
+	rep.Set(ctx, st)
+
+	slist, err := rep.Get(ctx, 100)
+
+	t.Run("No error", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Waiting states of the chat only", func(t *testing.T) {
+		if len(slist) != 2 {
+			t.FailNow()
+		}
+		for _, s := range slist {
+			if s.ChatId != 100 || s.MessageId > 0 {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Ordered by message id", func(t *testing.T) {
+		if len(slist) != 2 || slist[0].MessageId != 0 || slist[1].MessageId != -1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Chat cleared with messages", func(t *testing.T) {
+		if err := rep.ClearChat(ctx, 100); err != nil {
+			t.FailNow()
+		}
+		slist, _ := rep.GetByData(ctx, "data")
+		if len(slist) != 1 || slist[0].ChatId != 200 {
+			t.Fail()
+		}
+	})
+}
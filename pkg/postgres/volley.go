@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/order"
 	"volleybot/pkg/domain/person"
 	"volleybot/pkg/domain/volley"
 
@@ -28,6 +29,7 @@ func NewVolleyPgRepository(dbpool *pgxpool.Pool, PersonRepository person.PersonR
 	rep.MembersSpName = "sp_bvreserve_member_update"
 	rep.PlayersTableName = "bvplayers"
 	rep.PlayersSpName = "sp_bvplayer_update"
+	rep.PaymentsTableName = "bvpayments"
 
 	if err != nil {
 		return
@@ -46,21 +48,33 @@ type VolleyPgRepository struct {
 	MembersSpName      string
 	PlayersTableName   string
 	PlayersSpName      string
+	PaymentsTableName  string
 }
 
-func (rep *VolleyPgRepository) UpdateDB() (err error) {
+func (rep *VolleyPgRepository) UpdateDB(ctx context.Context) (err error) {
 	sql := "CREATE TABLE IF NOT EXISTS %[1]s " +
 		"(reserve_id UUID PRIMARY KEY, person_id UUID, location_id UUID, " +
 		"start_time TIMESTAMP, end_time TIMESTAMP, price INT, " +
 		"min_level INT, court_count INT, max_players INT, net_type INT, " +
-		"ordered BOOL, approved BOOL, canceled BOOL, description varchar(4000), activity INT);"
+		"ordered BOOL, approved BOOL, canceled BOOL, description varchar(4000), activity INT);" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS poll_id varchar(64);" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS schedule_id UUID;" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS teams JSONB;" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS together JSONB;" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS results JSONB;" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS rated BOOL;" +
+		"ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS bracket JSONB;"
 
 	mb_sql := "CREATE TABLE IF NOT EXISTS %[2]s "
 	mb_sql += "(member_id serial, reserve_id UUID, person_id UUID, count INT, "
 	mb_sql += "arrive_time TIMESTAMP, paid BOOL);"
+	mb_sql += "ALTER TABLE %[2]s ADD COLUMN IF NOT EXISTS promoted_at TIMESTAMP;"
 	pl_sql := "CREATE TABLE IF NOT EXISTS %[3]s (person_id UUID PRIMARY KEY, level INT);"
+	pm_sql := "CREATE TABLE IF NOT EXISTS %[6]s "
+	pm_sql += "(payment_id UUID PRIMARY KEY, reserve_id UUID, person_id UUID, sum INT, currency varchar(3), "
+	pm_sql += "charge_id varchar(255) UNIQUE, provider_charge_id varchar(255), payment_time TIMESTAMP);"
 	sp_sql := "CREATE OR REPLACE PROCEDURE " +
-		"%[4]s(res_id UUID, per_id UUID, c INT, at TIMESTAMP, pd BOOL) " +
+		"%[4]s(res_id UUID, per_id UUID, c INT, at TIMESTAMP, pd BOOL, pa TIMESTAMP) " +
 		"LANGUAGE plpgsql AS $$ " +
 		"DECLARE cur_count INT;\n" +
 		"BEGIN\n" +
@@ -71,9 +85,11 @@ func (rep *VolleyPgRepository) UpdateDB() (err error) {
 		"END IF;\n" +
 		"CASE\n" +
 		"WHEN cur_count > 0 THEN\n" +
-		"UPDATE %[2]s SET count = c, arrive_time = at, paid = pd WHERE reserve_id = res_id AND person_id = per_id;\n" +
+		"UPDATE %[2]s SET count = c, arrive_time = at, paid = pd, promoted_at = pa " +
+		"WHERE reserve_id = res_id AND person_id = per_id;\n" +
 		"ELSE\n" +
-		"INSERT INTO %[2]s (reserve_id, person_id, count, arrive_time, paid) VALUES (res_id, per_id, c, at, pd);\n" +
+		"INSERT INTO %[2]s (reserve_id, person_id, count, arrive_time, paid, promoted_at) " +
+		"VALUES (res_id, per_id, c, at, pd, pa);\n" +
 		"END CASE;\n" +
 		"END;$$;"
 	sp_pl_sql := "CREATE OR REPLACE PROCEDURE " +
@@ -86,9 +102,9 @@ func (rep *VolleyPgRepository) UpdateDB() (err error) {
 		"UPDATE %[3]s SET level = lvl WHERE person_id = per_id;\n" +
 		"END IF;\n" +
 		"END;$$;"
-	sql = fmt.Sprintf(sql+mb_sql+pl_sql+sp_sql+sp_pl_sql, rep.TableName, rep.MembersTableName, rep.PlayersTableName,
-		rep.MembersSpName, rep.PlayersSpName)
-	_, err = rep.dbpool.Exec(context.Background(), sql)
+	sql = fmt.Sprintf(sql+mb_sql+pl_sql+pm_sql+sp_sql+sp_pl_sql, rep.TableName, rep.MembersTableName, rep.PlayersTableName,
+		rep.MembersSpName, rep.PlayersSpName, rep.PaymentsTableName)
+	_, err = rep.dbpool.Exec(ctx, sql)
 
 	if err != nil {
 		return
@@ -97,48 +113,74 @@ func (rep *VolleyPgRepository) UpdateDB() (err error) {
 	return
 }
 
-func (rep *VolleyPgRepository) GetMembers(rid uuid.UUID) (mlist []volley.Member, err error) {
-	sql := "SELECT member_id, count, arrive_time, paid, person_id " +
+func (rep *VolleyPgRepository) GetMembers(ctx context.Context, rid uuid.UUID) (mlist []volley.Member, err error) {
+	sql := "SELECT member_id, count, arrive_time, paid, person_id, COALESCE(promoted_at, '0001-01-01') " +
 		"FROM %s " +
 		"WHERE reserve_id = $1 " +
 		"ORDER BY paid DESC, member_id "
 	sql = fmt.Sprintf(sql, rep.MembersTableName)
-	rows, err := rep.dbpool.Query(context.Background(), sql, rid)
+	rows, err := rep.dbpool.Query(ctx, sql, rid)
 	var mb volley.Member
 	for rows.Next() {
 		var paid bool
-		rows.Scan(&mb.MemberId, &mb.Count, &mb.ArriveTime, &paid, &mb.Id)
+		rows.Scan(&mb.MemberId, &mb.Count, &mb.ArriveTime, &paid, &mb.Id, &mb.PromotedAt)
 		mb.SetPaid(paid)
-		p, _ := rep.PersonRepository.Get(mb.Id)
-		mb.Player, _ = rep.GetPlayer(p)
+		p, _ := rep.PersonRepository.Get(ctx, mb.Id)
+		mb.Player, _ = rep.GetPlayer(ctx, p)
+		mb.Payment = order.Payment{}
+		if paid {
+			mb.Payment, _ = rep.GetPayment(ctx, rid, mb.Person)
+		}
 		mlist = append(mlist, mb)
 	}
 	return
 }
 
-func (rep *VolleyPgRepository) Get(rid uuid.UUID) (res volley.Volley, err error) {
+func (rep *VolleyPgRepository) GetPayment(ctx context.Context, rid uuid.UUID, p person.Person) (pm order.Payment, err error) {
+	sql := "SELECT payment_id, sum, currency, charge_id, provider_charge_id, payment_time " +
+		"FROM %s " +
+		"WHERE reserve_id = $1 AND person_id = $2 " +
+		"ORDER BY payment_time DESC LIMIT 1"
+	sql = fmt.Sprintf(sql, rep.PaymentsTableName)
+	row := rep.dbpool.QueryRow(ctx, sql, rid, p.Id)
+	if err = row.Scan(&pm.Id, &pm.Sum, &pm.Currency, &pm.ChargeId, &pm.ProviderChargeId, &pm.Date); err != nil {
+		return order.Payment{}, err
+	}
+	pm.Person = p
+	return
+}
+
+func (rep *VolleyPgRepository) Get(ctx context.Context, rid uuid.UUID) (res volley.Volley, err error) {
 	sql_str := "SELECT reserve_id, person_id, location_id, start_time, end_time, price, " +
-		"min_level, court_count, max_players, net_type, approved, canceled, description, activity " +
+		"min_level, court_count, max_players, net_type, approved, canceled, description, activity, " +
+		"COALESCE(poll_id, ''), COALESCE(schedule_id, '00000000-0000-0000-0000-000000000000'), " +
+		"COALESCE(teams, '[]'), COALESCE(together, '[]'), COALESCE(results, '[]'), COALESCE(rated, false), " +
+		"COALESCE(bracket, '{}') " +
 		"FROM %s " +
 		"WHERE reserve_id = $1"
 	sql_str = fmt.Sprintf(sql_str, rep.TableName)
-	row := rep.dbpool.QueryRow(context.Background(), sql_str, rid)
+	row := rep.dbpool.QueryRow(ctx, sql_str, rid)
 
 	err = row.Scan(&res.Id, &res.Person.Id, &res.Location.Id, &res.StartTime, &res.EndTime, &res.Price,
-		&res.MinLevel, &res.CourtCount, &res.MaxPlayers, &res.NetType, &res.Approved, &res.Canceled, &res.Description, &res.Activity)
+		&res.MinLevel, &res.CourtCount, &res.MaxPlayers, &res.NetType, &res.Approved, &res.Canceled, &res.Description, &res.Activity,
+		&res.PollId, &res.ScheduleId, &res.Teams, &res.Together, &res.Results, &res.Rated,
+		&res.Bracket)
 	if err != nil {
 		return
 	}
-	res.Person, _ = rep.PersonRepository.Get(res.Person.Id)
-	res.Location, _ = rep.LocationRepository.Get(res.Location.Id)
-	plist, err := rep.GetMembers(res.Id)
+	res.Person, _ = rep.PersonRepository.Get(ctx, res.Person.Id)
+	res.Location, _ = rep.LocationRepository.Get(ctx, res.Location.Id)
+	plist, err := rep.GetMembers(ctx, res.Id)
 	res.Members = plist
 	return
 }
 
-func (rep *VolleyPgRepository) GetByFilter(filter volley.Volley, oredered bool, sorted bool) (rmap []volley.Volley, err error) {
+func (rep *VolleyPgRepository) GetByFilter(ctx context.Context, filter volley.Volley, oredered bool, sorted bool) (rmap []volley.Volley, err error) {
 	sql_str := "SELECT reserve_id, person_id, start_time, end_time, price, " +
-		"min_level, court_count, max_players, net_type, approved, canceled, description, activity " +
+		"min_level, court_count, max_players, net_type, approved, canceled, description, activity, " +
+		"COALESCE(poll_id, ''), COALESCE(schedule_id, '00000000-0000-0000-0000-000000000000'), " +
+		"COALESCE(teams, '[]'), COALESCE(together, '[]'), COALESCE(results, '[]'), COALESCE(rated, false), " +
+		"COALESCE(bracket, '{}') " +
 		"FROM %s "
 	sql_str = fmt.Sprintf(sql_str, rep.TableName)
 	wheresql := ""
@@ -156,6 +198,12 @@ func (rep *VolleyPgRepository) GetByFilter(filter volley.Volley, oredered bool,
 	if !filter.EndTime.IsZero() {
 		AddWhereParam(&wheresql, &params, filter.EndTime, "start_time <=")
 	}
+	if filter.PollId != "" {
+		AddWhereParam(&wheresql, &params, filter.PollId, "poll_id =")
+	}
+	if filter.ScheduleId != uuid.Nil {
+		AddWhereParam(&wheresql, &params, filter.ScheduleId, "schedule_id =")
+	}
 	if oredered {
 		AddWhereParam(&wheresql, &params, oredered, "ordered =")
 	}
@@ -166,7 +214,7 @@ func (rep *VolleyPgRepository) GetByFilter(filter volley.Volley, oredered bool,
 	if sorted {
 		squery += " ORDER BY start_time"
 	}
-	rows, err := rep.dbpool.Query(context.Background(), squery, params...)
+	rows, err := rep.dbpool.Query(ctx, squery, params...)
 	if err != nil {
 		return rmap, err
 	}
@@ -176,29 +224,32 @@ func (rep *VolleyPgRepository) GetByFilter(filter volley.Volley, oredered bool,
 		res := volley.Volley{}
 		err = rows.Scan(&res.Id, &res.Person.Id, &res.StartTime, &res.EndTime, &res.Price,
 			&res.MinLevel, &res.CourtCount, &res.MaxPlayers, &res.NetType, &res.Approved, &res.Canceled,
-			&res.Description, &res.Activity)
+			&res.Description, &res.Activity, &res.PollId, &res.ScheduleId, &res.Teams, &res.Together, &res.Results, &res.Rated,
+			&res.Bracket)
 		if err != nil {
 			return
 		}
-		res.Person, _ = rep.PersonRepository.Get(res.Person.Id)
-		res.Location, _ = rep.LocationRepository.Get(res.Location.Id)
-		res.Members, err = rep.GetMembers(res.Id)
+		res.Person, _ = rep.PersonRepository.Get(ctx, res.Person.Id)
+		res.Location, _ = rep.LocationRepository.Get(ctx, res.Location.Id)
+		res.Members, err = rep.GetMembers(ctx, res.Id)
 		rmap = append(rmap, res)
 	}
 	return
 }
 
-func (rep *VolleyPgRepository) Add(r volley.Volley) (res volley.Volley, err error) {
+func (rep *VolleyPgRepository) Add(ctx context.Context, r volley.Volley) (res volley.Volley, err error) {
 	sql := "INSERT INTO %s " +
 		"(reserve_id, person_id, location_id, start_time, end_time, price, " +
-		"min_level, court_count, max_players, net_type, approved, ordered, canceled, description, activity) " +
-		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) " +
+		"min_level, court_count, max_players, net_type, approved, ordered, canceled, description, activity, poll_id, schedule_id, " +
+		"teams, together, results, rated, bracket) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22) " +
 		"RETURNING reserve_id"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	row := rep.dbpool.QueryRow(context.Background(), sql,
+	row := rep.dbpool.QueryRow(ctx, sql,
 		r.Id, r.Person.Id, r.Location.Id, r.StartTime, r.GetEndTime(), r.Price, r.MinLevel,
-		r.CourtCount, r.MaxPlayers, r.NetType, r.Approved, r.Ordered(), r.Canceled, r.Description, r.Activity)
+		r.CourtCount, r.MaxPlayers, r.NetType, r.Approved, r.Ordered(), r.Canceled, r.Description, r.Activity, r.PollId,
+		r.ScheduleId, r.Teams, r.Together, r.Results, r.Rated, r.Bracket)
 
 	var ReserveId uuid.UUID
 	err = row.Scan(&ReserveId)
@@ -206,59 +257,79 @@ func (rep *VolleyPgRepository) Add(r volley.Volley) (res volley.Volley, err erro
 	if err != nil {
 		return
 	}
-	res, err = rep.Get(ReserveId)
+	res, err = rep.Get(ctx, ReserveId)
 
 	return
 }
 
-func (rep *VolleyPgRepository) Update(r volley.Volley) (err error) {
+func (rep *VolleyPgRepository) Update(ctx context.Context, r volley.Volley) (err error) {
 	sql := "UPDATE %s SET " +
 		"person_id = $1, location_id = $2, start_time = $3, end_time = $4, " +
 		"price = $5, min_level = $6, court_count = $7, max_players = $8, net_type = $9, " +
-		"approved = $10, ordered = $11, canceled = $12, description = $13, activity = $14  " +
-		"WHERE reserve_id = $15"
+		"approved = $10, ordered = $11, canceled = $12, description = $13, activity = $14, poll_id = $15, " +
+		"schedule_id = $16, teams = $17, together = $18, results = $19, rated = $20, bracket = $21 " +
+		"WHERE reserve_id = $22"
 	sql = fmt.Sprintf(sql, rep.TableName)
 
-	rows, err := rep.dbpool.Query(context.Background(), sql,
+	tx, err := rep.dbpool.Begin(ctx)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, sql,
 		r.Person.Id, r.Location.Id, r.StartTime, r.GetEndTime(), r.Price, r.MinLevel,
-		r.CourtCount, r.MaxPlayers, r.NetType, r.Approved, r.Ordered(), r.Canceled, r.Description, r.Activity, r.Id)
+		r.CourtCount, r.MaxPlayers, r.NetType, r.Approved, r.Ordered(), r.Canceled, r.Description, r.Activity, r.PollId,
+		r.ScheduleId, r.Teams, r.Together, r.Results, r.Rated, r.Bracket, r.Id)
 	if err != nil {
 		return
 	}
-	defer rows.Close()
+	msql := "call " + rep.MembersSpName + " ($1, $2, $3, $4, $5, $6);"
+	psql := "INSERT INTO %s " +
+		"(payment_id, reserve_id, person_id, sum, currency, charge_id, provider_charge_id, payment_time) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (charge_id) DO NOTHING;"
+	psql = fmt.Sprintf(psql, rep.PaymentsTableName)
 	for _, mb := range r.Members {
-		rep.UpdateMember(r, mb)
+		if _, err = tx.Exec(ctx, msql, r.Id, mb.Id, mb.Count, mb.ArriveTime, mb.GetPaid(), mb.PromotedAt); err != nil {
+			return
+		}
+		if mb.Payment.Id == uuid.Nil {
+			continue
+		}
+		if _, err = tx.Exec(ctx, psql, mb.Payment.Id, r.Id, mb.Id, mb.Payment.Sum, mb.Payment.Currency,
+			mb.Payment.ChargeId, mb.Payment.ProviderChargeId, mb.Payment.Date); err != nil {
+			return
+		}
 	}
-	return
+	return tx.Commit(ctx)
 }
 
-func (rep *VolleyPgRepository) AddMember(r volley.Volley, mb volley.Member) (res volley.Volley, err error) {
+func (rep *VolleyPgRepository) AddMember(ctx context.Context, r volley.Volley, mb volley.Member) (res volley.Volley, err error) {
 	sql := "INSERT INTO %s (reserve_id, person_id, count, arrive_time, paid) " +
 		"VALUES ($1, $2, $3, $4, $5)"
 	sql = fmt.Sprintf(sql, rep.MembersTableName)
 
-	rows, err := rep.dbpool.Query(context.Background(), sql, r.Id, mb.Id, mb.Count, mb.ArriveTime, mb.GetPaid())
+	rows, err := rep.dbpool.Query(ctx, sql, r.Id, mb.Id, mb.Count, mb.ArriveTime, mb.GetPaid())
 	if err != nil {
 		return
 	}
 	defer rows.Close()
-	res, err = rep.Get(r.Id)
-	res.Person, _ = rep.PersonRepository.Get(res.Person.Id)
+	res, err = rep.Get(ctx, r.Id)
+	res.Person, _ = rep.PersonRepository.Get(ctx, res.Person.Id)
 	return
 }
 
-func (rep *VolleyPgRepository) UpdateMember(r volley.Volley, mb volley.Member) (res volley.Volley, err error) {
-	sql := "call " + rep.MembersSpName + " ($1, $2, $3, $4, $5);"
-	_, err = rep.dbpool.Exec(context.Background(), sql, r.Id, mb.Id, mb.Count, mb.ArriveTime, mb.GetPaid())
+func (rep *VolleyPgRepository) UpdateMember(ctx context.Context, r volley.Volley, mb volley.Member) (res volley.Volley, err error) {
+	sql := "call " + rep.MembersSpName + " ($1, $2, $3, $4, $5, $6);"
+	_, err = rep.dbpool.Exec(ctx, sql, r.Id, mb.Id, mb.Count, mb.ArriveTime, mb.GetPaid(), mb.PromotedAt)
 	return
 }
 
-func (rep *VolleyPgRepository) AddPlayer(pl volley.Player) (res volley.Player, err error) {
+func (rep *VolleyPgRepository) AddPlayer(ctx context.Context, pl volley.Player) (res volley.Player, err error) {
 	sql := "INSERT INTO %s (person_id, level) " +
 		"VALUES ($1, $2, $3)"
 	sql = fmt.Sprintf(sql, rep.PlayersTableName)
 
-	rows, err := rep.dbpool.Query(context.Background(), sql, pl.Id, pl.Level)
+	rows, err := rep.dbpool.Query(ctx, sql, pl.Id, pl.Level)
 	if err != nil {
 		return
 	}
@@ -266,9 +337,9 @@ func (rep *VolleyPgRepository) AddPlayer(pl volley.Player) (res volley.Player, e
 	return
 }
 
-func (rep *VolleyPgRepository) GetPlayer(p person.Person) (pl volley.Player, err error) {
+func (rep *VolleyPgRepository) GetPlayer(ctx context.Context, p person.Person) (pl volley.Player, err error) {
 	sql := "SELECT level FROM %s WHERE person_id = $1"
-	row := rep.dbpool.QueryRow(context.Background(), fmt.Sprintf(sql, rep.PlayersTableName), p.Id)
+	row := rep.dbpool.QueryRow(ctx, fmt.Sprintf(sql, rep.PlayersTableName), p.Id)
 	row.Scan(&pl.Level)
 	pl.Person = p
 	if err != nil {
@@ -277,9 +348,9 @@ func (rep *VolleyPgRepository) GetPlayer(p person.Person) (pl volley.Player, err
 	return
 }
 
-func (rep *VolleyPgRepository) UpdatePlayer(pl volley.Player) (err error) {
+func (rep *VolleyPgRepository) UpdatePlayer(ctx context.Context, pl volley.Player) (err error) {
 	sql := "call " + rep.PlayersSpName + " ($1, $2);"
-	_, err = rep.dbpool.Exec(context.Background(), sql, pl.Id, pl.Level)
-	rep.PersonRepository.Update(pl.Person)
+	_, err = rep.dbpool.Exec(ctx, sql, pl.Id, pl.Level)
+	rep.PersonRepository.Update(ctx, pl.Person)
 	return
 }
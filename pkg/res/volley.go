@@ -21,18 +21,28 @@ func (rl StaticVolleyResourceLoader) GetResources() (res VolleyResources) {
 	res.Resources.Config = bvbot.NewConfigResourcesRu()
 	res.Resources.Courts = bvbot.NewCourtsResourcesRu()
 	res.Resources.Description = bvbot.NewDescResourcesRu()
+	res.Resources.Inline = bvbot.NewInlineResourcesRu()
 	res.Resources.Join = bvbot.NewJoinPlayersResourcesRu()
 	res.Resources.Level = bvbot.NewLevelResourcesRu()
 	res.Resources.List = bvbot.NewListResourcesRu()
+	res.Resources.LocationChat = bvbot.NewLocationChatResourcesRu()
 	res.Resources.Main = bvbot.NewMainResourcesRu()
 	res.Resources.MaxPlayer = bvbot.NewMaxPlayersResourcesRu()
+	res.Resources.Payment = bvbot.NewPaymentResourcesRu()
+	res.Resources.Phone = bvbot.NewPhoneResourcesRu()
+	res.Resources.Poll = bvbot.NewPollResourcesRu()
 	res.Resources.Price = bvbot.NewPriceResourcesRu()
 	res.Resources.Profile = bvbot.NewProfileResourcesRu()
+	res.Resources.Promote = bvbot.NewPromoteResourcesRu()
+	res.Resources.Results = bvbot.NewResultsResourcesRu()
 	res.Resources.RemovePlayer = bvbot.RemovePlayerResourcesRu()
+	res.Resources.Schedule = bvbot.NewScheduleResourcesRu()
 	res.Resources.Settings = bvbot.NewSettingsResourcesRu()
 	res.Resources.Show = bvbot.NewShowResourcesRu()
 	res.Resources.SendResources = bvbot.NewSendResourcesRu()
 	res.Resources.Sets = bvbot.NewSetsResourcesRu()
+	res.Resources.Teams = bvbot.NewTeamsResourcesRu()
+	res.Resources.Tournament = bvbot.NewTournamentResourcesRu()
 	res.Resources.BackBtn = "Назад"
 	res.Resources.DescMessage = "Отлично. Отправьте мне в чат описание активности."
 	res.Command.Command = "volley"
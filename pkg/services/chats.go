@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
+)
+
+func (p *VolleyBotService) ProceedMyChatMember(ctx context.Context, cmu *telegram.ChatMemberUpdated) (err error) {
+	if cmu.Chat.Id >= 0 {
+		return
+	}
+	loc, err := p.GetLocation(ctx)
+	if err != nil {
+		return
+	}
+	switch {
+	case cmu.Joined():
+		if !p.IsLocationAdmin(ctx, loc, cmu.From) {
+			return
+		}
+		res := p.Resources.Resources.LocationChat
+		st := telegram.NewState()
+		st.Prefix = "res"
+		st.State = "lchat"
+		st.Action = "bind"
+		kbd := telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{
+			{{Text: res.BindBtn, CallbackData: st.String()}}}}
+		req := &telegram.MessageRequest{ChatId: cmu.Chat.Id, Text: res.OfferMessage, ReplyMarkup: kbd}
+		if _, err = p.Bot.SendMessage(ctx, req); err != nil {
+			return p.CheckSendError(ctx, req, err)
+		}
+	case cmu.Left():
+		// the cards can't be edited anymore, so their states are removed too
+		if err = p.StateRepository.ClearChat(ctx, cmu.Chat.Id); err != nil {
+			return
+		}
+		if loc.ChatId == cmu.Chat.Id {
+			loc.ChatId = 0
+			err = p.LocationRepository.Update(ctx, loc)
+		}
+	}
+	return
+}
+
+func (p *VolleyBotService) ProceedChatMember(ctx context.Context, cmu *telegram.ChatMemberUpdated) (err error) {
+	if (!cmu.Left() && !cmu.Joined()) || cmu.NewChatMember.User == nil {
+		return
+	}
+	loc, err := p.GetLocation(ctx)
+	if err != nil || loc.ChatId != cmu.Chat.Id {
+		return
+	}
+	val := "on"
+	if cmu.Left() {
+		val = "off"
+	}
+	if err := p.SetLocationNotifications(ctx, cmu.NewChatMember.User.Id, loc, val); err != nil {
+		log.Println(err.Error())
+	}
+	return
+}
+
+// SetLocationNotifications turns notifications about games of the location on or off,
+// other settings of the person are kept.
+func (p *VolleyBotService) SetLocationNotifications(ctx context.Context, tid int, loc location.Location, val string) (err error) {
+	pers, err := p.PersonRepository.GetByTelegramId(ctx, tid)
+	if err != nil {
+		return
+	}
+	param := person.LocationNotifyParam(loc.Id)
+	if pers.Settings[param] == val || (val == "on" && pers.Settings[param] == "") {
+		return
+	}
+	if pers.Settings == nil {
+		pers.Settings = make(map[string]string)
+	}
+	pers.Settings[param] = val
+	return p.PersonRepository.Update(ctx, pers)
+}
+
+func (p *VolleyBotService) ProceedLocationChat(ctx context.Context, cq *telegram.CallbackQuery, st telegram.State) (err error) {
+	res := p.Resources.Resources.LocationChat
+	loc, err := p.GetLocation(ctx)
+	if err != nil {
+		return
+	}
+	if st.Action != "bind" || cq.Message == nil || cq.Message.Chat.Id >= 0 || !p.IsLocationAdmin(ctx, loc, cq.From) {
+		_, err = cq.Answer(ctx, p.Bot, res.DeniedMessage, telegram.AnswerCallbackQueryRequest{})
+		return
+	}
+	loc.ChatId = cq.Message.Chat.Id
+	if err = p.LocationRepository.Update(ctx, loc); err != nil {
+		return
+	}
+	req := &telegram.EditMessageTextRequest{ChatId: loc.ChatId, MessageId: cq.Message.MessageId, Text: res.BoundMessage}
+	if _, err = p.Bot.SendMessage(ctx, req); err != nil {
+		if err = p.CheckSendError(ctx, req, err); err != nil {
+			log.Println(err.Error())
+		}
+	}
+	if _, err = cq.Answer(ctx, p.Bot, "Ok", telegram.AnswerCallbackQueryRequest{}); err != nil {
+		log.Println(err.Error())
+	}
+	return nil
+}
+
+func (p *VolleyBotService) IsLocationAdmin(ctx context.Context, loc location.Location, user *telegram.User) bool {
+	if user == nil {
+		return false
+	}
+	pers, err := p.PersonRepository.GetByTelegramId(ctx, user.Id)
+	return err == nil && pers.CheckLocationRole(loc, "admin")
+}
+
+// ProceedChannelPost shows the cards of the games announced by join links in the location channel.
+func (p *VolleyBotService) ProceedChannelPost(ctx context.Context, msg *telegram.Message) (err error) {
+	if msg.Chat == nil {
+		return
+	}
+	loc, err := p.GetLocation(ctx)
+	if err != nil || loc.ChatId != msg.Chat.Id {
+		return
+	}
+	for _, id := range JoinLinks(*msg) {
+		v, err := p.VolleyRepository.Get(ctx, id)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		st := telegram.NewState()
+		st.Prefix = "res"
+		st.State = "show"
+		st.Action = "refresh"
+		st.Data = v.Base64Id()
+		st.ChatId = msg.Chat.Id
+		if p.HasCard(ctx, st) {
+			continue
+		}
+		from := telegram.User{Id: v.Person.TelegramId, FirstName: v.Person.Firstname}
+		p.LogErrors(p.Proceed(ctx, from.Id, st, telegram.Message{Chat: msg.Chat, From: &from}))
+	}
+	return nil
+}
+
+// HasCard checks whether the chat already has a message of the reserve.
+func (p *VolleyBotService) HasCard(ctx context.Context, st telegram.State) bool {
+	slist, _ := p.StateRepository.GetByData(ctx, st.Data)
+	for _, s := range slist {
+		if s.ChatId == st.ChatId && s.MessageId > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// JoinLinks returns ids of the reserves from the join deep links of the message.
+func JoinLinks(msg telegram.Message) (ids []uuid.UUID) {
+	links := []string{msg.Text, msg.Caption}
+	for _, e := range msg.Entities {
+		links = append(links, e.Url)
+	}
+	found := map[uuid.UUID]bool{}
+	for _, link := range links {
+		for _, part := range strings.Split(link, "start=join_")[1:] {
+			if len(part) < 36 {
+				continue
+			}
+			if id, err := uuid.Parse(part[:36]); err == nil && !found[id] {
+				found[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// gameLocks serializes changes of a game. Handlers get the whole game, change
+// it and update it back, so concurrent changes of one game would be lost.
+type gameLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*gameLock
+}
+
+type gameLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock locks the game and returns the function unlocking it. Locks are
+// removed when no one holds or waits for them.
+func (l *gameLocks) Lock(id uuid.UUID) (unlock func()) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[uuid.UUID]*gameLock)
+	}
+	gl, ok := l.locks[id]
+	if !ok {
+		gl = &gameLock{}
+		l.locks[id] = gl
+	}
+	gl.refs++
+	l.mu.Unlock()
+
+	gl.Lock()
+	return func() {
+		gl.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if gl.refs--; gl.refs == 0 {
+			delete(l.locks, id)
+		}
+	}
+}
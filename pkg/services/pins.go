@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+)
+
+const PinnedGamesLookback = 7 * 24 * time.Hour
+
+func (s *VolleyBotService) UnpinFinished(ctx context.Context, now time.Time) (err error) {
+	loc, err := s.GetLocation(ctx)
+	if err != nil || loc.ChatId == 0 {
+		return
+	}
+	filter := volley.Volley{}
+	filter.StartTime = now.Add(-PinnedGamesLookback)
+	filter.EndTime = now
+	vlist, err := s.VolleyRepository.GetByFilter(ctx, filter, false, false)
+	if err != nil {
+		return
+	}
+	for _, v := range vlist {
+		if v.GetEndTime().After(now) {
+			continue
+		}
+		slist, _ := s.StateRepository.GetByData(ctx, v.Base64Id())
+		for _, st := range slist {
+			if st.State != "show" || st.ChatId != loc.ChatId || st.MessageId <= 0 {
+				continue
+			}
+			if _, uerr := s.Bot.SendMessage(ctx, telegram.UnpinChatMessageRequest{ChatId: st.ChatId, MessageId: st.MessageId}); uerr != nil {
+				log.Println(uerr.Error())
+				err = uerr
+			}
+			s.StateRepository.Clear(ctx, st)
+		}
+	}
+	return
+}
+
+// UnpinCards unpins the older cards of the reserve in the chat of the state, the new card replaces them.
+func (s *VolleyBotService) UnpinCards(ctx context.Context, st telegram.State) (err error) {
+	slist, err := s.StateRepository.GetByData(ctx, st.Data)
+	if err != nil {
+		return
+	}
+	for _, old := range slist {
+		if old.State != "show" || old.ChatId != st.ChatId || old.MessageId <= 0 || old.MessageId == st.MessageId {
+			continue
+		}
+		if _, uerr := s.Bot.SendMessage(ctx, telegram.UnpinChatMessageRequest{ChatId: old.ChatId, MessageId: old.MessageId}); uerr != nil {
+			log.Println(uerr.Error())
+			err = uerr
+		}
+		s.StateRepository.Clear(ctx, old)
+	}
+	return
+}
+
+func (s *VolleyBotService) RunUnpinner(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.UnpinFinished(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+)
+
+// ProceedPoll unbinds the closed poll from the reserve, so the answers of the poll don't change the roster.
+func (p *VolleyBotService) ProceedPoll(ctx context.Context, poll *telegram.Poll) (err error) {
+	if !poll.IsClosed {
+		return
+	}
+	vlist, err := p.VolleyRepository.GetByFilter(ctx, volley.Volley{PollId: poll.Id}, false, false)
+	if err != nil {
+		return
+	}
+	for _, v := range vlist {
+		if err = p.unbindPoll(ctx, v, poll.Id); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (p *VolleyBotService) unbindPoll(ctx context.Context, v volley.Volley, pollId string) (err error) {
+	defer p.LockGame(v.Base64Id())()
+	if v, err = p.VolleyRepository.Get(ctx, v.Id); err != nil || v.PollId != pollId {
+		return
+	}
+	v.PollId = ""
+	return p.VolleyRepository.Update(ctx, v)
+}
+
+func (p *VolleyBotService) ProceedPollAnswer(ctx context.Context, pa *telegram.PollAnswer) (err error) {
+	if pa.User == nil {
+		return
+	}
+	vlist, err := p.VolleyRepository.GetByFilter(ctx, volley.Volley{PollId: pa.PollId}, false, false)
+	if err != nil || len(vlist) == 0 {
+		return
+	}
+	st := telegram.NewState()
+	st.Prefix = "res"
+	st.State = "poll"
+	st.Action = "vote"
+	st.Data = vlist[0].Base64Id()
+	st.ChatId = pa.User.Id
+	if !pa.Retracted() {
+		st.Value = strconv.Itoa(pa.OptionIds[0])
+	}
+	p.LogErrors(p.Proceed(ctx, pa.User.Id, st, telegram.Message{From: pa.User}))
+	return
+}
+
+func (s *VolleyBotService) BindPoll(ctx context.Context, st telegram.State, poll *telegram.Poll) (err error) {
+	id, err := volley.Volley{}.IdFromBase64(st.Data)
+	if err != nil {
+		return
+	}
+	defer s.LockGame(st.Data)()
+	v, err := s.VolleyRepository.Get(ctx, id)
+	if err != nil {
+		return
+	}
+	if v.PollId != "" && v.PollId != poll.Id {
+		log.Printf("poll %s replaces poll %s for reserve %s", poll.Id, v.PollId, v.Id)
+	}
+	v.PollId = poll.Id
+	return s.VolleyRepository.Update(ctx, v)
+}
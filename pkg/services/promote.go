@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/telegram"
+)
+
+// NotifyPromoted sends a personal confirmation request to every member moved up
+// from the reserve who hasn't got one yet.
+func (s *VolleyBotService) NotifyPromoted(ctx context.Context, sta telegram.State) (errs []error) {
+	id, err := volley.Volley{}.IdFromBase64(sta.Data)
+	if err != nil {
+		return
+	}
+	v, err := s.VolleyRepository.Get(ctx, id)
+	if err != nil || v.Canceled {
+		return
+	}
+	notified := map[int]bool{}
+	slist, _ := s.StateRepository.GetByData(ctx, sta.Data)
+	for _, st := range slist {
+		if st.State == "promo" {
+			notified[st.ChatId] = true
+		}
+	}
+	for _, mb := range v.Members {
+		if !mb.Pending() || mb.TelegramId == 0 || notified[mb.TelegramId] {
+			continue
+		}
+		st := telegram.NewState()
+		st.Prefix = "res"
+		st.State = "promo"
+		st.Action = "promo"
+		st.Data = sta.Data
+		st.ChatId = mb.TelegramId
+		msg := telegram.Message{From: &telegram.User{Id: mb.TelegramId, FirstName: mb.Firstname}}
+		bld, err := s.GetStateBuilder(ctx, mb.TelegramId, st, msg)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sp, err := bld.GetStateProvider(st)
+		if sp == nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, s.SendRequests(ctx, sp.GetRequests())...)
+	}
+	return
+}
+
+// ReleaseExpired frees seats of promoted members who didn't confirm them in time
+// and notifies the next ones from the reserve.
+func (s *VolleyBotService) ReleaseExpired(ctx context.Context, now time.Time) (err error) {
+	filter := volley.Volley{}
+	filter.StartTime = now
+	vlist, err := s.VolleyRepository.GetByFilter(ctx, filter, true, false)
+	if err != nil {
+		return
+	}
+	deadline := now.Add(-s.Resources.Resources.Promote.Window)
+	for _, v := range vlist {
+		released, rerr := s.releaseExpired(ctx, v, deadline)
+		if rerr != nil {
+			log.Println(rerr.Error())
+			continue
+		}
+		if !released {
+			continue
+		}
+		st := telegram.NewState()
+		st.Prefix = "res"
+		st.State = "show"
+		st.Action = "show"
+		st.Data = v.Base64Id()
+		msg := telegram.Message{From: &telegram.User{Id: v.Person.TelegramId, FirstName: v.Person.Firstname}}
+		bld, berr := s.GetStateBuilder(ctx, v.Person.TelegramId, st, msg)
+		if berr != nil {
+			log.Println(berr.Error())
+			continue
+		}
+		s.UpdateMessages(ctx, st, bld)
+		s.LogErrors(s.NotifyPromoted(ctx, st))
+	}
+	return
+}
+
+// releaseExpired releases expired members of the game got again under its lock,
+// so members joined or confirmed since the game was listed are kept.
+func (s *VolleyBotService) releaseExpired(ctx context.Context, v volley.Volley, deadline time.Time) (released bool, err error) {
+	defer s.LockGame(v.Base64Id())()
+	if v, err = s.VolleyRepository.Get(ctx, v.Id); err != nil || v.Canceled {
+		return
+	}
+	if mlist, _ := v.ReleaseExpired(deadline); len(mlist) == 0 {
+		return
+	}
+	return true, s.VolleyRepository.Update(ctx, v)
+}
+
+func (s *VolleyBotService) RunReleaser(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.ReleaseExpired(ctx, now)
+		}
+	}
+}
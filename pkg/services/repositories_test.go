@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+
+	"github.com/google/uuid"
+)
+
+var errNotFound = errors.New("not found")
+
+type locationRepositoryMock struct {
+	mu        sync.Mutex
+	locations map[uuid.UUID]location.Location
+}
+
+func (rep *locationRepositoryMock) Get(ctx context.Context, id uuid.UUID) (location.Location, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if l, ok := rep.locations[id]; ok {
+		return l, nil
+	}
+	return location.Location{}, errNotFound
+}
+
+func (rep *locationRepositoryMock) GetByName(ctx context.Context, name string) (location.Location, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, l := range rep.locations {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return location.Location{}, errNotFound
+}
+
+func (rep *locationRepositoryMock) Add(ctx context.Context, l location.Location) (location.Location, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.locations == nil {
+		rep.locations = make(map[uuid.UUID]location.Location)
+	}
+	rep.locations[l.Id] = l
+	return l, nil
+}
+
+func (rep *locationRepositoryMock) Update(ctx context.Context, l location.Location) error {
+	_, err := rep.Add(ctx, l)
+	return err
+}
+
+type configRepositoryMock struct {
+	mu      sync.Mutex
+	configs map[string][]byte
+}
+
+func (rep *configRepositoryMock) Add(ctx context.Context, loc location.Location, service string, config interface{}) error {
+	return rep.Update(ctx, loc, service, config)
+}
+
+func (rep *configRepositoryMock) Get(ctx context.Context, loc location.Location, service string, config interface{}) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	data, ok := rep.configs[loc.Id.String()+service]
+	if !ok {
+		return errNotFound
+	}
+	return json.Unmarshal(data, config)
+}
+
+func (rep *configRepositoryMock) Update(ctx context.Context, loc location.Location, service string, config interface{}) (err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.configs == nil {
+		rep.configs = make(map[string][]byte)
+	}
+	rep.configs[loc.Id.String()+service], err = json.Marshal(config)
+	return
+}
+
+type personRepositoryMock struct {
+	mu      sync.Mutex
+	persons map[uuid.UUID]person.Person
+}
+
+func (rep *personRepositoryMock) Get(ctx context.Context, id uuid.UUID) (person.Person, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if p, ok := rep.persons[id]; ok {
+		return p, nil
+	}
+	return person.Person{}, errNotFound
+}
+
+func (rep *personRepositoryMock) GetByTelegramId(ctx context.Context, tid int) (person.Person, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, p := range rep.persons {
+		if p.TelegramId == tid {
+			return p, nil
+		}
+	}
+	return person.Person{}, errNotFound
+}
+
+func (rep *personRepositoryMock) Add(ctx context.Context, p person.Person) (person.Person, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.persons == nil {
+		rep.persons = make(map[uuid.UUID]person.Person)
+	}
+	rep.persons[p.Id] = p
+	return p, nil
+}
+
+func (rep *personRepositoryMock) Update(ctx context.Context, p person.Person) error {
+	_, err := rep.Add(ctx, p)
+	return err
+}
+
+type volleyRepositoryMock struct {
+	mu      sync.Mutex
+	volleys map[uuid.UUID]volley.Volley
+	players map[uuid.UUID]volley.Player
+	addErr  func(v volley.Volley) error
+	onSave  func(v volley.Volley)
+}
+
+func (rep *volleyRepositoryMock) Add(ctx context.Context, v volley.Volley) (volley.Volley, error) {
+	if rep.addErr != nil {
+		if err := rep.addErr(v); err != nil {
+			return v, err
+		}
+	}
+	return v, rep.Update(ctx, v)
+}
+
+func (rep *volleyRepositoryMock) AddMember(ctx context.Context, v volley.Volley, mb volley.Member) (volley.Volley, error) {
+	return rep.UpdateMember(ctx, v, mb)
+}
+
+func (rep *volleyRepositoryMock) AddPlayer(ctx context.Context, pl volley.Player) (volley.Player, error) {
+	return pl, rep.UpdatePlayer(ctx, pl)
+}
+
+func (rep *volleyRepositoryMock) Get(ctx context.Context, id uuid.UUID) (volley.Volley, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	v, ok := rep.volleys[id]
+	if !ok {
+		return volley.Volley{}, errNotFound
+	}
+	v.Members = append([]volley.Member{}, v.Members...)
+	return v, nil
+}
+
+func (rep *volleyRepositoryMock) GetByFilter(ctx context.Context, filter volley.Volley, ordered bool, sorted bool) (vlist []volley.Volley, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, v := range rep.volleys {
+		if !filter.StartTime.IsZero() && v.StartTime.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && !v.StartTime.Before(filter.EndTime) {
+			continue
+		}
+		if filter.PollId != "" && v.PollId != filter.PollId {
+			continue
+		}
+		if filter.ScheduleId != uuid.Nil && v.ScheduleId != filter.ScheduleId {
+			continue
+		}
+		v.Members = append([]volley.Member{}, v.Members...)
+		vlist = append(vlist, v)
+	}
+	return
+}
+
+func (rep *volleyRepositoryMock) GetPlayer(ctx context.Context, p person.Person) (volley.Player, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	pl := rep.players[p.Id]
+	pl.Person = p
+	return pl, nil
+}
+
+func (rep *volleyRepositoryMock) UpdateMember(ctx context.Context, v volley.Volley, mb volley.Member) (volley.Volley, error) {
+	stored, err := rep.Get(ctx, v.Id)
+	if err != nil {
+		return stored, err
+	}
+	stored.JoinPlayer(mb)
+	return stored, rep.Update(ctx, stored)
+}
+
+func (rep *volleyRepositoryMock) UpdatePlayer(ctx context.Context, pl volley.Player) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.players == nil {
+		rep.players = make(map[uuid.UUID]volley.Player)
+	}
+	rep.players[pl.Id] = pl
+	return nil
+}
+
+func (rep *volleyRepositoryMock) Update(ctx context.Context, v volley.Volley) error {
+	if rep.onSave != nil {
+		rep.onSave(v)
+	}
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.volleys == nil {
+		rep.volleys = make(map[uuid.UUID]volley.Volley)
+	}
+	v.Members = append([]volley.Member{}, v.Members...)
+	rep.volleys[v.Id] = v
+	return nil
+}
+
+type scheduleRepositoryMock struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]volley.Schedule
+}
+
+func (rep *scheduleRepositoryMock) Add(ctx context.Context, s volley.Schedule) (volley.Schedule, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.schedules == nil {
+		rep.schedules = make(map[uuid.UUID]volley.Schedule)
+	}
+	rep.schedules[s.Id] = s
+	return s, nil
+}
+
+func (rep *scheduleRepositoryMock) Get(ctx context.Context, id uuid.UUID) (volley.Schedule, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if s, ok := rep.schedules[id]; ok {
+		return s, nil
+	}
+	return volley.Schedule{}, volley.ErrScheduleNotFound
+}
+
+func (rep *scheduleRepositoryMock) GetByLocation(ctx context.Context, loc location.Location) (slist []volley.Schedule, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, s := range rep.schedules {
+		if s.Template.Location.Id == loc.Id {
+			slist = append(slist, s)
+		}
+	}
+	return
+}
+
+func (rep *scheduleRepositoryMock) Update(ctx context.Context, s volley.Schedule) error {
+	_, err := rep.Add(ctx, s)
+	return err
+}
+
+func (rep *scheduleRepositoryMock) Delete(ctx context.Context, id uuid.UUID) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	delete(rep.schedules, id)
+	return nil
+}
+
+type ratingRepositoryMock struct {
+	mu      sync.Mutex
+	ratings map[uuid.UUID]volley.Rating
+	history []volley.RatingChange
+	getErr  error
+	applies int
+}
+
+func (rep *ratingRepositoryMock) Get(ctx context.Context, loc location.Location, pid uuid.UUID) (volley.Rating, error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	if rep.getErr != nil {
+		return volley.Rating{}, rep.getErr
+	}
+	if r, ok := rep.ratings[pid]; ok && r.LocationId == loc.Id {
+		return r, nil
+	}
+	return volley.Rating{}, volley.ErrRatingNotFound
+}
+
+func (rep *ratingRepositoryMock) GetHistory(ctx context.Context, loc location.Location, pid uuid.UUID) (clist []volley.RatingChange, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, c := range rep.history {
+		if c.PersonId == pid && c.LocationId == loc.Id {
+			clist = append(clist, c)
+		}
+	}
+	return
+}
+
+func (rep *ratingRepositoryMock) Apply(ctx context.Context, changes []volley.RatingChange) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	rep.applies++
+	if rep.ratings == nil {
+		rep.ratings = make(map[uuid.UUID]volley.Rating)
+	}
+	for _, c := range changes {
+		if rep.applied(c) {
+			continue
+		}
+		rep.ratings[c.PersonId] = volley.Rating{PersonId: c.PersonId, LocationId: c.LocationId, Value: c.After, Games: c.Games}
+		rep.history = append(rep.history, c)
+	}
+	return nil
+}
+
+func (rep *ratingRepositoryMock) applied(c volley.RatingChange) bool {
+	for _, h := range rep.history {
+		if h.PersonId == c.PersonId && h.ReserveId == c.ReserveId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+	"volleybot/pkg/domain/volley"
+)
+
+// GenerateScheduled creates games of not paused schedules of the location
+// for the configured number of days ahead.
+func (s *VolleyBotService) GenerateScheduled(ctx context.Context, now time.Time) (err error) {
+	if s.ScheduleRepository == nil {
+		return
+	}
+	loc, err := s.GetLocation(ctx)
+	if err != nil {
+		return
+	}
+	slist, err := s.ScheduleRepository.GetByLocation(ctx, loc)
+	if err != nil {
+		return
+	}
+	horizon := now.AddDate(0, 0, s.Resources.Resources.Schedule.DaysAhead)
+	for _, sch := range slist {
+		if serr := s.generateSchedule(ctx, sch, now, horizon); serr != nil {
+			log.Println(serr.Error())
+			if err == nil {
+				err = serr
+			}
+		}
+	}
+	return
+}
+
+// generateSchedule adds the games past the generation mark of the schedule,
+// the mark is not moved past the game which failed to be added.
+func (s *VolleyBotService) generateSchedule(ctx context.Context, sch volley.Schedule, now, horizon time.Time) (err error) {
+	filter := volley.Volley{ScheduleId: sch.Id}
+	filter.StartTime = now
+	existing, err := s.VolleyRepository.GetByFilter(ctx, filter, false, false)
+	if err != nil {
+		return
+	}
+	mark := sch.Generated
+	vlist := sch.Generate(now, horizon, existing)
+	if sch.Generated.Equal(mark) {
+		return
+	}
+	for _, v := range vlist {
+		if _, err = s.VolleyRepository.Add(ctx, v); err != nil {
+			sch.Generated = mark
+			break
+		}
+		mark = v.StartTime
+	}
+	if uerr := s.ScheduleRepository.Update(ctx, sch); uerr != nil && err == nil {
+		err = uerr
+	}
+	return
+}
+
+func (s *VolleyBotService) RunScheduler(ctx context.Context, period time.Duration) {
+	s.GenerateScheduled(ctx, time.Now())
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.GenerateScheduled(ctx, now)
+		}
+	}
+}
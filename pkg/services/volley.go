@@ -1,21 +1,29 @@
 package services
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"sort"
+	"strconv"
 	"volleybot/pkg/bvbot"
 	"volleybot/pkg/domain/location"
 	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/reserve"
 	"volleybot/pkg/domain/volley"
 	"volleybot/pkg/res"
 	"volleybot/pkg/telegram"
+
+	"github.com/google/uuid"
 )
 
 func NewVolleyBotService(tb telegram.Bot, vres *res.VolleyResources, strep telegram.StateRepository,
-	lrep location.LocationRepository, rrep volley.Repository, prep person.PersonRepository, confrep location.LocationConfigRepository) VolleyBotService {
+	lrep location.LocationRepository, rrep volley.Repository, prep person.PersonRepository, confrep location.LocationConfigRepository,
+	schrep volley.ScheduleRepository, rtrep volley.RatingRepository) VolleyBotService {
 
 	s := VolleyBotService{Bot: tb, Resources: vres, StateRepository: strep, LocationRepository: lrep, VolleyRepository: rrep,
-		PersonRepository: prep, ConfigRepository: confrep}
+		PersonRepository: prep, ConfigRepository: confrep, ScheduleRepository: schrep,
+		RatingRepository: rtrep, games: &gameLocks{}}
 	return s
 }
 
@@ -27,7 +35,10 @@ type VolleyBotService struct {
 	ConfigRepository   location.LocationConfigRepository
 	PersonRepository   person.PersonRepository
 	VolleyRepository   volley.Repository
+	ScheduleRepository volley.ScheduleRepository
+	RatingRepository   volley.RatingRepository
 	StateRepository    telegram.StateRepository
+	games              *gameLocks
 }
 
 func (s VolleyBotService) LogErrors(errs []error) {
@@ -43,43 +54,135 @@ func (s *VolleyBotService) GetCommands(tuser int) (cmds []telegram.BotCommand) {
 	return cmds
 }
 
-func (s *VolleyBotService) GetLocation() (l location.Location, err error) {
-	l, err = s.LocationRepository.GetByName(s.Resources.Location.Name)
+func (s *VolleyBotService) GetLocation(ctx context.Context) (l location.Location, err error) {
+	l, err = s.LocationRepository.GetByName(ctx, s.Resources.Location.Name)
 	if err != nil {
 		log.Println(err.Error())
 		l, _ = location.NewLocation(s.Resources.Location.Name)
-		l, err = s.LocationRepository.Add(l)
+		l, err = s.LocationRepository.Add(ctx, l)
 	}
 	return
 }
 
-func (p *VolleyBotService) ProceedCallback(cq *telegram.CallbackQuery) (err error) {
+func (p *VolleyBotService) ProceedCallback(ctx context.Context, cq *telegram.CallbackQuery) (err error) {
 	st, err := telegram.NewState().Parse(cq.Data)
-	st.MessageId = cq.Message.MessageId
-	st.ChatId = cq.Message.Chat.Id
 	if err != nil {
 		log.Println(err.Error())
 		return
 	}
-	p.LogErrors(p.Proceed(cq.From.Id, st, *cq.Message))
-	_, err = cq.Answer(p.Bot, "Ok", telegram.AnswerCallbackQueryRequest{})
+	if st.State == "lchat" {
+		return p.ProceedLocationChat(ctx, cq, st)
+	}
+	msg := telegram.Message{From: cq.From}
+	if cq.Message != nil {
+		msg = *cq.Message
+		st.MessageId = cq.Message.MessageId
+		st.ChatId = cq.Message.Chat.Id
+	} else {
+		st.InlineMessageId = cq.InlineMessageId
+	}
+	p.LogErrors(p.Proceed(ctx, cq.From.Id, st, msg))
+	// the action is applied already, so the update must not be proceeded again for a failed answer
+	if _, err = cq.Answer(ctx, p.Bot, "Ok", telegram.AnswerCallbackQueryRequest{}); err != nil {
+		log.Println(err.Error())
+	}
+	return nil
+}
+
+func (p *VolleyBotService) ProceedInlineQuery(ctx context.Context, iq *telegram.InlineQuery) (err error) {
+	st := telegram.NewState()
+	st.Prefix = "res"
+	st.State = "inline"
+	st.Action = "inline"
+	st.Value = iq.Id
+	msg := telegram.Message{From: iq.From, Text: iq.Query}
+	bld, err := p.GetStateBuilder(ctx, iq.From.Id, st, msg)
+	if err != nil {
+		return
+	}
+	sp, err := bld.GetStateProvider(st)
+	if sp == nil {
+		return
+	}
+	p.LogErrors(p.SendRequests(ctx, sp.GetRequests()))
 	return
 }
 
-func (p *VolleyBotService) ProceedMessage(msg *telegram.Message) (err error) {
-	cmd := msg.GetCommand()
-	switch cmd {
-	case "volley":
-		st := telegram.NewState()
-		st.Action = "start"
-		st.State = "main"
-		st.ChatId = msg.Chat.Id
-		st.Prefix = "res"
-		p.LogErrors(p.Proceed(msg.From.Id, st, *msg))
-		return err
+func (p *VolleyBotService) ProceedPreCheckoutQuery(ctx context.Context, pq *telegram.PreCheckoutQuery) (err error) {
+	req := telegram.AnswerPreCheckoutQueryRequest{Ok: true}
+	if err = p.CheckPayment(ctx, pq); err != nil {
+		log.Println(err.Error())
+		req = telegram.AnswerPreCheckoutQueryRequest{ErrorMessage: p.Resources.Resources.Payment.ErrorMessage}
 	}
+	_, err = pq.Answer(ctx, p.Bot, req)
+	return
+}
 
-	slist, err := p.StateRepository.Get(msg.Chat.Id)
+func (p *VolleyBotService) CheckPayment(ctx context.Context, pq *telegram.PreCheckoutQuery) (err error) {
+	st, err := telegram.NewState().Parse(pq.InvoicePayload)
+	if err != nil {
+		return
+	}
+	st.ChatId = pq.From.Id
+	msg := telegram.Message{From: pq.From}
+	bld, err := p.GetStateBuilder(ctx, pq.From.Id, st, msg)
+	if err != nil {
+		return
+	}
+	sp, err := bld.GetStateProvider(st)
+	if err != nil {
+		return
+	}
+	pp, ok := sp.(*bvbot.PaymentStateProvider)
+	if !ok {
+		return fmt.Errorf("unexpected invoice payload: %s", pq.InvoicePayload)
+	}
+	return pp.CheckPayment(pq.Currency, pq.TotalAmount)
+}
+
+func (p *VolleyBotService) ProceedJoinLink(ctx context.Context, msg *telegram.Message, payload string) (err error) {
+	id, err := uuid.Parse(payload)
+	if err != nil {
+		log.Println(err.Error())
+		return nil
+	}
+	st := telegram.NewState()
+	st.Prefix = "res"
+	st.State = "show"
+	st.Action = "refresh"
+	st.Data = reserve.Reserve{Id: id}.Base64Id()
+	st.ChatId = msg.Chat.Id
+	p.LogErrors(p.Proceed(ctx, msg.From.Id, st, *msg))
+	return
+}
+
+func (p *VolleyBotService) ProceedVolleyCommand(ctx context.Context, msg *telegram.Message, cmd telegram.Command) error {
+	st := telegram.NewState()
+	st.Action = "start"
+	st.State = "main"
+	st.ChatId = msg.Chat.Id
+	st.Prefix = "res"
+	p.LogErrors(p.Proceed(ctx, msg.From.Id, st, *msg))
+	return nil
+}
+
+func (p *VolleyBotService) ProceedMessage(ctx context.Context, msg *telegram.Message) (err error) {
+	if msg.SuccessfulPayment != nil {
+		st, err := telegram.NewState().Parse(msg.SuccessfulPayment.InvoicePayload)
+		if err != nil {
+			log.Println(err.Error())
+			return err
+		}
+		st.ChatId = msg.Chat.Id
+		st.MessageId = msg.MessageId
+		p.LogErrors(p.Proceed(ctx, msg.From.Id, st, *msg))
+		return nil
+	}
+	// commands are handled by the router and are never an answer to a waiting state
+	if msg.IsCommand() {
+		return
+	}
+	slist, err := p.StateRepository.Get(ctx, msg.Chat.Id)
 	if err != nil {
 		log.Println(err.Error())
 		return
@@ -87,38 +190,53 @@ func (p *VolleyBotService) ProceedMessage(msg *telegram.Message) (err error) {
 	if len(slist) == 0 {
 		return
 	}
-	st := slist[0]
-	stmsg := *msg
-	stmsg.MessageId = st.MessageId
-	p.LogErrors(p.Proceed(msg.From.Id, st, stmsg))
+	// The message keeps its own id: waiting states have no message and the providers bind the text by its id.
+	p.LogErrors(p.Proceed(ctx, msg.From.Id, slist[0], *msg))
+	return
+}
+
+func (p *VolleyBotService) ProceedEditedMessage(ctx context.Context, msg *telegram.Message) (err error) {
+	if msg.From == nil {
+		return
+	}
+	st, err := p.StateRepository.GetByMessage(ctx, *msg)
+	if err != nil || st.State == "" {
+		return
+	}
+	p.LogErrors(p.Proceed(ctx, msg.From.Id, st, *msg))
 	return
 }
 
-func (p *VolleyBotService) Proceed(tid int, st telegram.State, msg telegram.Message) (errs []error) {
+func (p *VolleyBotService) Proceed(ctx context.Context, tid int, st telegram.State, msg telegram.Message) (errs []error) {
 	var (
 		err      error
 		reqlist  []telegram.StateRequest
 		sp       telegram.StateProvider
 		newstate telegram.State
 	)
-	bld, err := p.GetStateBuilder(tid, st, msg)
+	// the game is got by the builder and updated by the provider
+	unlock := p.LockGame(st.Data)
+	bld, err := p.GetStateBuilder(ctx, tid, st, msg)
 	if err != nil {
+		unlock()
 		return append(errs, err)
 	}
-
 	if sp, err = bld.GetStateProvider(st); sp == nil {
+		unlock()
 		return append(errs, err)
 	}
-	if newstate, err = sp.Proceed(); sp == nil {
+	newstate, err = sp.Proceed()
+	unlock()
+	if sp == nil {
 		return append(errs, err)
 	}
 	// Adding incoming state requests
 	reqlist = append(reqlist, sp.GetRequests()...)
-	errs = append(errs, p.SendRequests(reqlist)...)
+	errs = append(errs, p.SendRequests(ctx, reqlist)...)
 	reqlist = []telegram.StateRequest{}
 
 	// Adding result state requests
-	bld, err = p.GetStateBuilder(tid, newstate, msg)
+	bld, err = p.GetStateBuilder(ctx, tid, newstate, msg)
 	if err != nil {
 		return append(errs, err)
 	}
@@ -128,95 +246,220 @@ func (p *VolleyBotService) Proceed(tid int, st telegram.State, msg telegram.Mess
 		reqlist = append(reqlist, sp.GetRequests()...)
 	}
 
-	errs = append(errs, p.SendRequests(reqlist)...)
+	errs = append(errs, p.SendRequests(ctx, reqlist)...)
 
 	if newstate.Updated {
-		p.UpdateMessages(newstate, bld)
+		p.UpdateMessages(ctx, newstate, bld)
+		errs = append(errs, p.NotifyPromoted(ctx, newstate)...)
 	}
 
 	return
 }
 
-func (s *VolleyBotService) SendRequests(reqlist []telegram.StateRequest) (errs []error) {
+// LockGame locks the game of the state data until the returned function is called.
+func (s *VolleyBotService) LockGame(data string) (unlock func()) {
+	id, err := volley.Volley{}.IdFromBase64(data)
+	if err != nil || data == "" || s.games == nil {
+		return func() {}
+	}
+	return s.games.Lock(id)
+}
+
+func (s *VolleyBotService) SendRequests(ctx context.Context, reqlist []telegram.StateRequest) (errs []error) {
 	var err error
 	for _, req := range reqlist {
 		if req.Clear {
-			if err = s.StateRepository.Clear(req.State); err != nil {
+			if err = s.StateRepository.Clear(ctx, req.State); err != nil {
 				errs = append(errs, err)
 			}
 		}
 		if req.Request == nil {
+			if !req.Clear && req.State.State != "" {
+				if err = s.ReplaceState(ctx, req.State); err != nil {
+					errs = append(errs, err)
+				}
+			}
 			continue
 		}
 		var resp *telegram.MessageResponse
-		if resp, err = s.Bot.SendMessage(req.Request); err != nil {
-			errs = append(errs, err)
+		if resp, err = s.Bot.SendMessage(ctx, req.Request); err != nil {
+			if err = s.CheckSendError(ctx, req.Request, err); err != nil {
+				errs = append(errs, err)
+			}
 			continue
 		}
+		if resp.Result.Chat == nil && req.State.InlineMessageId != "" && req.State.State != "" {
+			// edits of inline messages return no message, their states are kept to refresh the cards
+			if err = s.StateRepository.Set(ctx, req.State); err != nil {
+				errs = append(errs, err)
+			}
+		}
 		if resp.Result.Chat != nil {
 			if req.State.MessageId >= 0 {
 				req.State.MessageId = resp.Result.MessageId
 			}
 			req.State.ChatId = resp.Result.Chat.Id
-			if err = s.StateRepository.Set(req.State); err != nil {
+			if err = s.StateRepository.Set(ctx, req.State); err != nil {
 				errs = append(errs, err)
 			}
+			if resp.Result.Poll != nil {
+				if err = s.BindPoll(ctx, req.State, resp.Result.Poll); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if req.Pin {
+				if err = s.UnpinCards(ctx, req.State); err != nil {
+					errs = append(errs, err)
+				}
+				if _, err = s.Bot.SendMessage(ctx, telegram.PinChatMessageRequest{ChatId: resp.Result.Chat.Id,
+					MessageId: resp.Result.MessageId, DisableNotification: true}); err != nil {
+					errs = append(errs, err)
+				}
+			}
 		}
 	}
 	return
 }
 
-func (s *VolleyBotService) GetStateBuilder(tid int, state telegram.State, msg telegram.Message) (bld telegram.StateBuilder, err error) {
-	p, err := s.PersonRepository.GetByTelegramId(tid)
+// ReplaceState stores the state bound to the user message and clears the same states of the older messages,
+// so edits of the older messages are ignored.
+func (s *VolleyBotService) ReplaceState(ctx context.Context, st telegram.State) error {
+	slist, err := s.StateRepository.GetByData(ctx, st.Data)
+	if err != nil {
+		return err
+	}
+	for _, old := range slist {
+		if old.ChatId != st.ChatId || old.MessageId == st.MessageId || old.Prefix != st.Prefix ||
+			old.State != st.State || old.Action != st.Action {
+			continue
+		}
+		if err = s.StateRepository.Clear(ctx, old); err != nil {
+			return err
+		}
+	}
+	return s.StateRepository.Set(ctx, st)
+}
+
+func (s *VolleyBotService) GetStateBuilder(ctx context.Context, tid int, state telegram.State, msg telegram.Message) (bld telegram.StateBuilder, err error) {
+	p, err := s.PersonRepository.GetByTelegramId(ctx, tid)
 	if err != nil {
 		p = person.NewPerson(msg.From.FirstName)
 		p.TelegramId = msg.From.Id
 		p.Lastname = msg.From.LastName
-		if p, err = s.PersonRepository.Add(p); err != nil {
+		if p, err = s.PersonRepository.Add(ctx, p); err != nil {
 			return
 		}
 	}
-	loc, err := s.GetLocation()
+	loc, err := s.GetLocation(ctx)
 	if err != nil {
 		return
 	}
-	return bvbot.NewBvStateBuilder(loc, msg, p, s.VolleyRepository, s.Resources.Resources, s.ConfigRepository, state)
+	vbld, err := bvbot.NewBvStateBuilder(ctx, loc, msg, p, s.VolleyRepository, s.Resources.Resources, s.ConfigRepository, state)
+	vbld.ScheduleRepository = s.ScheduleRepository
+	vbld.RatingRepository = s.RatingRepository
+	vbld.LockGame = s.LockGame
+	return vbld, err
 }
 
-func (p *VolleyBotService) UpdateMessages(sta telegram.State, bld telegram.StateBuilder) {
-	slist, _ := p.StateRepository.GetByData(sta.Data)
+func (p *VolleyBotService) UpdateMessages(ctx context.Context, sta telegram.State, bld telegram.StateBuilder) {
+	slist, _ := p.StateRepository.GetByData(ctx, sta.Data)
 	sort.Slice(slist, func(i, j int) bool {
 		return slist[i].MessageId > slist[j].MessageId
 	})
 	cid := sta.ChatId
 	mid := sta.MessageId
-	notified := map[int]bool{}
+	type target struct {
+		chat   int
+		inline string
+		state  string
+	}
+	notified := map[target]bool{}
 	for _, st := range slist {
 		var (
 			reqlist []telegram.StateRequest
 			sp      telegram.StateProvider
 		)
-		if st.ChatId == cid && (st.MessageId == mid || st.ChatId < 0) {
+		if st.InlineMessageId != "" {
+			if st.InlineMessageId == sta.InlineMessageId {
+				continue
+			}
+		} else if st.ChatId == cid && (st.MessageId == mid || st.ChatId < 0) {
 			continue
 		}
 
 		if st.ChatId < 0 {
-			p.StateRepository.Clear(st)
+			p.StateRepository.Clear(ctx, st)
 		}
-		if notified[st.ChatId] {
+		tg := target{st.ChatId, st.InlineMessageId, st.State}
+		if notified[tg] {
 			continue
 		}
-		notified[st.ChatId] = true
+		notified[tg] = true
 
 		if sp, _ = bld.GetStateProvider(st); sp != nil {
 			reqlist = append(reqlist, sp.GetRequests()...)
 		}
+		blocked, cleared := false, false
 		for _, req := range reqlist {
-			resp, err := p.Bot.SendMessage(req.Request)
+			resp, err := p.Bot.SendMessage(ctx, req.Request)
 			if err == nil {
-				st.MessageId = resp.Result.MessageId
+				if resp.Result.MessageId > 0 {
+					st.MessageId = resp.Result.MessageId
+				}
+				if req.Clear {
+					p.StateRepository.Clear(ctx, st)
+					cleared = true
+				}
+				continue
+			}
+			blocked = blocked || telegram.IsBlocked(err)
+			if err = p.CheckSendError(ctx, req.Request, err); err != nil {
+				log.Println(err.Error())
 			}
 		}
-		p.StateRepository.Set(st)
+		if blocked || cleared {
+			continue
+		}
+		p.StateRepository.Set(ctx, st)
+	}
+}
+
+func (s *VolleyBotService) CheckSendError(ctx context.Context, req telegram.Request, err error) error {
+	switch {
+	case telegram.IsNotModified(err):
+		return nil
+	case telegram.IsBlocked(err):
+		if cid := getChatId(req); cid > 0 {
+			return s.DisableNotifications(ctx, cid)
+		}
+	}
+	return err
+}
+
+func (s *VolleyBotService) DisableNotifications(ctx context.Context, tid int) (err error) {
+	p, err := s.PersonRepository.GetByTelegramId(ctx, tid)
+	if err != nil {
+		return
+	}
+	if p.Settings == nil {
+		p.Settings = make(map[string]string)
+	}
+	for _, param := range person.Params {
+		p.Settings[param] = "off"
+	}
+	if err = s.PersonRepository.Update(ctx, p); err != nil {
+		return
+	}
+	slist, _ := s.StateRepository.Get(ctx, tid)
+	for _, st := range slist {
+		s.StateRepository.Clear(ctx, st)
+	}
+	return
+}
+
+func getChatId(req telegram.Request) (cid int) {
+	if val, _, err := req.GetParams(); err == nil {
+		cid, _ = strconv.Atoi(val.Get("chat_id"))
 	}
+	return
 }
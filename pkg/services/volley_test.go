@@ -0,0 +1,1351 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"volleybot/pkg/domain/location"
+	"volleybot/pkg/domain/person"
+	"volleybot/pkg/domain/volley"
+	"volleybot/pkg/res"
+	"volleybot/pkg/telegram"
+	"volleybot/pkg/telegram/telegramtest"
+
+	"github.com/google/uuid"
+)
+
+type scenario struct {
+	ctx     context.Context
+	srv     *telegramtest.Server
+	poller  telegram.SimplePoller
+	service VolleyBotService
+	lrep    *locationRepositoryMock
+	vrep    *volleyRepositoryMock
+	prep    *personRepositoryMock
+	srep    *scheduleRepositoryMock
+	rrep    *ratingRepositoryMock
+}
+
+func newScenario(t *testing.T) *scenario {
+	ctx := context.Background()
+	srv := telegramtest.NewServer()
+	t.Cleanup(srv.Close)
+	tb := srv.Bot()
+
+	vres := res.StaticVolleyResourceLoader{}.GetResources()
+	vres.Location.Name = "test"
+	s := &scenario{ctx: ctx, srv: srv, lrep: &locationRepositoryMock{}, vrep: &volleyRepositoryMock{},
+		prep: &personRepositoryMock{}, srep: &scheduleRepositoryMock{},
+		rrep: &ratingRepositoryMock{}}
+	s.lrep.Add(ctx, location.Location{Id: vres.Location.Id, Name: vres.Location.Name})
+	s.service = NewVolleyBotService(tb, &vres, telegramtest.NewStateRepository(), s.lrep, s.vrep, s.prep,
+		&configRepositoryMock{}, s.srep, s.rrep)
+
+	router := telegram.NewRouter(srv.Me.UserName)
+	router.Handle("volley", s.service.ProceedVolleyCommand)
+	router.HandleDeepLink("join", s.service.ProceedJoinLink)
+	uh := &telegram.BaseUpdateHandler{}
+	uh.AppendMessageHandlers(router, &s.service)
+	uh.AppendEditedMessageHandlers(&telegram.BaseMessageHandler{Handler: s.service.ProceedEditedMessage})
+	uh.AppendChannelPostHandlers(&telegram.BaseMessageHandler{Handler: s.service.ProceedChannelPost})
+	uh.AppendEditedChannelPostHandlers(&telegram.BaseMessageHandler{Handler: s.service.ProceedChannelPost})
+	uh.AppendCallbackHandlers(&s.service)
+	uh.AppendPollHandlers(&s.service)
+	s.poller = telegram.NewSimplePoller(tb)
+	s.poller.Timeout = 0
+	s.poller.UpdateHandlers = []telegram.UpdateHandler{uh}
+	return s
+}
+
+func (s *scenario) addPerson(name string, tid int, roles ...string) telegram.User {
+	p := person.NewPerson(name)
+	p.TelegramId = tid
+	loc, _ := s.service.GetLocation(s.ctx)
+	if len(roles) > 0 {
+		p.LocationRoles[loc.Id] = roles
+	}
+	s.prep.Add(s.ctx, p)
+	return telegram.User{Id: tid, FirstName: name}
+}
+
+func (s *scenario) proceed(t *testing.T) {
+	for s.srv.Pending() > 0 {
+		if err := s.poller.ProceedUpdates(s.ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.poller.Commit(s.ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func (s *scenario) press(t *testing.T, user telegram.User, text string) {
+	if _, err := s.srv.PressButton(user, user.Id, text); err != nil {
+		t.Fatalf("%s: %s", text, err)
+	}
+	s.proceed(t)
+}
+
+// holdSave makes the first save of a game wait until resume is called.
+func (s *scenario) holdSave() (held chan struct{}, resume func()) {
+	held, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	s.vrep.onSave = func(v volley.Volley) {
+		once.Do(func() {
+			close(held)
+			<-release
+		})
+	}
+	return held, func() { close(release) }
+}
+
+func (s *scenario) lastText(user telegram.User) string {
+	msg, _ := s.srv.LastMessage(user.Id)
+	return msg.Text
+}
+
+func (s *scenario) game(t *testing.T) (v volley.Volley) {
+	vlist, _ := s.vrep.GetByFilter(s.ctx, volley.Volley{}, false, false)
+	if len(vlist) != 1 {
+		t.Fatalf("expected one game, got %d", len(vlist))
+	}
+	return vlist[0]
+}
+
+func TestVolleyCommandScenario(t *testing.T) {
+	s := newScenario(t)
+	org := s.addPerson("Organizer", 1, "admin")
+
+	tests := []struct {
+		name string
+		text string
+		sent int
+	}{
+		{name: "Other bot", text: "/volley@OtherBot", sent: 0},
+		{name: "Text", text: "volley", sent: 0},
+		{name: "This bot", text: "/volley@" + s.srv.Me.UserName, sent: 1},
+		{name: "Command", text: "/volley", sent: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s.srv.UserMessage(org, org.Id, test.text)
+			s.proceed(t)
+			if len(s.srv.Calls("sendMessage")) != test.sent {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestCommandWhileWaitingScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	st := telegram.State{Prefix: "res", Separator: "_", State: "desc", Action: "desc", Data: game.Base64Id(),
+		ChatId: org.Id, MessageId: -1}
+	s.service.StateRepository.Set(s.ctx, st)
+
+	for _, text := range []string{"/volley", "/start", "/help@" + s.srv.Me.UserName} {
+		t.Run(text, func(t *testing.T) {
+			s.srv.UserMessage(org, org.Id, text)
+			s.proceed(t)
+			if s.game(t).Description != game.Description {
+				t.Fail()
+			}
+			slist, _ := s.service.StateRepository.Get(s.ctx, org.Id)
+			if len(slist) != 1 || slist[0] != st {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestGameScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	players := []telegram.User{
+		s.addPerson("Ivan_Petrov", 2),
+		s.addPerson("<Anna>", 3),
+		s.addPerson("Oleg", 4),
+	}
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	if !strings.Contains(s.lastText(org), "Organizer") {
+		t.Fail()
+	}
+
+	for _, pl := range players {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+	if game = s.game(t); game.PlayerCount(uuid.Nil) != 3 {
+		t.Fail()
+	}
+	orgText := s.lastText(org)
+	for _, want := range []string{"Ivan_Petrov", "<Anna>", "Oleg"} {
+		if !strings.Contains(orgText, want) {
+			t.Errorf("organizer message has no %s", want)
+		}
+	}
+
+	s.press(t, players[1], vres.Show.JoinLeaveBtn)
+	if game = s.game(t); game.PlayerCount(uuid.Nil) != 2 || game.HasPlayerByTelegramId(players[1].Id) {
+		t.Fail()
+	}
+	for _, u := range []telegram.User{org, players[0], players[1], players[2]} {
+		if strings.Contains(s.lastText(u), "<Anna>") {
+			t.Errorf("user %d still sees left player", u.Id)
+		}
+	}
+	for _, c := range s.srv.Calls("answerCallbackQuery") {
+		if c.Values.Get("text") != "Ok" {
+			t.Fail()
+		}
+	}
+}
+
+func TestPublishScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	text := s.lastText(org)
+
+	edits := len(s.srv.Calls("editMessageText"))
+	s.press(t, org, vres.Show.ActionsBtn)
+	if len(s.srv.Calls("editMessageText")) != edits || len(s.srv.Calls("editMessageReplyMarkup")) != 1 {
+		t.Error("keyboard-only change re-rendered the text")
+	}
+	if s.lastText(org) != text {
+		t.Fail()
+	}
+
+	s.press(t, org, vres.Actions.PublishBtn)
+	card, ok := s.srv.LastMessage(loc.ChatId)
+	if !ok || !strings.Contains(card.Text, "Organizer") {
+		t.FailNow()
+	}
+	if pinned := s.srv.Pinned(loc.ChatId); len(pinned) != 1 || pinned[0] != card.MessageId {
+		t.Fail()
+	}
+
+	first := card
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.PublishBtn)
+	if card, _ = s.srv.LastMessage(loc.ChatId); card.MessageId == first.MessageId {
+		t.FailNow()
+	}
+	if pinned := s.srv.Pinned(loc.ChatId); len(pinned) != 1 || pinned[0] != card.MessageId {
+		t.Error("previous card is still pinned")
+	}
+	unpins := len(s.srv.Calls("unpinChatMessage"))
+
+	if err := s.service.UnpinFinished(s.ctx, game.GetEndTime().Add(-time.Minute)); err != nil ||
+		len(s.srv.Pinned(loc.ChatId)) != 1 {
+		t.Error("game unpinned before end")
+	}
+	if err := s.service.UnpinFinished(s.ctx, game.GetEndTime().Add(time.Minute)); err != nil ||
+		len(s.srv.Pinned(loc.ChatId)) != 0 {
+		t.Error("game is still pinned after end")
+	}
+	if err := s.service.UnpinFinished(s.ctx, game.GetEndTime().Add(2*time.Minute)); err != nil ||
+		len(s.srv.Calls("unpinChatMessage")) != unpins+1 {
+		t.Error("finished game unpinned twice")
+	}
+}
+
+func TestPollScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+	guest := telegram.User{Id: 5, FirstName: "Guest"}
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.PollBtn)
+	msg, ok := s.srv.LastMessage(loc.ChatId)
+	if !ok || msg.Poll == nil || msg.Poll.IsAnonymous || len(msg.Poll.Options) != 2 {
+		t.FailNow()
+	}
+	if game := s.game(t); game.PollId != msg.Poll.Id {
+		t.FailNow()
+	}
+
+	vote := func(u telegram.User, options ...int) {
+		if _, err := s.srv.Vote(u, msg, options...); err != nil {
+			t.Fatal(err)
+		}
+		s.proceed(t)
+	}
+	vote(ivan, 0)
+	vote(guest, 0)
+	game := s.game(t)
+	if !game.HasPlayerByTelegramId(ivan.Id) || !game.HasPlayerByTelegramId(guest.Id) {
+		t.Error("voters did not join")
+	}
+	if !strings.Contains(s.lastText(org), "Guest") {
+		t.Error("organizer card was not updated")
+	}
+
+	vote(ivan, 1)
+	vote(guest)
+	if game = s.game(t); game.PlayerCount(uuid.Nil) != 0 {
+		t.Error("voters did not leave")
+	}
+	if strings.Contains(s.lastText(org), "Guest") {
+		t.Error("organizer card still shows left voter")
+	}
+
+	s.press(t, org, vres.Show.RefreshBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.CancelBtn)
+	s.press(t, org, vres.Cancel.ConfirmBtn)
+	if len(s.srv.Calls("stopPoll")) != 1 {
+		t.Error("poll was not stopped")
+	}
+	if _, err := s.srv.Vote(ivan, msg, 0); err != telegramtest.ErrPollNotFound {
+		t.Fail()
+	}
+}
+
+func TestClosedPollScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+	anna := s.addPerson("Anna", 3)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.PollBtn)
+	msg, ok := s.srv.LastMessage(loc.ChatId)
+	if !ok || msg.Poll == nil {
+		t.FailNow()
+	}
+	vote := func(u telegram.User) {
+		if _, err := s.srv.Vote(u, msg, 0); err != nil {
+			t.Fatal(err)
+		}
+		s.proceed(t)
+	}
+
+	tests := []struct {
+		name   string
+		poll   telegram.Poll
+		voter  telegram.User
+		joined bool
+		bound  bool
+	}{
+		{name: "Open poll", poll: telegram.Poll{Id: msg.Poll.Id}, voter: ivan, joined: true, bound: true},
+		{name: "Closed poll", poll: telegram.Poll{Id: msg.Poll.Id, IsClosed: true}, voter: anna, joined: false, bound: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := s.service.ProceedPoll(s.ctx, &test.poll); err != nil {
+				t.FailNow()
+			}
+			vote(test.voter)
+			game := s.game(t)
+			if game.HasPlayerByTelegramId(test.voter.Id) != test.joined || (game.PollId == msg.Poll.Id) != test.bound {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestConcurrentJoinScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+	anna := s.addPerson("Anna", 3)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	for _, pl := range []telegram.User{ivan, anna} {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+	}
+	// the presses are proceeded by the service directly, not by the poller
+	joins := []telegram.CallbackQuery{}
+	for _, pl := range []telegram.User{ivan, anna} {
+		cq, err := s.srv.PressButton(pl, pl.Id, vres.Show.JoinBtn)
+		if err != nil {
+			t.FailNow()
+		}
+		joins = append(joins, cq)
+	}
+
+	held, resume := s.holdSave()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.service.ProceedCallback(s.ctx, &joins[0])
+	}()
+	<-held
+	go func() {
+		defer wg.Done()
+		s.service.ProceedCallback(s.ctx, &joins[1])
+	}()
+	time.Sleep(20 * time.Millisecond)
+	resume()
+	wg.Wait()
+
+	if game = s.game(t); !game.HasPlayerByTelegramId(ivan.Id) || !game.HasPlayerByTelegramId(anna.Id) {
+		t.Error("join of the other chat was lost")
+	}
+}
+
+func TestRetriedJoinScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	s.srv.UserMessage(ivan, ivan.Id, "/start join_"+game.Id.String())
+	s.proceed(t)
+
+	// the answer of an old query fails after the join is saved and sent
+	cq, err := s.srv.PressButton(ivan, ivan.Id, vres.Show.JoinBtn)
+	if err != nil {
+		t.FailNow()
+	}
+	cq.Answer(s.ctx, s.srv.Bot(), "Ok", telegram.AnswerCallbackQueryRequest{})
+	answers, sent := len(s.srv.Calls("answerCallbackQuery")), len(s.srv.Calls("editMessageText"))
+	d := telegram.NewDispatcher(1, 10, s.poller.UpdateHandlers...)
+	d.Backoff = 0
+	d.ProceedUpdate(s.ctx, s.srv.Bot(), telegram.Update{CallbackQuery: &cq})
+	d.Wait()
+
+	if len(s.srv.Calls("answerCallbackQuery")) != answers+1 {
+		t.Error("join was proceeded again")
+	}
+	if game = s.game(t); game.PlayerCount(uuid.Nil) != 1 {
+		t.Error("player joined more than once")
+	}
+	if edited := len(s.srv.Calls("editMessageText")) - sent; edited == 0 || edited > 2 {
+		t.Errorf("cards edited %d times", edited)
+	}
+}
+
+func TestPromotionScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+	anna := s.addPerson("Anna", 3)
+	oleg := s.addPerson("Oleg", 4)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	game.MaxPlayers = 1
+	s.vrep.Update(s.ctx, game)
+	for _, pl := range []telegram.User{ivan, anna, oleg} {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+	sent := len(s.srv.Calls("sendMessage"))
+
+	s.press(t, ivan, vres.Show.JoinLeaveBtn)
+	game = s.game(t)
+	if !game.GetMemberByTelegramId(anna.Id).Pending() || game.GetMemberByTelegramId(oleg.Id).Pending() {
+		t.FailNow()
+	}
+	if !strings.Contains(s.lastText(anna), "Подтверди участие") {
+		t.Error("promoted player was not notified")
+	}
+	if len(s.srv.Calls("sendMessage")) != sent+1 {
+		t.Error("promotion notified more than once")
+	}
+
+	s.press(t, anna, vres.Promote.ConfirmBtn)
+	if game = s.game(t); game.GetMemberByTelegramId(anna.Id).Pending() ||
+		!game.HasPlayerByTelegramId(anna.Id) {
+		t.Fail()
+	}
+	rview := volley.NewTelegramViewRu(game)
+	if s.lastText(anna) != fmt.Sprintf(vres.Promote.ConfirmedMessage, rview.GetTitle()) {
+		t.Error("confirmation was not shown")
+	}
+
+	s.press(t, anna, vres.Show.JoinLeaveBtn)
+	if game = s.game(t); !game.GetMemberByTelegramId(oleg.Id).Pending() {
+		t.FailNow()
+	}
+	err := s.service.ReleaseExpired(s.ctx, time.Now())
+	if game = s.game(t); err != nil || !game.HasPlayerByTelegramId(oleg.Id) {
+		t.Error("player released before the window")
+	}
+	s.service.Resources.Resources.Promote.Window = 0
+	err = s.service.ReleaseExpired(s.ctx, time.Now())
+	if game = s.game(t); err != nil || game.HasPlayerByTelegramId(oleg.Id) {
+		t.Error("expired player was not released")
+	}
+	if !strings.Contains(s.lastText(oleg), "Место передано") {
+		t.Error("released player was not notified")
+	}
+}
+
+func TestReleaseJoinScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+	anna := s.addPerson("Anna", 3)
+	petr := s.addPerson("Petr", 4)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	game.MaxPlayers = 1
+	s.vrep.Update(s.ctx, game)
+	for _, pl := range []telegram.User{ivan, anna} {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+	s.press(t, ivan, vres.Show.JoinLeaveBtn)
+	if game = s.game(t); !game.GetMemberByTelegramId(anna.Id).Pending() {
+		t.FailNow()
+	}
+	s.srv.UserMessage(petr, petr.Id, "/start join_"+game.Id.String())
+	s.proceed(t)
+	join, err := s.srv.PressButton(petr, petr.Id, vres.Show.JoinBtn)
+	if err != nil {
+		t.FailNow()
+	}
+	s.service.Resources.Resources.Promote.Window = 0
+
+	held, resume := s.holdSave()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.service.ProceedCallback(s.ctx, &join)
+	}()
+	<-held
+	go func() {
+		defer wg.Done()
+		s.service.ReleaseExpired(s.ctx, time.Now())
+	}()
+	time.Sleep(20 * time.Millisecond)
+	resume()
+	wg.Wait()
+
+	game = s.game(t)
+	if game.HasPlayerByTelegramId(anna.Id) {
+		t.Error("release was lost")
+	}
+	if !game.GetMemberByTelegramId(petr.Id).Pending() {
+		t.Error("join was lost")
+	}
+}
+
+func TestScheduleScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.ScheduleBtn)
+	game := s.game(t)
+	sch, err := s.srep.Get(s.ctx, game.ScheduleId)
+	if err != nil || !sch.HasWeekday(game.StartTime.Weekday()) {
+		t.FailNow()
+	}
+	if !strings.Contains(s.lastText(org), "Расписание") {
+		t.Error("schedule screen was not shown")
+	}
+
+	if err = s.service.GenerateScheduled(s.ctx, game.StartTime); err != nil {
+		t.FailNow()
+	}
+	vlist, _ := s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false)
+	if len(vlist) != 3 {
+		t.Fatalf("expected 3 scheduled games, got %d", len(vlist))
+	}
+	s.service.GenerateScheduled(s.ctx, game.StartTime)
+	if vlist, _ = s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false); len(vlist) != 3 {
+		t.Error("games were generated twice")
+	}
+
+	s.press(t, org, vres.Schedule.DaysBtn)
+	day := (game.StartTime.Weekday() + 1) % 7
+	s.press(t, org, volley.WeekdayNamesRu[day])
+	if sch, _ = s.srep.Get(s.ctx, sch.Id); !sch.HasWeekday(day) || len(sch.Weekdays) != 2 {
+		t.Error("weekday was not toggled")
+	}
+	s.press(t, org, "Назад")
+
+	game.Price = 700
+	s.vrep.Update(s.ctx, game)
+	s.press(t, org, vres.Schedule.FutureBtn)
+	if s.lastText(org) != fmt.Sprintf(vres.Schedule.FutureMessage, 2) {
+		t.Error("applied games count was not shown")
+	}
+	vlist, _ = s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false)
+	for _, v := range vlist {
+		if v.Price != 700 {
+			t.Error("template was not applied to future game")
+		}
+	}
+
+	s.press(t, org, vres.Schedule.PauseBtn)
+	if sch, _ = s.srep.Get(s.ctx, sch.Id); !sch.Paused {
+		t.FailNow()
+	}
+	s.service.GenerateScheduled(s.ctx, game.StartTime.AddDate(0, 0, 30))
+	if vlist, _ = s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false); len(vlist) != 3 {
+		t.Error("paused schedule generated games")
+	}
+
+	s.press(t, org, vres.Schedule.DeleteBtn)
+	if _, err = s.srep.Get(s.ctx, sch.Id); err != volley.ErrScheduleNotFound {
+		t.Error("schedule was not deleted")
+	}
+	if game, _ = s.vrep.Get(s.ctx, game.Id); game.ScheduleId != uuid.Nil {
+		t.Error("game is still linked to the schedule")
+	}
+}
+
+func TestConcurrentScheduleApplyScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	ivan := s.addPerson("Ivan", 2)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.ScheduleBtn)
+	game := s.game(t)
+	if err := s.service.GenerateScheduled(s.ctx, game.StartTime); err != nil {
+		t.FailNow()
+	}
+	game.Price = 700
+	s.vrep.Update(s.ctx, game)
+	apply, err := s.srv.PressButton(org, org.Id, vres.Schedule.FutureBtn)
+	if err != nil {
+		t.FailNow()
+	}
+
+	// the first future game is held while it is saved by the schedule
+	held, release := make(chan volley.Volley), make(chan struct{})
+	var once sync.Once
+	s.vrep.onSave = func(v volley.Volley) {
+		once.Do(func() {
+			held <- v
+			<-release
+		})
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.service.ProceedCallback(s.ctx, &apply)
+	}()
+	future := <-held
+	go func() {
+		defer wg.Done()
+		st := telegram.State{Prefix: "res", Separator: "_", State: "show", Action: "join",
+			Data: future.Base64Id(), ChatId: ivan.Id}
+		s.service.LogErrors(s.service.Proceed(s.ctx, ivan.Id, st, telegram.Message{From: &ivan}))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	future, _ = s.vrep.Get(s.ctx, future.Id)
+	if future.Price != 700 {
+		t.Error("template was not applied")
+	}
+	if !future.HasPlayerByTelegramId(ivan.Id) {
+		t.Error("join was lost")
+	}
+}
+
+func TestGenerateScheduled(t *testing.T) {
+	s := newScenario(t)
+	author := person.Person{Id: uuid.New(), Firstname: "Elly"}
+	now := time.Date(2023, 3, 6, 12, 0, 0, 0, time.UTC)
+	// Tuesday
+	v := volley.NewVolley(author, now.Add(31*time.Hour), now.Add(33*time.Hour))
+	sch, _ := s.srep.Add(s.ctx, volley.NewSchedule(v))
+	v.ScheduleId = sch.Id
+	s.vrep.Add(s.ctx, v)
+	games := func() (starts map[time.Time]int) {
+		starts = map[time.Time]int{}
+		vlist, _ := s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false)
+		for _, g := range vlist {
+			starts[g.StartTime]++
+		}
+		return
+	}
+	addErr := errors.New("add error")
+	failed := v.StartTime.AddDate(0, 0, 7)
+	s.vrep.addErr = func(g volley.Volley) error {
+		if g.StartTime.Equal(failed) {
+			return addErr
+		}
+		return nil
+	}
+
+	t.Run("Add error returned", func(t *testing.T) {
+		if err := s.service.GenerateScheduled(s.ctx, now); err != addErr {
+			t.Fail()
+		}
+	})
+
+	t.Run("Generation mark kept before failed game", func(t *testing.T) {
+		sch, _ := s.srep.Get(s.ctx, sch.Id)
+		if !sch.Generated.Equal(v.StartTime) || len(games()) != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Missing games generated", func(t *testing.T) {
+		s.vrep.addErr = nil
+		if err := s.service.GenerateScheduled(s.ctx, now); err != nil {
+			t.FailNow()
+		}
+		starts := games()
+		if len(starts) != 2 || starts[failed] != 1 || starts[v.StartTime] != 1 {
+			t.Fail()
+		}
+		if sch, _ := s.srep.Get(s.ctx, sch.Id); !sch.Generated.Equal(failed) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Moved game not generated again", func(t *testing.T) {
+		vlist, _ := s.vrep.GetByFilter(s.ctx, volley.Volley{ScheduleId: sch.Id}, false, false)
+		for _, g := range vlist {
+			if g.StartTime.Equal(failed) {
+				g.StartTime, g.EndTime = g.StartTime.Add(24*time.Hour), g.EndTime.Add(24*time.Hour)
+				s.vrep.Update(s.ctx, g)
+			}
+		}
+		if err := s.service.GenerateScheduled(s.ctx, now); err != nil {
+			t.FailNow()
+		}
+		if starts := games(); len(starts) != 2 || starts[failed] != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Deleted game not generated again", func(t *testing.T) {
+		s.vrep.mu.Lock()
+		for id, g := range s.vrep.volleys {
+			if g.ScheduleId == sch.Id && g.StartTime.Equal(v.StartTime) {
+				delete(s.vrep.volleys, id)
+			}
+		}
+		s.vrep.mu.Unlock()
+		next := failed.AddDate(0, 0, 7)
+		if err := s.service.GenerateScheduled(s.ctx, now.AddDate(0, 0, 7)); err != nil {
+			t.FailNow()
+		}
+		if starts := games(); len(starts) != 2 || starts[v.StartTime] != 0 || starts[next] != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestTeamsScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+	players := []telegram.User{s.addPerson("Ivan", 2), s.addPerson("Anna", 3), s.addPerson("Oleg", 4)}
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	s.press(t, org, vres.Show.JoinBtn)
+	for _, pl := range players {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+
+	s.press(t, org, vres.Show.RefreshBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.TeamsBtn)
+	s.press(t, org, vres.Teams.BalanceBtn)
+	game = s.game(t)
+	if len(game.Teams) != 2 || len(game.Teams[0].Players) != 2 || len(game.Teams[1].Players) != 2 {
+		t.FailNow()
+	}
+	if !strings.Contains(s.lastText(org), "Команда 2") {
+		t.Error("teams were not shown")
+	}
+
+	s.press(t, org, vres.Teams.PairBtn)
+	s.press(t, org, "Ivan")
+	s.press(t, org, "Anna")
+	game = s.game(t)
+	ivan, anna := game.GetMemberByTelegramId(players[0].Id), game.GetMemberByTelegramId(players[1].Id)
+	if len(game.Together) != 1 || !game.Together[0].Has(ivan.Id) || !game.Together[0].Has(anna.Id) {
+		t.FailNow()
+	}
+	for _, tm := range game.Teams {
+		together := 0
+		for _, tp := range tm.Players {
+			if tp.Id == ivan.Id || tp.Id == anna.Id {
+				together++
+			}
+		}
+		if together == 1 {
+			t.Error("paired players are in different teams")
+		}
+	}
+
+	s.press(t, org, vres.Teams.ShuffleBtn)
+	s.press(t, org, vres.Teams.PublishBtn)
+	if msg, ok := s.srv.LastMessage(loc.ChatId); !ok || !strings.Contains(msg.Text, "Команда 1") {
+		t.Error("teams were not published")
+	}
+}
+
+func TestResultsScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	players := []telegram.User{s.addPerson("Ivan", 2), s.addPerson("Anna", 3), s.addPerson("Oleg", 4)}
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	s.press(t, org, vres.Show.JoinBtn)
+	for _, pl := range players {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+	s.press(t, org, vres.Show.RefreshBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.ResultsBtn)
+	if !strings.Contains(s.lastText(org), vres.Results.NoTeamsMessage) {
+		t.Error("results without teams")
+	}
+	s.press(t, org, vres.BackBtn)
+	s.press(t, org, vres.Actions.TeamsBtn)
+	s.press(t, org, vres.Teams.BalanceBtn)
+	s.press(t, org, vres.BackBtn)
+	s.press(t, org, vres.Actions.ResultsBtn)
+
+	s.press(t, org, vres.Results.ScoreBtn)
+	s.srv.UserMessage(org, org.Id, "twenty one")
+	s.proceed(t)
+	if msgs := s.srv.Messages(org.Id); len(msgs) < 2 || msgs[len(msgs)-2].Text != vres.Results.ErrorMessage {
+		t.Error("wrong score was accepted")
+	}
+	s.srv.UserMessage(org, org.Id, "21:15")
+	s.proceed(t)
+	if game = s.game(t); len(game.Results) != 1 || game.Results[0].Score != [2]int{21, 15} {
+		t.FailNow()
+	}
+	if !strings.Contains(s.lastText(org), "Сет 1: 21:15") {
+		t.Error("results card was not shown")
+	}
+
+	s.press(t, org, vres.Results.ScoreBtn)
+	s.srv.UserMessage(org, org.Id, "15 21")
+	s.proceed(t)
+	s.press(t, org, vres.Results.UndoBtn)
+	if game = s.game(t); len(game.Results) != 1 {
+		t.FailNow()
+	}
+
+	rate, _ := s.srv.PressButton(org, org.Id, vres.Results.RateBtn)
+	s.proceed(t)
+	if game = s.game(t); !game.Rated || len(s.rrep.history) != 4 {
+		t.FailNow()
+	}
+	if !strings.HasPrefix(s.lastText(org), vres.Results.RatedMessage) {
+		t.Error("rating changes were not shown")
+	}
+	winner := game.Teams[0].Players[0]
+	if r := s.rrep.ratings[winner.Id]; r.Value <= volley.DefaultRating || r.Games != 1 {
+		t.Fail()
+	}
+
+	// the rated flag may be lost when the game fails to be saved after ratings
+	rated := s.rrep.ratings[winner.Id]
+	game.Rated = false
+	s.vrep.Update(s.ctx, game)
+	s.srv.Callback(org, *rate.Message, rate.Data)
+	s.proceed(t)
+	if r := s.rrep.ratings[winner.Id]; r != rated || len(s.rrep.history) != 4 {
+		t.Error("game was rated twice")
+	}
+
+	// ratings are not reset to the default one when they can't be read
+	s.rrep.getErr = errors.New("connection refused")
+	applied := s.rrep.applies
+	game.Rated = false
+	s.vrep.Update(s.ctx, game)
+	s.srv.Callback(org, *rate.Message, rate.Data)
+	s.proceed(t)
+	s.rrep.getErr = nil
+	if s.rrep.applies != applied || s.game(t).Rated {
+		t.Error("ratings were applied without the saved ones")
+	}
+	game.Rated = true
+	s.vrep.Update(s.ctx, game)
+
+	pl := players[0]
+	s.srv.UserMessage(pl, pl.Id, "/volley")
+	s.proceed(t)
+	s.press(t, pl, vres.Main.ProfileBtn)
+	mb := game.GetMemberByTelegramId(pl.Id)
+	if !strings.Contains(s.lastText(pl), fmt.Sprintf("Рейтинг: %d", s.rrep.ratings[mb.Id].Value)) {
+		t.Error("profile has no rating")
+	}
+}
+
+func TestTournamentScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	players := []telegram.User{s.addPerson("Ivan", 2), s.addPerson("Anna", 3), s.addPerson("Oleg", 4),
+		s.addPerson("Petr", 5), s.addPerson("Olga", 6)}
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	s.press(t, org, vres.Show.SettingsBtn)
+	s.press(t, org, vres.Settings.ActivityBtn)
+	s.press(t, org, volley.Tournament.String())
+	s.press(t, org, vres.BackBtn)
+	game := s.game(t)
+	if game.Activity != volley.Tournament {
+		t.FailNow()
+	}
+	s.press(t, org, vres.Show.JoinBtn)
+	for _, pl := range players {
+		s.srv.UserMessage(pl, pl.Id, "/start join_"+game.Id.String())
+		s.proceed(t)
+		s.press(t, pl, vres.Show.JoinBtn)
+	}
+	s.press(t, org, vres.Show.RefreshBtn)
+	s.press(t, org, vres.Show.ActionsBtn)
+	s.press(t, org, vres.Actions.TournamentBtn)
+	s.press(t, org, vres.Tournament.FormatBtn)
+	s.press(t, org, vres.Tournament.FormatBtn)
+	if !strings.Contains(s.lastText(org), volley.SingleElimination.String()) {
+		t.Error("format was not changed")
+	}
+	s.press(t, org, vres.Tournament.StartBtn)
+	if game = s.game(t); len(game.Bracket.Teams) != 3 || len(game.Bracket.Matches) != 3 {
+		t.FailNow()
+	}
+
+	score := func(result string) volley.Match {
+		s.press(t, org, vres.Tournament.MatchBtn)
+		m := game.Bracket.ReadyMatches()[0]
+		br := game.Bracket
+		s.press(t, org, fmt.Sprintf("#%d %s — %s", m.Id, br.Team(m.Teams[0]).Name, br.Team(m.Teams[1]).Name))
+		s.srv.UserMessage(org, org.Id, result)
+		s.proceed(t)
+		game = s.game(t)
+		return *game.Bracket.Match(m.Id)
+	}
+	if m := score("21:21"); m.Winner != 0 {
+		t.FailNow()
+	}
+	if msgs := s.srv.Messages(org.Id); len(msgs) < 2 || msgs[len(msgs)-2].Text != vres.Tournament.ErrorMessage {
+		t.Error("draw was accepted")
+	}
+	s.srv.UserMessage(org, org.Id, "21:15")
+	s.proceed(t)
+	game = s.game(t)
+	semi := game.Bracket.Matches[1]
+	if semi.Winner != semi.Teams[0] {
+		t.FailNow()
+	}
+	if m := score("1:2"); m.Stage != volley.FinalStage || m.Winner != m.Teams[1] {
+		t.FailNow()
+	}
+	champion := game.Bracket.Team(game.Bracket.Champion())
+	if !strings.Contains(s.lastText(org), "🥇 "+champion.Name) {
+		t.Error("champion was not shown")
+	}
+
+	s.press(t, org, vres.Tournament.ExportBtn)
+	msg, _ := s.srv.LastMessage(org.Id)
+	if msg.Document == nil {
+		t.FailNow()
+	}
+	data, _ := s.srv.File(msg.Document.FileId)
+	var br volley.Bracket
+	if err := json.Unmarshal(data, &br); err != nil || br.Champion() != champion.Id {
+		t.Fail()
+	}
+
+	card := func(id int) (txt string) {
+		for _, msg := range s.srv.Messages(org.Id) {
+			if msg.MessageId == id {
+				txt = msg.Text
+			}
+		}
+		return
+	}
+	reset, _ := s.srv.PressButton(org, org.Id, vres.Tournament.ResetBtn)
+	s.proceed(t)
+	if !strings.Contains(card(reset.Message.MessageId), vres.Tournament.ResetMessage) || !s.game(t).Bracket.Started() {
+		t.FailNow()
+	}
+	s.press(t, org, vres.BackBtn)
+	if !s.game(t).Bracket.Started() {
+		t.Error("bracket was reset without confirmation")
+	}
+	s.press(t, org, vres.Tournament.ResetBtn)
+	s.press(t, org, vres.Tournament.ResetConfirmBtn)
+	if s.game(t).Bracket.Started() {
+		t.Error("bracket was not reset")
+	}
+	if _, err := s.srv.PressButton(org, org.Id, vres.Tournament.StartBtn); err != nil {
+		t.Error("tournament card was not shown after reset")
+	}
+}
+
+func TestInlineRefreshScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	anna := s.addPerson("Anna", 2)
+	oleg := s.addPerson("Oleg", 3)
+
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	card, _ := s.srv.LastMessage(org.Id)
+	data := ""
+	for _, btn := range telegramtest.Buttons(card) {
+		if btn.Text == vres.Show.JoinBtn {
+			data = btn.CallbackData
+		}
+	}
+	if data == "" {
+		t.FailNow()
+	}
+
+	s.srv.InlineCallback(anna, "inline-1", data)
+	s.proceed(t)
+	inlineEdits := func() (count int) {
+		for _, c := range s.srv.Calls("editMessageText") {
+			if c.Values.Get("inline_message_id") == "inline-1" {
+				count++
+			}
+		}
+		return
+	}
+	if game = s.game(t); !game.HasPlayerByTelegramId(anna.Id) || inlineEdits() != 1 {
+		t.FailNow()
+	}
+
+	s.srv.UserMessage(oleg, oleg.Id, "/start join_"+game.Id.String())
+	s.proceed(t)
+	s.press(t, oleg, vres.Show.JoinBtn)
+	if inlineEdits() != 2 {
+		t.Error("inline card is not refreshed")
+	}
+	last := s.srv.Calls("editMessageText")
+	for i := len(last) - 1; i >= 0; i-- {
+		if last[i].Values.Get("inline_message_id") == "inline-1" {
+			if !strings.Contains(last[i].Values.Get("text"), "Oleg") {
+				t.Error("inline card has no new player")
+			}
+			break
+		}
+	}
+}
+
+func TestEditDescriptionScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	org := s.addPerson("Organizer", 1, "admin")
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	describe := func(text string) telegram.Message {
+		st := telegram.State{Prefix: "res", Separator: "_", State: "desc", Action: "desc", Data: game.Base64Id(),
+			ChatId: org.Id, MessageId: -1}
+		s.service.StateRepository.Set(s.ctx, st)
+		msg := s.srv.UserMessage(org, org.Id, text)
+		s.proceed(t)
+		return msg
+	}
+	edit := func(msg telegram.Message, text string) {
+		if _, ok := s.srv.EditUserMessage(msg, text); !ok {
+			t.FailNow()
+		}
+		s.proceed(t)
+	}
+
+	first := describe("First")
+
+	t.Run("Description applied", func(t *testing.T) {
+		if s.game(t).Description != "First" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Edit of the description message applied", func(t *testing.T) {
+		// The state of the edited message is bound to the message id of the organizer's text,
+		// so the text has to reach the provider with its own message id.
+		st, _ := s.service.StateRepository.GetByMessage(s.ctx, first)
+		if st.State != "desc" || st.Action != "edited" {
+			t.FailNow()
+		}
+		edit(first, "First edited")
+		if s.game(t).Description != "First edited" {
+			t.Fail()
+		}
+	})
+
+	second := describe("Second")
+
+	t.Run("Edit of the older description ignored", func(t *testing.T) {
+		edit(first, "First edited again")
+		if s.game(t).Description != "Second" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Edited state replaced", func(t *testing.T) {
+		slist, _ := s.service.StateRepository.GetByData(s.ctx, game.Base64Id())
+		edited := []telegram.State{}
+		for _, st := range slist {
+			if st.State == "desc" && st.Action == "edited" {
+				edited = append(edited, st)
+			}
+		}
+		if len(edited) != 1 || edited[0].MessageId != second.MessageId {
+			t.Fail()
+		}
+	})
+
+	t.Run("Edit of the last description applied", func(t *testing.T) {
+		edit(second, "Second edited")
+		if s.game(t).Description != "Second edited" {
+			t.Fail()
+		}
+	})
+}
+
+func TestChannelPostScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	link := telegram.DeepLink(s.srv.Me.UserName, "join_"+game.Id.String())
+	cards := func(chatId int) (count int) {
+		for _, c := range s.srv.Calls("sendMessage") {
+			if c.Values.Get("chat_id") == strconv.Itoa(chatId) {
+				count++
+			}
+		}
+		return
+	}
+
+	var post telegram.Message
+	tests := []struct {
+		name  string
+		send  func()
+		chat  int
+		cards int
+	}{
+		{name: "Other channel", send: func() { s.srv.ChannelPost(-200, "Game "+link) }, chat: -200, cards: 0},
+		{name: "Unknown game", chat: -100, cards: 0, send: func() {
+			s.srv.ChannelPost(-100, "Game "+telegram.DeepLink(s.srv.Me.UserName, "join_"+uuid.New().String()))
+		}},
+		{name: "Announcement", send: func() { post = s.srv.ChannelPost(-100, "Game "+link) }, chat: -100, cards: 1},
+		{name: "Edited announcement", send: func() { s.srv.EditChannelPost(post, "Game moved "+link) }, chat: -100, cards: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.send()
+			s.proceed(t)
+			if cards(test.chat) != test.cards {
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("Card of the game", func(t *testing.T) {
+		msg, ok := s.srv.LastMessage(-100)
+		if !ok || !strings.Contains(msg.Text, "Organizer") || len(telegramtest.Buttons(msg)) == 0 {
+			t.Fail()
+		}
+	})
+}
+
+func TestChatMemberScenario(t *testing.T) {
+	s := newScenario(t)
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	anna := s.addPerson("Anna", 2)
+	pers, _ := s.prep.GetByTelegramId(s.ctx, anna.Id)
+	pers.Settings["notify"] = "on"
+	pers.Settings["notify_cancel"] = "on"
+	s.prep.Update(s.ctx, pers)
+	st := telegram.State{Prefix: "res", Separator: "_", State: "desc", Action: "desc", ChatId: anna.Id, MessageId: -1}
+	s.service.StateRepository.Set(s.ctx, st)
+
+	member := telegram.ChatMember{Status: telegram.ChatMemberMember, User: &anna}
+	left := telegram.ChatMember{Status: telegram.ChatMemberLeft, User: &anna}
+	kicked := telegram.ChatMember{Status: telegram.ChatMemberKicked, User: &anna}
+	tests := []struct {
+		name   string
+		chat   int
+		old    telegram.ChatMember
+		new    telegram.ChatMember
+		notify bool
+	}{
+		{name: "Leave", chat: loc.ChatId, old: member, new: left, notify: false},
+		{name: "Rejoin", chat: loc.ChatId, old: left, new: member, notify: true},
+		{name: "Kick", chat: loc.ChatId, old: member, new: kicked, notify: false},
+		{name: "Join other chat", chat: -200, old: kicked, new: member, notify: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmu := telegram.ChatMemberUpdated{Chat: telegram.Chat{Id: test.chat}, From: &anna,
+				OldChatMember: test.old, NewChatMember: test.new}
+			if err := s.service.ProceedChatMember(s.ctx, &cmu); err != nil {
+				t.FailNow()
+			}
+			pers, _ := s.prep.GetByTelegramId(s.ctx, anna.Id)
+			if pers.Notify("notify", loc.Id) != test.notify || pers.Notify("notify", uuid.New()) != true {
+				t.Fail()
+			}
+			if pers.Settings["notify"] != "on" || pers.Settings["notify_cancel"] != "on" {
+				t.Error("person settings changed")
+			}
+			if slist, _ := s.service.StateRepository.Get(s.ctx, anna.Id); len(slist) != 1 {
+				t.Error("person states cleared")
+			}
+		})
+	}
+}
+
+func TestMyChatMemberScenario(t *testing.T) {
+	s := newScenario(t)
+	vres := s.service.Resources.Resources
+	loc, _ := s.service.GetLocation(s.ctx)
+	loc.ChatId = -100
+	s.lrep.Update(s.ctx, loc)
+	org := s.addPerson("Organizer", 1, "admin")
+	s.srv.UserMessage(org, org.Id, "/volley")
+	s.proceed(t)
+	s.press(t, org, vres.Main.NewReserveBtn)
+	game := s.game(t)
+	s.srv.ChannelPost(-100, "Game "+telegram.DeepLink(s.srv.Me.UserName, "join_"+game.Id.String()))
+	s.proceed(t)
+	st := telegram.State{Prefix: "res", Separator: "_", State: "desc", Action: "desc", ChatId: -100, MessageId: -1}
+	s.service.StateRepository.Set(s.ctx, st)
+
+	chatStates := func(chatId int) (count int) {
+		slist, _ := s.service.StateRepository.GetByData(s.ctx, game.Base64Id())
+		for _, st := range slist {
+			if st.ChatId == chatId {
+				count++
+			}
+		}
+		slist, _ = s.service.StateRepository.Get(s.ctx, chatId)
+		return count + len(slist)
+	}
+	if chatStates(-100) < 2 || chatStates(org.Id) == 0 {
+		t.Fatal("states are not set")
+	}
+
+	me := s.srv.Me
+	cmu := telegram.ChatMemberUpdated{Chat: telegram.Chat{Id: -100}, From: &org,
+		OldChatMember: telegram.ChatMember{Status: telegram.ChatMemberMember, User: &me},
+		NewChatMember: telegram.ChatMember{Status: telegram.ChatMemberLeft, User: &me}}
+	if err := s.service.ProceedMyChatMember(s.ctx, &cmu); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Chat states cleared", func(t *testing.T) {
+		if chatStates(-100) != 0 {
+			t.Fail()
+		}
+	})
+	t.Run("Other states kept", func(t *testing.T) {
+		if chatStates(org.Id) == 0 {
+			t.Fail()
+		}
+	})
+	t.Run("Location chat unbound", func(t *testing.T) {
+		if loc, _ := s.service.GetLocation(s.ctx); loc.ChatId != 0 {
+			t.Fail()
+		}
+	})
+}
@@ -1,16 +1,36 @@
 package telegram
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
-const DefaultApiUrl string = "https://api.telegram.org"
+const (
+	DefaultApiUrl      string        = "https://api.telegram.org"
+	DefaultPollTimeout int           = 30
+	PollTimeoutMargin  time.Duration = 10 * time.Second
+
+	processedHistorySize int = 1000
+)
+
+func NewPollingClient(timeout int) *http.Client {
+	return &http.Client{Timeout: time.Duration(timeout)*time.Second + PollTimeoutMargin}
+}
 
 func NewSimpleBot(Token string, client HttpClient) (SimpleBot, error) {
+	return NewSimpleBotWithEndpoint(DefaultApiUrl, Token, client)
+}
+
+func NewSimpleBotWithEndpoint(endpoint string, Token string, client HttpClient) (SimpleBot, error) {
 	return SimpleBot{
-		apiEndpoint: DefaultApiUrl,
+		apiEndpoint: strings.TrimSuffix(endpoint, "/"),
 		client:      client,
 		token:       Token,
 		chatStates:  make(map[int]interface{}),
@@ -24,74 +44,387 @@ type SimpleBot struct {
 	chatStates  map[int]interface{}
 }
 
-func (tb SimpleBot) GetUpdates(req UpdatesRequest) (resp *UpdateResponse, err error) {
+func (tb SimpleBot) GetUpdates(ctx context.Context, req UpdatesRequest) (resp *UpdateResponse, err error) {
 	var httpResp *http.Response
-	if httpResp, err = tb.SendRequest(req); err == nil {
+	if httpResp, err = tb.SendRequest(ctx, req); err == nil {
 		defer httpResp.Body.Close()
 		resp = &UpdateResponse{}
-		err = resp.Parse(httpResp.Body)
+		if err = resp.Parse(httpResp.Body); err == nil {
+			err = resp.Error()
+		}
 	}
 	return
 }
 
-func (tb SimpleBot) SendRequest(botReq Request) (resp *http.Response, err error) {
+func (tb SimpleBot) SendRequest(ctx context.Context, botReq Request) (resp *http.Response, err error) {
 	values, method, err := botReq.GetParams()
-	if err == nil {
-		var httpReq *http.Request
-		url := fmt.Sprintf("%s/bot%s/%s", tb.apiEndpoint, tb.token, method)
-		httpReq, err = http.NewRequest("POST", url, strings.NewReader(values.Encode()))
-		if err == nil {
-			httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-			resp, err = tb.client.Do(httpReq)
+	if err != nil {
+		return
+	}
+	var (
+		body        io.Reader = strings.NewReader(values.Encode())
+		contentType           = "application/x-www-form-urlencoded"
+	)
+	if fr, ok := botReq.(FileRequest); ok {
+		if files := fr.GetFiles(); len(files) > 0 {
+			buf := &bytes.Buffer{}
+			if contentType, err = WriteMultipart(buf, values, files); err != nil {
+				return
+			}
+			body = buf
 		}
 	}
+	var httpReq *http.Request
+	url := fmt.Sprintf("%s/bot%s/%s", tb.apiEndpoint, tb.token, method)
+	httpReq, err = http.NewRequestWithContext(ctx, "POST", url, body)
+	if err == nil {
+		httpReq.Header.Set("Content-Type", contentType)
+		resp, err = tb.client.Do(httpReq)
+	}
 	return
 }
 
-func (tb SimpleBot) SendMessage(req Request) (resp *MessageResponse, err error) {
+func (tb SimpleBot) SendMessage(ctx context.Context, req Request) (resp *MessageResponse, err error) {
 	var httpResp *http.Response
-	if httpResp, err = tb.SendRequest(req); err == nil {
+	if httpResp, err = tb.SendRequest(ctx, req); err == nil {
 		defer httpResp.Body.Close()
 		resp = &MessageResponse{}
-		err = resp.Parse(httpResp.Body)
+		if err = resp.Parse(httpResp.Body); err == nil {
+			err = resp.Error()
+		}
+	}
+	return
+}
+
+func (tb SimpleBot) SendMediaGroup(ctx context.Context, req SendMediaGroupRequest) (resp *MessagesResponse, err error) {
+	var httpResp *http.Response
+	if httpResp, err = tb.SendRequest(ctx, req); err == nil {
+		defer httpResp.Body.Close()
+		resp = &MessagesResponse{}
+		if err = resp.Parse(httpResp.Body); err == nil {
+			err = resp.Error()
+		}
 	}
 	return
 }
 
+// SimplePoller delivers updates at least once. Updates are deduplicated in memory only,
+// so after a restart updates past the committed offset are proceeded again.
 type SimplePoller struct {
 	bot    Bot
 	offset int
 	Logger
+	OffsetStore    OffsetStore
+	MaxRetries     int
+	Timeout        int
+	AllowedUpdates []string
+	Backoff        time.Duration
+	MaxBackoff     time.Duration
 	UpdateHandlers []UpdateHandler
+	loaded         bool
+	retries        int
+	failures       int
+	sleep          func(context.Context, time.Duration) error
+	processed      map[int]bool
+	history        []int
+	pending        *pendingUpdates
+	saved          int
 }
 
 func NewSimplePoller(tb Bot) SimplePoller {
 	uh := BaseUpdateHandler{MessageHandlers: []MessageHandler{}}
-	return SimplePoller{bot: tb, UpdateHandlers: []UpdateHandler{&uh}}
+	return SimplePoller{
+		bot:            tb,
+		MaxRetries:     3,
+		Timeout:        DefaultPollTimeout,
+		Backoff:        time.Second,
+		MaxBackoff:     time.Minute,
+		UpdateHandlers: []UpdateHandler{&uh},
+		sleep:          sleepContext,
+		pending:        &pendingUpdates{},
+	}
 }
 
-func (lp *SimplePoller) ProceedUpdates() (err error) {
-	if resp, err := lp.bot.GetUpdates(UpdatesRequest{Offset: lp.offset}); err == nil {
-		if len(resp.Result) > 0 {
-			lp.offset = resp.Result[len(resp.Result)-1].UpdateId + 1
-		}
-		for _, update := range resp.Result {
-			for _, handler := range lp.UpdateHandlers {
-				if err = handler.ProceedUpdate(lp.bot, update); err != nil && lp.Logger != nil {
-					lp.Logger.Printf("ERROR: SimplePoller proceed update error '%s'", err.Error())
+func (lp *SimplePoller) ProceedUpdates(ctx context.Context) (err error) {
+	if err = lp.loadOffset(ctx); err != nil {
+		lp.fail(ctx, err)
+		return
+	}
+	hctx := WithoutCancel(ctx)
+	// updates of async handlers in progress are requested again until they are proceeded,
+	// so Telegram keeps them if the bot stops before
+	resp, err := lp.bot.GetUpdates(ctx, UpdatesRequest{
+		Offset:         lp.commitOffset(),
+		Timeout:        lp.Timeout,
+		AllowedUpdates: lp.AllowedUpdates,
+	})
+	if err != nil {
+		lp.fail(ctx, err)
+		return
+	}
+	lp.failures = 0
+	fresh, retry := 0, false
+	for _, update := range resp.Result {
+		if !lp.processed[update.UpdateId] {
+			fresh++
+			if err = lp.proceedUpdate(hctx, update); err != nil {
+				if lp.retries < lp.MaxRetries {
+					lp.retries++
+					retry = true
+					break
+				}
+				if lp.Logger != nil {
+					lp.Logger.Printf("ERROR: SimplePoller skip update %d after %d retries", update.UpdateId, lp.retries)
 				}
+				err = nil
 			}
+			lp.markProcessed(update.UpdateId)
+		}
+		lp.retries = 0
+		if update.UpdateId >= lp.offset {
+			lp.offset = update.UpdateId + 1
 		}
 	}
-	return nil
+	if serr := lp.saveOffset(hctx); serr != nil {
+		err = serr
+	}
+	if retry {
+		lp.wait(ctx, lp.backoff(lp.retries))
+	}
+	if _, ok := lp.getPending().first(); ok && err == nil && fresh == 0 && len(resp.Result) > 0 {
+		lp.waitPending(ctx)
+	}
+	return
+}
+
+func (lp *SimplePoller) Commit(ctx context.Context) (err error) {
+	for _, handler := range lp.UpdateHandlers {
+		if wh, ok := handler.(interface{ Wait() }); ok {
+			wh.Wait()
+		}
+	}
+	if lp.offset != 0 {
+		if err = lp.saveOffset(ctx); err != nil {
+			return
+		}
+		_, err = lp.bot.GetUpdates(ctx, UpdatesRequest{Offset: lp.commitOffset(), Limit: 1})
+	}
+	return
+}
+
+func (lp *SimplePoller) proceedUpdate(ctx context.Context, update Update) (err error) {
+	for _, handler := range lp.UpdateHandlers {
+		var herr error
+		if ah, ok := handler.(AsyncUpdateHandler); ok {
+			herr = lp.dispatch(ctx, pendingUpdate{handler: ah, update: update})
+		} else {
+			herr = handler.ProceedUpdate(ctx, lp.bot, update)
+		}
+		if herr != nil {
+			if lp.Logger != nil {
+				lp.Logger.Printf("ERROR: SimplePoller proceed update error '%s'", herr.Error())
+			}
+			err = herr
+		}
+	}
+	return
+}
+
+// dispatch hands the update over to the async handler, the update stays pending until it is proceeded.
+// The async handler retries the failed update itself to keep the order of updates, so it is skipped here.
+func (lp *SimplePoller) dispatch(ctx context.Context, pu pendingUpdate) (err error) {
+	id := pu.update.UpdateId
+	lp.getPending().track(id)
+	done := func(err error) {
+		if err != nil && lp.Logger != nil {
+			lp.Logger.Printf("ERROR: SimplePoller skip update %d '%s'", id, err.Error())
+		}
+		lp.pending.release(id)
+	}
+	if err = pu.handler.ProceedUpdateAsync(ctx, lp.bot, pu.update, done); err != nil {
+		lp.pending.release(id)
+	}
+	return
+}
+
+// waitPending waits until an update in progress is proceeded, but not longer than Backoff.
+func (lp *SimplePoller) waitPending(ctx context.Context) {
+	select {
+	case <-lp.getPending().released():
+	case <-ctx.Done():
+	case <-time.After(lp.Backoff):
+	}
+}
+
+func (lp *SimplePoller) getPending() *pendingUpdates {
+	if lp.pending == nil {
+		lp.pending = &pendingUpdates{}
+	}
+	return lp.pending
+}
+
+// commitOffset is the lowest offset below which every update is proceeded.
+func (lp *SimplePoller) commitOffset() int {
+	if id, ok := lp.getPending().first(); ok && id < lp.offset {
+		return id
+	}
+	return lp.offset
+}
+
+func (lp *SimplePoller) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	if lp.Logger != nil {
+		lp.Logger.Printf("ERROR: SimplePoller get updates error '%s'", err.Error())
+	}
+	lp.failures++
+	lp.wait(ctx, lp.backoff(lp.failures))
+}
+
+// backoff is the jittered delay before the attempt after the given number of failed ones.
+func (lp *SimplePoller) backoff(failures int) time.Duration {
+	d := lp.Backoff
+	for i := 1; i < failures && d < lp.MaxBackoff; i++ {
+		d *= 2
+	}
+	if lp.MaxBackoff > 0 && d > lp.MaxBackoff {
+		d = lp.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (lp *SimplePoller) wait(ctx context.Context, d time.Duration) error {
+	if lp.sleep == nil {
+		return sleepContext(ctx, d)
+	}
+	return lp.sleep(ctx, d)
+}
+
+func (lp *SimplePoller) markProcessed(updateId int) {
+	if lp.processed == nil {
+		lp.processed = make(map[int]bool)
+	}
+	lp.processed[updateId] = true
+	lp.history = append(lp.history, updateId)
+	if len(lp.history) > processedHistorySize {
+		delete(lp.processed, lp.history[0])
+		lp.history = lp.history[1:]
+	}
+}
+
+func (lp *SimplePoller) loadOffset(ctx context.Context) (err error) {
+	if lp.loaded || lp.OffsetStore == nil {
+		return
+	}
+	var offset int
+	if offset, err = lp.OffsetStore.Get(ctx); err == nil {
+		if offset > lp.offset {
+			lp.offset = offset
+		}
+		lp.saved = offset
+		lp.loaded = true
+	}
+	return
+}
+
+func (lp *SimplePoller) saveOffset(ctx context.Context) (err error) {
+	offset := lp.commitOffset()
+	if lp.OffsetStore == nil || offset == lp.saved {
+		return
+	}
+	if err = lp.OffsetStore.Set(ctx, offset); err == nil {
+		lp.saved = offset
+	}
+	return
+}
+
+type pendingUpdate struct {
+	handler AsyncUpdateHandler
+	update  Update
+}
+
+// pendingUpdates counts async handlers which have not proceeded an update yet.
+type pendingUpdates struct {
+	sync.Mutex
+	count map[int]int
+	done  chan struct{}
+}
+
+func (pu *pendingUpdates) track(id int) {
+	pu.Lock()
+	defer pu.Unlock()
+	if pu.count == nil {
+		pu.count = make(map[int]int)
+	}
+	pu.count[id]++
+}
+
+func (pu *pendingUpdates) release(id int) {
+	pu.Lock()
+	defer pu.Unlock()
+	if pu.count[id]--; pu.count[id] <= 0 {
+		delete(pu.count, id)
+	}
+	if pu.done != nil {
+		select {
+		case pu.done <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// released signals when an update is released.
+func (pu *pendingUpdates) released() <-chan struct{} {
+	pu.Lock()
+	defer pu.Unlock()
+	if pu.done == nil {
+		pu.done = make(chan struct{}, 1)
+	}
+	return pu.done
+}
+
+func (pu *pendingUpdates) first() (id int, ok bool) {
+	pu.Lock()
+	defer pu.Unlock()
+	for uid := range pu.count {
+		if !ok || uid < id {
+			id, ok = uid, true
+		}
+	}
+	return
 }
 
 type SimpleLongPoller struct {
 	SimplePoller
 }
 
-func (lp SimpleLongPoller) Run() error {
-	for {
-		lp.ProceedUpdates()
+func (lp SimpleLongPoller) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		lp.ProceedUpdates(ctx)
 	}
+	return lp.Commit(WithoutCancel(ctx))
+}
+
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func WithoutCancel(parent context.Context) context.Context {
+	return detachedContext{Context: parent}
 }
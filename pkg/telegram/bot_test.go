@@ -1,11 +1,38 @@
 package telegram
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
+type recordLoggerMock struct {
+	lines []string
+}
+
+func (l *recordLoggerMock) Printf(format string, v ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+type failUpdateHandlerMock struct {
+	UpdateHandlerMock
+	fail  map[int]bool
+	calls map[int]int
+}
+
+func (h *failUpdateHandlerMock) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	h.calls[update.UpdateId]++
+	if h.fail[update.UpdateId] {
+		return errors.New("Mock error")
+	}
+	return nil
+}
+
 func TestSimpleBotSendRequest(t *testing.T) {
 	tb, _ := NewSimpleBot("***Token***", httpClientMock{})
 	tb.client = httpClientMock{}
@@ -14,7 +41,7 @@ func TestSimpleBotSendRequest(t *testing.T) {
 		ChatId: 586350636,
 		Text:   "Message text",
 	}
-	resp, err := tb.SendRequest(&req)
+	resp, err := tb.SendRequest(context.Background(), &req)
 
 	t.Run("Error is nil", func(t *testing.T) {
 		if err != nil {
@@ -68,7 +95,7 @@ func TestSimpleBotSendMessage(t *testing.T) {
 		Text:   "Message text",
 	}
 
-	botResp, _ := tb.SendMessage(req)
+	botResp, _ := tb.SendMessage(context.Background(), req)
 
 	t.Run("Response Ok", func(t *testing.T) {
 		if !botResp.Ok {
@@ -90,7 +117,7 @@ func TestSimpleBotGetUpdates(t *testing.T) {
 		"result": [{"update_id": 123130161},{"update_id": 123130162},{"update_id": 123130163}]
 	}`})
 
-	resp, err := tb.GetUpdates(UpdatesRequest{})
+	resp, err := tb.GetUpdates(context.Background(), UpdatesRequest{})
 
 	t.Run("Error is nil", func(t *testing.T) {
 		if err != nil {
@@ -113,6 +140,17 @@ func TestSimpleBotGetUpdates(t *testing.T) {
 	})
 }
 
+type lockedUpdateHandlerMock struct {
+	UpdateHandler
+	mu *sync.Mutex
+}
+
+func (h *lockedUpdateHandlerMock) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.UpdateHandler.ProceedUpdate(ctx, tb, update)
+}
+
 func TestSimplePollerProceedUpdates(t *testing.T) {
 	tb, _ := NewSimpleBot("***Token***",
 		httpClientMock{Body: `{
@@ -121,10 +159,9 @@ func TestSimplePollerProceedUpdates(t *testing.T) {
 	}`})
 
 	pol := NewSimplePoller(tb)
-	errHand := UpdateHandlerMock{err: errors.New("Mock error")}
-	pol.UpdateHandlers = append(pol.UpdateHandlers, errHand)
-	pol.Logger = LoggerMock{}
-	err := pol.ProceedUpdates()
+	pol.OffsetStore = NewMemoryOffsetStore()
+	err := pol.ProceedUpdates(context.Background())
+	stored, _ := pol.OffsetStore.Get(context.Background())
 
 	t.Run("Check error", func(t *testing.T) {
 		if err != nil {
@@ -137,4 +174,311 @@ func TestSimplePollerProceedUpdates(t *testing.T) {
 			t.Fail()
 		}
 	})
+
+	t.Run("Check stored offset", func(t *testing.T) {
+		if stored != 123130164 {
+			t.Fail()
+		}
+	})
+
+	logger := &recordLoggerMock{}
+	pol = NewSimplePoller(tb)
+	pol.OffsetStore = NewMemoryOffsetStore()
+	pol.Logger = logger
+	pol.Backoff = 0
+	errHand := UpdateHandlerMock{err: errors.New("Mock error")}
+	pol.UpdateHandlers = append(pol.UpdateHandlers, errHand)
+	err = pol.ProceedUpdates(context.Background())
+	stored, _ = pol.OffsetStore.Get(context.Background())
+
+	t.Run("Handler error reported", func(t *testing.T) {
+		if err == nil || err.Error() != "Mock error" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Handler error logged", func(t *testing.T) {
+		if len(logger.lines) != 1 || !strings.Contains(logger.lines[0], "Mock error") {
+			t.Fail()
+		}
+	})
+
+	t.Run("Offset not advanced on error", func(t *testing.T) {
+		if pol.offset != 0 || stored != 0 {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerAsyncOffset(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{`{
+		"ok": true,
+		"result": [{"update_id": 123130161},{"update_id": 123130162},{"update_id": 123130163}]
+	}`}}
+	tb, _ := NewSimpleBot("***Token***", client)
+
+	release := make(chan struct{})
+	h := &failUpdateHandlerMock{fail: map[int]bool{123130162: true}, calls: map[int]int{}}
+	var mu sync.Mutex
+	calls := func(id int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return h.calls[id]
+	}
+	d := NewDispatcher(2, 0, UpdateHandlerMock{proceed: func(update Update) {
+		if update.UpdateId == 123130161 {
+			<-release
+		}
+	}}, &lockedUpdateHandlerMock{mu: &mu, UpdateHandler: h})
+	d.MaxRetries, d.Backoff = 1, 0
+	pol := NewSimplePoller(tb)
+	pol.Backoff = 0
+	pol.OffsetStore = NewMemoryOffsetStore()
+	pol.UpdateHandlers = []UpdateHandler{d}
+	stored := func() int {
+		offset, _ := pol.OffsetStore.Get(context.Background())
+		return offset
+	}
+
+	err := pol.ProceedUpdates(context.Background())
+
+	t.Run("Updates dispatched", func(t *testing.T) {
+		if err != nil || pol.offset != 123130164 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Update in progress not committed", func(t *testing.T) {
+		if stored() != 123130161 {
+			t.Fail()
+		}
+	})
+
+	pol.ProceedUpdates(context.Background())
+
+	t.Run("Update in progress requested again", func(t *testing.T) {
+		if client.Requests[1] != "/bot***Token***/getUpdates?offset=123130161&timeout=30" {
+			t.Fail()
+		}
+	})
+
+	close(release)
+	d.Wait()
+
+	t.Run("Failed update retried in order", func(t *testing.T) {
+		if calls(123130161) != 1 || calls(123130162) != 2 || calls(123130163) != 1 {
+			t.Fail()
+		}
+	})
+
+	pol.saveOffset(context.Background())
+
+	t.Run("Failed update skipped after retries", func(t *testing.T) {
+		if stored() != 123130164 {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerHandlerError(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***",
+		httpClientMock{Body: `{
+		"ok": true,
+		"result": [{"update_id": 123130161},{"update_id": 123130162}]
+	}`})
+
+	calls := map[int]int{}
+	pol := NewSimplePoller(tb)
+	pol.MaxRetries = 1
+	pol.Logger = LoggerMock{}
+	sleeps := []time.Duration{}
+	pol.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	pol.UpdateHandlers = append(pol.UpdateHandlers, UpdateHandlerMock{
+		err: errors.New("Mock error"),
+		proceed: func(update Update) {
+			calls[update.UpdateId]++
+		}})
+
+	err := pol.ProceedUpdates(context.Background())
+
+	t.Run("Error returned", func(t *testing.T) {
+		if err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Offset not committed", func(t *testing.T) {
+		if pol.offset != 0 || calls[123130162] != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Retry waits backoff", func(t *testing.T) {
+		if len(sleeps) != 1 || sleeps[0] < pol.Backoff/2 || sleeps[0] > pol.Backoff {
+			t.Fail()
+		}
+	})
+
+	err = pol.ProceedUpdates(context.Background())
+
+	t.Run("Update skipped after retries", func(t *testing.T) {
+		if pol.offset != 123130162 || calls[123130161] != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerDeduplicate(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***",
+		httpClientMock{Body: `{
+		"ok": true,
+		"result": [{"update_id": 123130161},{"update_id": 123130161},{"update_id": 123130162}]
+	}`})
+
+	calls := map[int]int{}
+	pol := NewSimplePoller(tb)
+	pol.UpdateHandlers = []UpdateHandler{UpdateHandlerMock{proceed: func(update Update) {
+		calls[update.UpdateId]++
+	}}}
+	pol.ProceedUpdates(context.Background())
+	pol.ProceedUpdates(context.Background())
+
+	t.Run("Each update proceeded once", func(t *testing.T) {
+		if calls[123130161] != 1 || calls[123130162] != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerOffsetStore(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{`{"ok": true, "result": []}`}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	store := NewMemoryOffsetStore()
+	store.Set(context.Background(), 123130170)
+
+	pol := NewSimplePoller(tb)
+	pol.OffsetStore = store
+	err := pol.ProceedUpdates(context.Background())
+
+	t.Run("Error is nil", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Stored offset requested", func(t *testing.T) {
+		if client.Requests[0] != "/bot***Token***/getUpdates?offset=123130170&timeout=30" {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimpleLongPollerRun(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{
+		`{"ok": true, "result": [{"update_id": 123130161},{"update_id": 123130162}]}`,
+		`{"ok": true, "result": []}`,
+	}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	ctx, cancel := context.WithCancel(context.Background())
+	proceeded := []int{}
+	lp := SimpleLongPoller{SimplePoller: NewSimplePoller(tb)}
+	lp.UpdateHandlers = []UpdateHandler{UpdateHandlerMock{proceed: func(update Update) {
+		proceeded = append(proceeded, update.UpdateId)
+		if update.UpdateId == 123130162 {
+			cancel()
+		}
+	}}}
+
+	err := lp.Run(ctx)
+
+	t.Run("Error is nil", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("In-flight updates finished", func(t *testing.T) {
+		if len(proceeded) != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Offset committed", func(t *testing.T) {
+		last := client.Requests[len(client.Requests)-1]
+		if last != "/bot***Token***/getUpdates?limit=1&offset=123130163" {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerLongPolling(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{`{"ok": true, "result": []}`}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	pol := NewSimplePoller(tb)
+	pol.Timeout = 50
+	pol.AllowedUpdates = []string{"message", "callback_query"}
+	pol.ProceedUpdates(context.Background())
+
+	t.Run("Timeout and allowed updates requested", func(t *testing.T) {
+		want := "/bot***Token***/getUpdates?allowed_updates=%5B%22message%22%2C%22callback_query%22%5D&timeout=50"
+		if client.Requests[0] != want {
+			t.Fail()
+		}
+	})
+
+	t.Run("Polling client timeout", func(t *testing.T) {
+		if NewPollingClient(pol.Timeout).Timeout != 50*time.Second+PollTimeoutMargin {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimplePollerBackoff(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{
+		`{"ok": false, "error_code": 502, "description": "Bad Gateway"}`,
+		`{"ok": false, "error_code": 502, "description": "Bad Gateway"}`,
+		`{"ok": false, "error_code": 502, "description": "Bad Gateway"}`,
+		`{"ok": true, "result": []}`,
+	}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	pol := NewSimplePoller(tb)
+	pol.Backoff = time.Second
+	pol.MaxBackoff = 3 * time.Second
+	sleeps := []time.Duration{}
+	pol.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+
+	errs := []error{}
+	for i := 0; i < 4; i++ {
+		errs = append(errs, pol.ProceedUpdates(context.Background()))
+	}
+
+	t.Run("Errors returned", func(t *testing.T) {
+		if errs[0] == nil || errs[2] == nil || errs[3] != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Jittered backoff", func(t *testing.T) {
+		limits := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+		if len(sleeps) != len(limits) {
+			t.FailNow()
+		}
+		for i, limit := range limits {
+			if sleeps[i] < limit/2 || sleeps[i] > limit {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Failures reset", func(t *testing.T) {
+		if pol.failures != 0 {
+			t.Fail()
+		}
+	})
 }
@@ -0,0 +1,276 @@
+package telegram
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MaxCallbackDataLength    = 64
+	CallbackTokenPrefix      = "~"
+	DefaultCallbackDataTTL   = 7 * 24 * time.Hour
+	callbackTokenRandomBytes = 9
+)
+
+var ErrCallbackDataNotFound = errors.New("the callback data was not found or expired")
+
+func IsCallbackToken(data string) bool {
+	return strings.HasPrefix(data, CallbackTokenPrefix)
+}
+
+func NewCallbackToken() (string, error) {
+	b := make([]byte, callbackTokenRandomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return CallbackTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// EncodeCallbackData replaces the data which doesn't fit into a button by the codec token.
+func EncodeCallbackData(ctx context.Context, c CallbackDataCodec, data string) (string, error) {
+	if c == nil || len(data) <= MaxCallbackDataLength {
+		return data, nil
+	}
+	return c.Encode(ctx, data)
+}
+
+// DecodeCallbackData returns the data stored by the codec for the token, other data is returned as is.
+func DecodeCallbackData(ctx context.Context, c CallbackDataCodec, data string) (string, error) {
+	if c == nil || !IsCallbackToken(data) {
+		return data, nil
+	}
+	return c.Decode(ctx, data)
+}
+
+// NewCallbackDataBot returns the bot which encodes the long callback data of the sent inline keyboards.
+func NewCallbackDataBot(tb Bot, c CallbackDataCodec) *CallbackDataBot {
+	return &CallbackDataBot{Bot: tb, Codec: c}
+}
+
+type CallbackDataBot struct {
+	Bot
+	Codec CallbackDataCodec
+}
+
+func (tb *CallbackDataBot) SendRequest(ctx context.Context, req Request) (*http.Response, error) {
+	req, err := tb.encodeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return tb.Bot.SendRequest(ctx, req)
+}
+
+func (tb *CallbackDataBot) SendMessage(ctx context.Context, req Request) (*MessageResponse, error) {
+	req, err := tb.encodeRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return tb.Bot.SendMessage(ctx, req)
+}
+
+func (tb *CallbackDataBot) encodeRequest(ctx context.Context, req Request) (Request, error) {
+	val, method, err := req.GetParams()
+	if err != nil || tb.Codec == nil {
+		return req, err
+	}
+	changed := false
+	for _, key := range []string{"reply_markup", "results"} {
+		if val.Get(key) == "" {
+			continue
+		}
+		var (
+			data json.RawMessage
+			ok   bool
+		)
+		if key == "results" {
+			data, ok, err = tb.encodeResults(ctx, json.RawMessage(val.Get(key)))
+		} else {
+			data, ok, err = tb.encodeMarkup(ctx, json.RawMessage(val.Get(key)))
+		}
+		if err != nil {
+			return req, err
+		}
+		if ok {
+			val.Set(key, string(data))
+			changed = true
+		}
+	}
+	if !changed {
+		return req, nil
+	}
+	return encodedRequest{Request: req, values: val, method: method}, nil
+}
+
+func (tb *CallbackDataBot) encodeResults(ctx context.Context, data json.RawMessage) (json.RawMessage, bool, error) {
+	results := []map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return data, false, nil
+	}
+	changed := false
+	for _, result := range results {
+		markup, ok, err := tb.encodeMarkup(ctx, result["reply_markup"])
+		if err != nil {
+			return data, false, err
+		}
+		if ok {
+			result["reply_markup"] = markup
+			changed = true
+		}
+	}
+	if !changed {
+		return data, false, nil
+	}
+	data, err := json.Marshal(results)
+	return data, err == nil, err
+}
+
+func (tb *CallbackDataBot) encodeMarkup(ctx context.Context, data json.RawMessage) (json.RawMessage, bool, error) {
+	markup := map[string]json.RawMessage{}
+	if len(data) == 0 || json.Unmarshal(data, &markup) != nil || markup["inline_keyboard"] == nil {
+		return data, false, nil
+	}
+	kbd := [][]InlineKeyboardButton{}
+	if err := json.Unmarshal(markup["inline_keyboard"], &kbd); err != nil {
+		return data, false, nil
+	}
+	changed := false
+	for _, row := range kbd {
+		for i := range row {
+			if len(row[i].CallbackData) <= MaxCallbackDataLength {
+				continue
+			}
+			token, err := EncodeCallbackData(ctx, tb.Codec, row[i].CallbackData)
+			if err != nil {
+				return data, false, err
+			}
+			row[i].CallbackData = token
+			changed = true
+		}
+	}
+	if !changed {
+		return data, false, nil
+	}
+	var err error
+	if markup["inline_keyboard"], err = json.Marshal(kbd); err != nil {
+		return data, false, err
+	}
+	data, err = json.Marshal(markup)
+	return data, err == nil, err
+}
+
+type encodedRequest struct {
+	Request
+	values url.Values
+	method string
+}
+
+func (req encodedRequest) GetParams() (url.Values, string, error) {
+	return req.values, req.method, nil
+}
+
+func (req encodedRequest) GetFiles() []FormFile {
+	if fr, ok := req.Request.(FileRequest); ok {
+		return fr.GetFiles()
+	}
+	return nil
+}
+
+// CallbackDataMiddleware decodes the callback data tokens before the update is proceeded.
+// A query with an expired token is answered with expiredText and dropped, retrying it
+// can't bring the token back.
+func CallbackDataMiddleware(c CallbackDataCodec, expiredText string) Middleware {
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) error {
+			if update.CallbackQuery == nil {
+				return next(ctx, tb, update)
+			}
+			data, err := DecodeCallbackData(ctx, c, update.CallbackQuery.Data)
+			if err != nil {
+				log.WithFields(updateFields(update)).WithFields(log.Fields{
+					"function": "CallbackDataMiddleware",
+					"error":    err,
+				}).Warn("can't decode callback data")
+				_, err = update.CallbackQuery.Answer(ctx, tb, expiredText, AnswerCallbackQueryRequest{})
+				return err
+			}
+			cq := *update.CallbackQuery
+			cq.Data = data
+			update.CallbackQuery = &cq
+			return next(ctx, tb, update)
+		}
+	}
+}
+
+type callbackDataItem struct {
+	data    string
+	expires time.Time
+}
+
+func NewMemoryCallbackDataCodec(ttl time.Duration) CallbackDataCodec {
+	return &MemoryCallbackDataCodec{TTL: ttl, items: make(map[string]callbackDataItem), tokens: make(map[string]string)}
+}
+
+type MemoryCallbackDataCodec struct {
+	TTL    time.Duration
+	items  map[string]callbackDataItem
+	tokens map[string]string
+	now    func() time.Time
+	swept  time.Time
+	sync.Mutex
+}
+
+func (c *MemoryCallbackDataCodec) getNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+func (c *MemoryCallbackDataCodec) Encode(ctx context.Context, data string) (token string, err error) {
+	c.Lock()
+	defer c.Unlock()
+	now := c.getNow()
+	c.cleanup(now)
+	token, ok := c.tokens[data]
+	if !ok {
+		if token, err = NewCallbackToken(); err != nil {
+			return
+		}
+		c.tokens[data] = token
+	}
+	c.items[token] = callbackDataItem{data: data, expires: now.Add(c.TTL)}
+	return
+}
+
+func (c *MemoryCallbackDataCodec) Decode(ctx context.Context, token string) (string, error) {
+	c.Lock()
+	defer c.Unlock()
+	item, ok := c.items[token]
+	if !ok || !c.getNow().Before(item.expires) {
+		return "", ErrCallbackDataNotFound
+	}
+	return item.data, nil
+}
+
+func (c *MemoryCallbackDataCodec) cleanup(now time.Time) {
+	if now.Sub(c.swept) < time.Minute {
+		return
+	}
+	c.swept = now
+	for token, item := range c.items {
+		if !now.Before(item.expires) {
+			delete(c.items, token)
+			delete(c.tokens, item.data)
+		}
+	}
+}
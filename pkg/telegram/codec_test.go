@@ -0,0 +1,183 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryCallbackDataCodec(t *testing.T) {
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewMemoryCallbackDataCodec(time.Hour).(*MemoryCallbackDataCodec)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+	data := strings.Repeat("x", 100)
+
+	token, err := c.Encode(ctx, data)
+
+	t.Run("Short token", func(t *testing.T) {
+		if err != nil || !IsCallbackToken(token) || len(token) > MaxCallbackDataLength {
+			t.Fail()
+		}
+	})
+
+	t.Run("Same token for same data", func(t *testing.T) {
+		if again, _ := c.Encode(ctx, data); again != token {
+			t.Fail()
+		}
+	})
+
+	t.Run("Decode", func(t *testing.T) {
+		if decoded, err := c.Decode(ctx, token); err != nil || decoded != data {
+			t.Fail()
+		}
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		if _, err := c.Decode(ctx, "~unknown"); err != ErrCallbackDataNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		if _, err := c.Decode(ctx, token); err != ErrCallbackDataNotFound {
+			t.Fail()
+		}
+		c.Encode(ctx, "other")
+		if len(c.items) != 1 || len(c.tokens) != 1 {
+			t.Fail()
+		}
+	})
+}
+
+type failCodecMock struct{}
+
+func (c failCodecMock) Encode(ctx context.Context, data string) (string, error) {
+	return "", errors.New("codec error")
+}
+
+func (c failCodecMock) Decode(ctx context.Context, token string) (string, error) {
+	return "", errors.New("codec error")
+}
+
+func TestCallbackDataBot(t *testing.T) {
+	short := State{Prefix: "res", State: "show", Action: "join", Data: "CGCOvpSJTbO-SnJo1AHsbA", Separator: "_"}
+	long := State{Prefix: "res", State: "cfgcourtmaxpl", Action: "set", Data: "CGCOvpSJTbO-SnJo1AHsbA",
+		Value: "2022-01-01T12:00:00Z", Separator: "_"}
+	kbd := InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "short", CallbackData: short.String()}, {Text: "long", CallbackData: long.String()}}}}
+	article := NewInlineQueryResultArticle("1", "Title", "Text")
+	article.ReplyMarkup = kbd
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		request Request
+		key     string
+	}{
+		"Message":      {request: MessageRequest{ChatId: 10, Text: "Text", ReplyMarkup: kbd}, key: "reply_markup"},
+		"Edit markup":  {request: EditMessageReplyMarkupRequest{ChatId: 10, MessageId: 12, ReplyMarkup: kbd}, key: "reply_markup"},
+		"Inline query": {request: AnswerInlineQueryRequest{InlineQueryId: "1", Results: []interface{}{article}}, key: "results"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &seqClientMock{Bodies: []string{`{"ok":true,"result":{"message_id":1}}`}}
+			sb, _ := NewSimpleBot("***Token***", client)
+			c := NewMemoryCallbackDataCodec(time.Hour)
+			if _, err := NewCallbackDataBot(sb, c).SendMessage(ctx, test.request); err != nil || len(client.Requests) != 1 {
+				t.FailNow()
+			}
+			val, _ := url.ParseQuery(strings.SplitN(client.Requests[0], "?", 2)[1])
+			data := val.Get(test.key)
+			if !strings.Contains(data, short.String()) || strings.Contains(data, long.String()) {
+				t.Fail()
+			}
+			var buttons struct {
+				InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+			}
+			if test.key == "results" {
+				results := []struct {
+					ReplyMarkup json.RawMessage `json:"reply_markup"`
+				}{}
+				json.Unmarshal([]byte(data), &results)
+				data = string(results[0].ReplyMarkup)
+			}
+			if json.Unmarshal([]byte(data), &buttons) != nil || len(buttons.InlineKeyboard) != 1 {
+				t.FailNow()
+			}
+			token := buttons.InlineKeyboard[0][1].CallbackData
+			if !IsCallbackToken(token) || len(token) > MaxCallbackDataLength {
+				t.Fail()
+			}
+			decoded, err := DecodeCallbackData(ctx, c, token)
+			if st, _ := NewState().Parse(decoded); err != nil || st != long {
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("Codec error returned", func(t *testing.T) {
+		client := &seqClientMock{Bodies: []string{`{"ok":true,"result":{"message_id":1}}`}}
+		sb, _ := NewSimpleBot("***Token***", client)
+		cb := NewCallbackDataBot(sb, failCodecMock{})
+		if _, err := cb.SendMessage(ctx, MessageRequest{ChatId: 10, Text: "Text", ReplyMarkup: kbd}); err == nil {
+			t.Fail()
+		}
+		if len(client.Requests) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Short data not encoded", func(t *testing.T) {
+		client := &seqClientMock{Bodies: []string{`{"ok":true,"result":{"message_id":1}}`}}
+		sb, _ := NewSimpleBot("***Token***", client)
+		req := MessageRequest{ChatId: 10, Text: "Text", ReplyMarkup: InlineKeyboardMarkup{
+			InlineKeyboard: [][]InlineKeyboardButton{{{Text: "short", CallbackData: short.String()}}}}}
+		if _, err := NewCallbackDataBot(sb, failCodecMock{}).SendMessage(ctx, req); err != nil || len(client.Requests) != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestCallbackDataMiddleware(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCallbackDataCodec(time.Hour)
+	data := strings.Repeat("x", 100)
+	token, _ := c.Encode(ctx, data)
+
+	tests := map[string]struct {
+		data     string
+		result   string
+		answered bool
+	}{
+		"Token":         {data: token, result: data},
+		"Plain data":    {data: "res_show_join", result: "res_show_join"},
+		"Unknown token": {data: "~unknown", answered: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &seqClientMock{Bodies: []string{`{"ok":true,"result":true}`}}
+			sb, _ := NewSimpleBot("***Token***", client)
+			result := ""
+			uh := UpdateHandlerMock{proceed: func(update Update) { result = update.CallbackQuery.Data }}
+			mh := NewMiddlewareHandler(uh, CallbackDataMiddleware(c, "Expired"))
+			update := Update{CallbackQuery: &CallbackQuery{Id: "1", From: &User{Id: 10}, Data: test.data}}
+			err := mh.ProceedUpdate(ctx, sb, update)
+			if err != nil || result != test.result {
+				t.Fail()
+			}
+			if update.CallbackQuery.Data != test.data {
+				t.Fail()
+			}
+			if test.answered && (len(client.Requests) != 1 || !strings.Contains(client.Requests[0], "answerCallbackQuery")) {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrDispatcherQueueFull = errors.New("the dispatcher queue is full")
+
+type DispatchPolicy int
+
+const (
+	BlockPolicy DispatchPolicy = 0
+	DropPolicy  DispatchPolicy = 1
+)
+
+type dispatchItem struct {
+	ctx    context.Context
+	bot    Bot
+	update Update
+	done   func(error)
+}
+
+func NewDispatcher(workers int, queueSize int, handlers ...UpdateHandler) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+	d := &Dispatcher{
+		Workers:        workers,
+		QueueSize:      queueSize,
+		MaxRetries:     3,
+		Backoff:        time.Second,
+		UpdateHandlers: handlers,
+		queues:         make(map[int][]dispatchItem),
+		sem:            make(chan struct{}, workers),
+	}
+	d.cond = sync.NewCond(&d.mu)
+	return d
+}
+
+// Dispatcher proceeds updates of different chats in parallel and updates of
+// one chat in order. A failed update is retried before the next update of its chat,
+// so handlers return errors only while the update can be proceeded again, e.g. before
+// anything is saved or sent, and log errors which happen after that.
+type Dispatcher struct {
+	Workers    int
+	QueueSize  int
+	Policy     DispatchPolicy
+	MaxRetries int
+	Backoff    time.Duration
+	Logger
+	UpdateHandlers []UpdateHandler
+	mu             sync.Mutex
+	cond           *sync.Cond
+	queues         map[int][]dispatchItem
+	pending        int
+	sem            chan struct{}
+	wg             sync.WaitGroup
+}
+
+func (d *Dispatcher) AppendCallbackHandlers(ch ...CallbackHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendCallbackHandlers(ch...)
+	}
+}
+
+func (d *Dispatcher) AppendInlineQueryHandlers(ih ...InlineQueryHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendInlineQueryHandlers(ih...)
+	}
+}
+
+func (d *Dispatcher) AppendPreCheckoutQueryHandlers(ph ...PreCheckoutQueryHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendPreCheckoutQueryHandlers(ph...)
+	}
+}
+
+func (d *Dispatcher) AppendChatMemberHandlers(ch ...ChatMemberHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendChatMemberHandlers(ch...)
+	}
+}
+
+func (d *Dispatcher) AppendPollHandlers(ph ...PollHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendPollHandlers(ph...)
+	}
+}
+
+func (d *Dispatcher) AppendMessageHandlers(mh ...MessageHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendMessageHandlers(mh...)
+	}
+}
+
+func (d *Dispatcher) AppendEditedMessageHandlers(mh ...MessageHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendEditedMessageHandlers(mh...)
+	}
+}
+
+func (d *Dispatcher) AppendChannelPostHandlers(mh ...MessageHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendChannelPostHandlers(mh...)
+	}
+}
+
+func (d *Dispatcher) AppendEditedChannelPostHandlers(mh ...MessageHandler) {
+	for _, handler := range d.UpdateHandlers {
+		handler.AppendEditedChannelPostHandlers(mh...)
+	}
+}
+
+func (d *Dispatcher) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	return d.ProceedUpdateAsync(ctx, tb, update, nil)
+}
+
+func (d *Dispatcher) ProceedUpdateAsync(ctx context.Context, tb Bot, update Update, done func(error)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for d.QueueSize > 0 && d.pending >= d.QueueSize {
+		if d.Policy == DropPolicy {
+			return ErrDispatcherQueueFull
+		}
+		d.cond.Wait()
+	}
+	cid := update.ChatId()
+	if _, ok := d.queues[cid]; !ok {
+		d.queues[cid] = []dispatchItem{}
+		d.wg.Add(1)
+		go d.run(cid)
+	}
+	d.queues[cid] = append(d.queues[cid], dispatchItem{ctx: ctx, bot: tb, update: update, done: done})
+	d.pending++
+	return nil
+}
+
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) run(cid int) {
+	defer d.wg.Done()
+	for {
+		d.sem <- struct{}{}
+		d.mu.Lock()
+		queue := d.queues[cid]
+		if len(queue) == 0 {
+			delete(d.queues, cid)
+			d.mu.Unlock()
+			<-d.sem
+			return
+		}
+		item := queue[0]
+		d.queues[cid] = queue[1:]
+		d.pending--
+		d.cond.Broadcast()
+		d.mu.Unlock()
+
+		err := d.proceed(item)
+		for attempt := 0; err != nil && attempt < d.MaxRetries; attempt++ {
+			// the worker is free for other chats while the update waits for its retry
+			<-d.sem
+			werr := sleepContext(item.ctx, d.Backoff)
+			d.sem <- struct{}{}
+			if werr != nil {
+				break
+			}
+			err = d.proceed(item)
+		}
+		if item.done != nil {
+			item.done(err)
+		}
+		<-d.sem
+	}
+}
+
+func (d *Dispatcher) proceed(item dispatchItem) (err error) {
+	for _, handler := range d.UpdateHandlers {
+		if herr := handler.ProceedUpdate(item.ctx, item.bot, item.update); herr != nil {
+			if d.Logger != nil {
+				d.Logger.Printf("ERROR: Dispatcher proceed update error '%s'", herr.Error())
+			}
+			err = herr
+		}
+	}
+	return
+}
@@ -0,0 +1,186 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newChatUpdate(uid int, cid int) Update {
+	return Update{UpdateId: uid, Message: &Message{Chat: &Chat{Id: cid}}}
+}
+
+func TestDispatcherChatOrdering(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		active  int
+		maxRun  int
+		results = map[int][]int{}
+	)
+	handler := UpdateHandlerMock{proceed: func(update Update) {
+		mu.Lock()
+		active++
+		if active > maxRun {
+			maxRun = active
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Duration(update.UpdateId%3) * time.Millisecond)
+
+		mu.Lock()
+		active--
+		cid := update.ChatId()
+		results[cid] = append(results[cid], update.UpdateId)
+		mu.Unlock()
+	}}
+
+	d := NewDispatcher(3, 0, handler)
+	for i := 1; i <= 100; i++ {
+		d.ProceedUpdate(context.Background(), nil, newChatUpdate(i, i%7))
+	}
+	d.Wait()
+
+	t.Run("All updates proceeded", func(t *testing.T) {
+		count := 0
+		for _, ids := range results {
+			count += len(ids)
+		}
+		if count != 100 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Chat ordering", func(t *testing.T) {
+		for _, ids := range results {
+			for i := 1; i < len(ids); i++ {
+				if ids[i] <= ids[i-1] {
+					t.Fail()
+				}
+			}
+		}
+	})
+
+	t.Run("Concurrency limit", func(t *testing.T) {
+		if maxRun > 3 || maxRun < 1 {
+			t.Fail()
+		}
+	})
+}
+
+type errFuncHandlerMock struct {
+	UpdateHandlerMock
+	proceed func(update Update) error
+}
+
+func (h *errFuncHandlerMock) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	return h.proceed(update)
+}
+
+func TestDispatcherRetry(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []int
+		fails = map[int]int{2: 1, 5: 10}
+	)
+	d := NewDispatcher(2, 0, &errFuncHandlerMock{proceed: func(update Update) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, update.UpdateId)
+		if fails[update.UpdateId] > 0 {
+			fails[update.UpdateId]--
+			return errors.New("Mock error")
+		}
+		return nil
+	}})
+	d.MaxRetries, d.Backoff = 2, 0
+	errs := map[int]error{}
+	for i := 1; i <= 6; i++ {
+		id := i
+		d.ProceedUpdateAsync(context.Background(), nil, newChatUpdate(id, 1), func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs[id] = err
+		})
+	}
+	d.Wait()
+
+	t.Run("Failed update retried before next update of the chat", func(t *testing.T) {
+		want := []int{1, 2, 2, 3, 4, 5, 5, 5, 6}
+		if len(order) != len(want) {
+			t.FailNow()
+		}
+		for i, id := range want {
+			if order[i] != id {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Error reported after retries", func(t *testing.T) {
+		if errs[2] != nil || errs[5] == nil || len(errs) != 6 {
+			t.Fail()
+		}
+	})
+}
+
+func TestDispatcherDropPolicy(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := UpdateHandlerMock{proceed: func(update Update) {
+		if update.UpdateId == 1 {
+			close(started)
+			<-release
+		}
+	}}
+
+	d := NewDispatcher(1, 1, handler)
+	d.Policy = DropPolicy
+	errs := []error{}
+	errs = append(errs, d.ProceedUpdate(context.Background(), nil, newChatUpdate(1, 10)))
+	<-started
+	errs = append(errs, d.ProceedUpdate(context.Background(), nil, newChatUpdate(2, 20)))
+	errs = append(errs, d.ProceedUpdate(context.Background(), nil, newChatUpdate(3, 30)))
+	close(release)
+	d.Wait()
+
+	t.Run("Queued updates accepted", func(t *testing.T) {
+		if errs[0] != nil || errs[1] != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Overflow dropped", func(t *testing.T) {
+		if errs[2] != ErrDispatcherQueueFull {
+			t.Fail()
+		}
+	})
+}
+
+func TestDispatcherBlockPolicy(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		count int
+	)
+	handler := UpdateHandlerMock{proceed: func(update Update) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}}
+
+	d := NewDispatcher(2, 2, handler)
+	for i := 1; i <= 20; i++ {
+		if err := d.ProceedUpdate(context.Background(), nil, newChatUpdate(i, i%4)); err != nil {
+			t.Fail()
+		}
+	}
+	d.Wait()
+
+	t.Run("All updates proceeded", func(t *testing.T) {
+		if count != 20 {
+			t.Fail()
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package telegram
 
-import "regexp"
+import (
+	"context"
+)
 
 type User struct {
 	Id                      int    `json:"id"`
@@ -78,14 +80,32 @@ type KeyboardButtonRequestChat struct {
 }
 
 type KeyboardButton struct {
-	Text        string                    `json:"text"`
-	RequestChat KeyboardButtonRequestChat `json:"request_chat"`
+	Text            string                     `json:"text"`
+	RequestContact  bool                       `json:"request_contact,omitempty"`
+	RequestLocation bool                       `json:"request_location,omitempty"`
+	RequestChat     *KeyboardButtonRequestChat `json:"request_chat,omitempty"`
 }
 
 type ReplyKeyboardMarkup struct {
-	Keyboard        [][]KeyboardButton `json:"keyboard"`
-	OneTimeKeyboard bool               `json:"one_time_keyboard"`
-	ResizeKeyboard  bool               `json:"resize_keyboard"`
+	Keyboard              [][]KeyboardButton `json:"keyboard"`
+	OneTimeKeyboard       bool               `json:"one_time_keyboard"`
+	ResizeKeyboard        bool               `json:"resize_keyboard"`
+	InputFieldPlaceholder string             `json:"input_field_placeholder,omitempty"`
+	Selective             bool               `json:"selective,omitempty"`
+}
+
+type ForceReply struct {
+	ForceReply            bool   `json:"force_reply"`
+	InputFieldPlaceholder string `json:"input_field_placeholder,omitempty"`
+	Selective             bool   `json:"selective,omitempty"`
+}
+
+type Contact struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	UserId      int    `json:"user_id"`
+	Vcard       string `json:"vcard"`
 }
 
 type ReplyKeyboardRemove struct {
@@ -115,57 +135,75 @@ type MessageEntity struct {
 	Language string `json:"language"`
 }
 
+type PhotoSize struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int    `json:"file_size"`
+}
+
+type Document struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	FileName     string `json:"file_name"`
+	MimeType     string `json:"mime_type"`
+	FileSize     int    `json:"file_size"`
+}
+
 type Message struct {
-	MessageId             int             `json:"message_id"`
-	From                  *User           `json:"from"`
-	SenderChat            *Chat           `json:"sender_chat"`
-	Date                  int             `json:"date"`
-	Chat                  *Chat           `json:"chat"`
-	ForwardFrom           *User           `json:"forward_from"`
-	ForwardFromChat       *Chat           `json:"forward_from_chat"`
-	ForwardFromMessage_id int             `json:"forward_from_message_id"`
-	ForwardSignature      string          `json:"forward_signature"`
-	ForwardSenderName     string          `json:"forward_sender_name"`
-	ForwardDate           int             `json:"forward_date"`
-	ReplyToMessage        *Message        `json:"reply_to_message"`
-	ChatShared            *ChatShared     `json:"chat_shared"`
-	ViaBot                *User           `json:"via_bot"`
-	EditDate              int             `json:"edit_date"`
-	MediaGroupId          int             `json:"media_group_id"`
-	AuthorSignature       string          `json:"author_signature"`
-	Text                  string          `json:"text"`
-	Entities              []MessageEntity `json:"entities"`
-	Caption               string          `json:"caption"`
-	CaptionEentities      []MessageEntity `json:"caption_entities"`
-	ReplyMarkup           interface{}     `json:"reply_markup"`
+	MessageId             int                `json:"message_id"`
+	From                  *User              `json:"from"`
+	SenderChat            *Chat              `json:"sender_chat"`
+	Date                  int                `json:"date"`
+	Chat                  *Chat              `json:"chat"`
+	ForwardFrom           *User              `json:"forward_from"`
+	ForwardFromChat       *Chat              `json:"forward_from_chat"`
+	ForwardFromMessage_id int                `json:"forward_from_message_id"`
+	ForwardSignature      string             `json:"forward_signature"`
+	ForwardSenderName     string             `json:"forward_sender_name"`
+	ForwardDate           int                `json:"forward_date"`
+	ReplyToMessage        *Message           `json:"reply_to_message"`
+	ChatShared            *ChatShared        `json:"chat_shared"`
+	ViaBot                *User              `json:"via_bot"`
+	EditDate              int                `json:"edit_date"`
+	MediaGroupId          string             `json:"media_group_id"`
+	AuthorSignature       string             `json:"author_signature"`
+	Text                  string             `json:"text"`
+	Entities              []MessageEntity    `json:"entities"`
+	Document              *Document          `json:"document"`
+	Photo                 []PhotoSize        `json:"photo"`
+	Contact               *Contact           `json:"contact"`
+	Location              *Location          `json:"location"`
+	SuccessfulPayment     *SuccessfulPayment `json:"successful_payment"`
+	Poll                  *Poll              `json:"poll"`
+	Caption               string             `json:"caption"`
+	CaptionEentities      []MessageEntity    `json:"caption_entities"`
+	ReplyMarkup           interface{}        `json:"reply_markup"`
+}
+
+func (msg Message) ParseCommand() Command {
+	return ParseCommand(msg.Text)
 }
 
 func (msg Message) GetCommand() string {
-	re, err := regexp.Compile(`^/([a-zA-Z0-9_]*)`)
-	if err != nil {
-		return ""
-	}
-	matches := re.FindStringSubmatch(msg.Text)
-	if len(matches) < 2 {
-		return ""
-	}
-	return matches[1]
+	return msg.ParseCommand().Name
 }
 
 func (msg Message) IsCommand() bool {
 	return msg.GetCommand() != ""
 }
 
-func (msg Message) SendMessage(tb Bot, Text string, mr MessageRequest) (*MessageResponse, error) {
-	return tb.SendMessage(msg.CreateMessageRequest(Text, mr))
+func (msg Message) SendMessage(ctx context.Context, tb Bot, Text string, mr MessageRequest) (*MessageResponse, error) {
+	return tb.SendMessage(ctx, msg.CreateMessageRequest(Text, mr))
 }
 
-func (msg Message) DeleteMessage(tb Bot) {
-	tb.SendMessage(&DeleteMessageRequest{ChatId: msg.Chat.Id, MessageId: msg.MessageId})
+func (msg Message) DeleteMessage(ctx context.Context, tb Bot) {
+	tb.SendMessage(ctx, &DeleteMessageRequest{ChatId: msg.Chat.Id, MessageId: msg.MessageId})
 }
 
-func (msg Message) Reply(tb Bot, Text string, mr MessageRequest) (*MessageResponse, error) {
-	return tb.SendMessage(msg.CreateReplyRequest(Text, mr))
+func (msg Message) Reply(ctx context.Context, tb Bot, Text string, mr MessageRequest) (*MessageResponse, error) {
+	return tb.SendMessage(ctx, msg.CreateReplyRequest(Text, mr))
 }
 
 func (msg Message) CreateReplyRequest(Text string, mr MessageRequest) (result MessageRequest) {
@@ -181,8 +219,8 @@ func (msg *Message) CreateMessageRequest(Text string, mr MessageRequest) (result
 	return
 }
 
-func (msg Message) EditText(tb Bot, Text string, mer EditMessageTextRequest) (*MessageResponse, error) {
-	return tb.SendMessage(msg.CreateEditTextRequest(Text, mer))
+func (msg Message) EditText(ctx context.Context, tb Bot, Text string, mer EditMessageTextRequest) (*MessageResponse, error) {
+	return tb.SendMessage(ctx, msg.CreateEditTextRequest(Text, mer))
 }
 
 func (msg Message) CreateEditTextRequest(Text string, Request EditMessageTextRequest) EditMessageTextRequest {
@@ -199,19 +237,119 @@ type CallbackQuery struct {
 	GameShortName   string   `json:"game_short_name"`
 }
 
-func (cq CallbackQuery) Answer(tb Bot, Text string, req AnswerCallbackQueryRequest) (*MessageResponse, error) {
+func (cq CallbackQuery) Answer(ctx context.Context, tb Bot, Text string, req AnswerCallbackQueryRequest) (*MessageResponse, error) {
 	req.CallbackQueryId = cq.Id
 	req.Text = Text
-	return tb.SendMessage(req)
+	return tb.SendMessage(ctx, req)
 }
 
 type Update struct {
-	UpdateId          int            `json:"update_id"`
-	Message           *Message       `json:"message"`
-	EditedMessage     *Message       `json:"edited_message"`
-	ChannelPost       *Message       `json:"channel_post"`
-	EditedChannelPost *Message       `json:"edited_channel_post"`
-	CallbackQuery     *CallbackQuery `json:"callback_query"`
+	UpdateId          int                `json:"update_id"`
+	Message           *Message           `json:"message"`
+	EditedMessage     *Message           `json:"edited_message"`
+	ChannelPost       *Message           `json:"channel_post"`
+	EditedChannelPost *Message           `json:"edited_channel_post"`
+	CallbackQuery     *CallbackQuery     `json:"callback_query"`
+	InlineQuery       *InlineQuery       `json:"inline_query"`
+	PreCheckoutQuery  *PreCheckoutQuery  `json:"pre_checkout_query"`
+	MyChatMember      *ChatMemberUpdated `json:"my_chat_member"`
+	ChatMember        *ChatMemberUpdated `json:"chat_member"`
+	Poll              *Poll              `json:"poll"`
+	PollAnswer        *PollAnswer        `json:"poll_answer"`
+}
+
+func (update Update) ChatId() int {
+	if update.Message != nil && update.Message.Chat != nil {
+		return update.Message.Chat.Id
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil && update.CallbackQuery.Message.Chat != nil {
+		return update.CallbackQuery.Message.Chat.Id
+	}
+	if update.EditedMessage != nil && update.EditedMessage.Chat != nil {
+		return update.EditedMessage.Chat.Id
+	}
+	if update.ChannelPost != nil && update.ChannelPost.Chat != nil {
+		return update.ChannelPost.Chat.Id
+	}
+	if update.EditedChannelPost != nil && update.EditedChannelPost.Chat != nil {
+		return update.EditedChannelPost.Chat.Id
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.From != nil {
+		return update.CallbackQuery.From.Id
+	}
+	if update.InlineQuery != nil && update.InlineQuery.From != nil {
+		return update.InlineQuery.From.Id
+	}
+	if update.PreCheckoutQuery != nil && update.PreCheckoutQuery.From != nil {
+		return update.PreCheckoutQuery.From.Id
+	}
+	if update.MyChatMember != nil {
+		return update.MyChatMember.Chat.Id
+	}
+	if update.ChatMember != nil {
+		return update.ChatMember.Chat.Id
+	}
+	if update.PollAnswer != nil && update.PollAnswer.User != nil {
+		return update.PollAnswer.User.Id
+	}
+	return 0
+}
+
+func (update Update) From() *User {
+	switch {
+	case update.Message != nil:
+		return update.Message.From
+	case update.EditedMessage != nil:
+		return update.EditedMessage.From
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From
+	case update.PreCheckoutQuery != nil:
+		return update.PreCheckoutQuery.From
+	case update.MyChatMember != nil:
+		return update.MyChatMember.From
+	case update.ChatMember != nil:
+		return update.ChatMember.From
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User
+	}
+	return nil
+}
+
+func (update Update) UserId() int {
+	if from := update.From(); from != nil {
+		return from.Id
+	}
+	return 0
+}
+
+func (update Update) Type() string {
+	switch {
+	case update.Message != nil:
+		return "message"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	case update.ChannelPost != nil:
+		return "channel_post"
+	case update.EditedChannelPost != nil:
+		return "edited_channel_post"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.InlineQuery != nil:
+		return "inline_query"
+	case update.PreCheckoutQuery != nil:
+		return "pre_checkout_query"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	case update.ChatMember != nil:
+		return "chat_member"
+	case update.Poll != nil:
+		return "poll"
+	case update.PollAnswer != nil:
+		return "poll_answer"
+	}
+	return ""
 }
 
 type BotCommand struct {
@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	BadRequestCode = 400
+	ForbiddenCode  = 403
+)
+
+// blockedDescriptions are 403 errors meaning the user can't get messages from the bot anymore.
+var blockedDescriptions = []string{"bot was blocked by the user", "user is deactivated"}
+
+type APIError struct {
+	Code        int
+	Description string
+	Parameters  ResponseParameters
+}
+
+func (e APIError) Error() string {
+	return fmt.Sprintf("telegram api error %d: %s", e.Code, e.Description)
+}
+
+func (e APIError) IsBlocked() bool {
+	if e.Code != ForbiddenCode {
+		return false
+	}
+	for _, desc := range blockedDescriptions {
+		if strings.Contains(e.Description, desc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e APIError) IsNotModified() bool {
+	return e.Code == BadRequestCode && strings.Contains(e.Description, "message is not modified")
+}
+
+func (e APIError) IsChatMigrated() bool {
+	return e.Parameters.MigrateToChatId != 0
+}
+
+func (e APIError) IsTooManyRequests() bool {
+	return e.Code == TooManyRequestsCode
+}
+
+func AsAPIError(err error) (apiErr APIError, ok bool) {
+	ok = errors.As(err, &apiErr)
+	return
+}
+
+func IsBlocked(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.IsBlocked()
+}
+
+func IsNotModified(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.IsNotModified()
+}
+
+func IsChatMigrated(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.IsChatMigrated()
+}
+
+func IsTooManyRequests(err error) bool {
+	apiErr, ok := AsAPIError(err)
+	return ok && apiErr.IsTooManyRequests()
+}
@@ -0,0 +1,80 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAPIErrorChecks(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want []bool
+	}{
+		"Blocked": {
+			err:  APIError{Code: 403, Description: "Forbidden: bot was blocked by the user"},
+			want: []bool{true, false, false, false},
+		},
+		"Deactivated": {
+			err:  APIError{Code: 403, Description: "Forbidden: user is deactivated"},
+			want: []bool{true, false, false, false},
+		},
+		"Not a member": {
+			err:  APIError{Code: 403, Description: "Forbidden: bot is not a member of the channel chat"},
+			want: []bool{false, false, false, false},
+		},
+		"Not enough rights": {
+			err:  APIError{Code: 403, Description: "Forbidden: not enough rights to send text messages to the chat"},
+			want: []bool{false, false, false, false},
+		},
+		"Not modified": {
+			err:  APIError{Code: 400, Description: "Bad Request: message is not modified"},
+			want: []bool{false, true, false, false},
+		},
+		"Chat migrated": {
+			err: APIError{Code: 400, Description: "Bad Request: group chat was upgraded to a supergroup chat",
+				Parameters: ResponseParameters{MigrateToChatId: -1001234}},
+			want: []bool{false, false, true, false},
+		},
+		"Too many requests": {
+			err:  fmt.Errorf("wrapped: %w", APIError{Code: 429, Parameters: ResponseParameters{RetryAfter: 5}}),
+			want: []bool{false, false, false, true},
+		},
+		"Transport error": {
+			err:  errors.New("connection refused"),
+			want: []bool{false, false, false, false},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := []bool{IsBlocked(test.err), IsNotModified(test.err), IsChatMigrated(test.err), IsTooManyRequests(test.err)}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleBotSendMessageAPIError(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***", httpClientMock{
+		Body: `{"ok": false, "error_code": 403, "description": "Forbidden: bot was blocked by the user"}`})
+
+	resp, err := tb.SendMessage(context.Background(), MessageRequest{ChatId: 586350636, Text: "Text"})
+
+	t.Run("Error is APIError", func(t *testing.T) {
+		apiErr, ok := AsAPIError(err)
+		if !ok || apiErr.Code != 403 || apiErr.Description != "Forbidden: bot was blocked by the user" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Response returned", func(t *testing.T) {
+		if resp == nil || resp.Ok {
+			t.Fail()
+		}
+	})
+}
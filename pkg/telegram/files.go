@@ -0,0 +1,213 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+)
+
+type InputFile struct {
+	FileId string
+	Name   string
+	Reader io.Reader
+}
+
+func (f InputFile) IsUpload() bool {
+	return f.Reader != nil
+}
+
+type FormFile struct {
+	Field string
+	InputFile
+}
+
+func WriteMultipart(w io.Writer, values url.Values, files []FormFile) (contentType string, err error) {
+	mw := multipart.NewWriter(w)
+	for key, vals := range values {
+		for _, val := range vals {
+			if err = mw.WriteField(key, val); err != nil {
+				return
+			}
+		}
+	}
+	for _, file := range files {
+		var part io.Writer
+		if part, err = mw.CreateFormFile(file.Field, file.Name); err != nil {
+			return
+		}
+		if _, err = io.Copy(part, file.Reader); err != nil {
+			return
+		}
+	}
+	return mw.FormDataContentType(), mw.Close()
+}
+
+// rewindFiles remembers positions of the files uploaded by the request,
+// rewind moves them back before one more attempt. ok is false when the files can't be read again.
+func rewindFiles(req Request) (rewind func() error, ok bool) {
+	type mark struct {
+		seeker io.Seeker
+		pos    int64
+	}
+	marks := []mark{}
+	rewind = func() (err error) {
+		for _, m := range marks {
+			if _, err = m.seeker.Seek(m.pos, io.SeekStart); err != nil {
+				return
+			}
+		}
+		return
+	}
+	fr, isFile := req.(FileRequest)
+	if !isFile {
+		return rewind, true
+	}
+	for _, file := range fr.GetFiles() {
+		seeker, isSeeker := file.Reader.(io.Seeker)
+		if !isSeeker {
+			return rewind, false
+		}
+		pos, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return rewind, false
+		}
+		marks = append(marks, mark{seeker: seeker, pos: pos})
+	}
+	return rewind, true
+}
+
+type SendDocumentRequest struct {
+	ChatId              interface{} `json:"chat_id"`
+	Document            InputFile   `json:"document"`
+	Caption             string      `json:"caption"`
+	ParseMode           string      `json:"parse_mode"`
+	DisableNotification bool        `json:"disable_notification"`
+	ReplyToMessageId    int         `json:"reply_to_message_id"`
+	ReplyMarkup         interface{} `json:"reply_markup"`
+}
+
+func (req SendDocumentRequest) GetParams() (val url.Values, method string, err error) {
+	method = "sendDocument"
+	val, err = getMediaParams(req.ChatId, req.Caption, req.ParseMode, req.DisableNotification,
+		req.ReplyToMessageId, req.ReplyMarkup)
+	if err == nil && !req.Document.IsUpload() {
+		val.Add("document", req.Document.FileId)
+	}
+	return
+}
+
+func (req SendDocumentRequest) GetFiles() (files []FormFile) {
+	if req.Document.IsUpload() {
+		files = append(files, FormFile{Field: "document", InputFile: req.Document})
+	}
+	return
+}
+
+type SendPhotoRequest struct {
+	ChatId              interface{} `json:"chat_id"`
+	Photo               InputFile   `json:"photo"`
+	Caption             string      `json:"caption"`
+	ParseMode           string      `json:"parse_mode"`
+	DisableNotification bool        `json:"disable_notification"`
+	ReplyToMessageId    int         `json:"reply_to_message_id"`
+	ReplyMarkup         interface{} `json:"reply_markup"`
+}
+
+func (req SendPhotoRequest) GetParams() (val url.Values, method string, err error) {
+	method = "sendPhoto"
+	val, err = getMediaParams(req.ChatId, req.Caption, req.ParseMode, req.DisableNotification,
+		req.ReplyToMessageId, req.ReplyMarkup)
+	if err == nil && !req.Photo.IsUpload() {
+		val.Add("photo", req.Photo.FileId)
+	}
+	return
+}
+
+func (req SendPhotoRequest) GetFiles() (files []FormFile) {
+	if req.Photo.IsUpload() {
+		files = append(files, FormFile{Field: "photo", InputFile: req.Photo})
+	}
+	return
+}
+
+type InputMedia struct {
+	Type      string    `json:"type"`
+	Media     InputFile `json:"-"`
+	Caption   string    `json:"caption,omitempty"`
+	ParseMode string    `json:"parse_mode,omitempty"`
+}
+
+type SendMediaGroupRequest struct {
+	ChatId              interface{}  `json:"chat_id"`
+	Media               []InputMedia `json:"media"`
+	DisableNotification bool         `json:"disable_notification"`
+	ReplyToMessageId    int          `json:"reply_to_message_id"`
+}
+
+func (req SendMediaGroupRequest) GetParams() (val url.Values, method string, err error) {
+	method = "sendMediaGroup"
+	val, err = getMediaParams(req.ChatId, "", "", req.DisableNotification, req.ReplyToMessageId, nil)
+	if err != nil {
+		return
+	}
+	type media struct {
+		InputMedia
+		Media string `json:"media"`
+	}
+	mlist := []media{}
+	for i, im := range req.Media {
+		m := media{InputMedia: im, Media: im.Media.FileId}
+		if im.Media.IsUpload() {
+			m.Media = "attach://" + mediaField(i)
+		}
+		mlist = append(mlist, m)
+	}
+	data, err := json.Marshal(mlist)
+	if err != nil {
+		return nil, "", err
+	}
+	val.Add("media", string(data))
+	return
+}
+
+func (req SendMediaGroupRequest) GetFiles() (files []FormFile) {
+	for i, im := range req.Media {
+		if im.Media.IsUpload() {
+			files = append(files, FormFile{Field: mediaField(i), InputFile: im.Media})
+		}
+	}
+	return
+}
+
+func mediaField(i int) string {
+	return "file" + strconv.Itoa(i)
+}
+
+func getMediaParams(chatId interface{}, caption string, parseMode string, disableNotification bool,
+	replyTo int, replyMarkup interface{}) (val url.Values, err error) {
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(chatId))
+	if caption != "" {
+		val.Add("caption", caption)
+	}
+	if parseMode != "" {
+		val.Add("parse_mode", parseMode)
+	}
+	if disableNotification {
+		val.Add("disable_notification", strconv.FormatBool(disableNotification))
+	}
+	if replyTo > 0 {
+		val.Add("reply_to_message_id", strconv.Itoa(replyTo))
+	}
+	if replyMarkup != nil {
+		data, err := json.Marshal(replyMarkup)
+		if err != nil {
+			return nil, err
+		}
+		val.Add("reply_markup", string(data))
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+type formPart struct {
+	name string
+	data string
+}
+
+func readMultipart(t *testing.T, contentType string, body io.Reader) (fields map[string]string, files map[string]formPart) {
+	fields, files = map[string]string{}, map[string]formPart{}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		t.FailNow()
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.FailNow()
+		}
+		data, _ := io.ReadAll(part)
+		if part.FileName() != "" {
+			files[part.FormName()] = formPart{name: part.FileName(), data: string(data)}
+		} else {
+			fields[part.FormName()] = string(data)
+		}
+	}
+}
+
+func TestSimpleBotSendFiles(t *testing.T) {
+	tests := map[string]struct {
+		request Request
+		url     string
+		fields  map[string]string
+		files   map[string]formPart
+	}{
+		"Document": {
+			request: SendDocumentRequest{ChatId: 586350636, Caption: "Roster",
+				Document: InputFile{Name: "roster.csv", Reader: strings.NewReader("name;level\nAlexey;3")}},
+			url:    "https://api.telegram.org/bot***Token***/sendDocument",
+			fields: map[string]string{"chat_id": "586350636", "caption": "Roster"},
+			files:  map[string]formPart{"document": {name: "roster.csv", data: "name;level\nAlexey;3"}},
+		},
+		"Photo": {
+			request: SendPhotoRequest{ChatId: 586350636, DisableNotification: true,
+				Photo: InputFile{Name: "court.jpg", Reader: strings.NewReader("jpeg")}},
+			url:    "https://api.telegram.org/bot***Token***/sendPhoto",
+			fields: map[string]string{"chat_id": "586350636", "disable_notification": "true"},
+			files:  map[string]formPart{"photo": {name: "court.jpg", data: "jpeg"}},
+		},
+		"Media group": {
+			request: SendMediaGroupRequest{ChatId: -100123, Media: []InputMedia{
+				{Type: "photo", Media: InputFile{FileId: "AgACAgIAAxk"}, Caption: "Court 1"},
+				{Type: "photo", Media: InputFile{Name: "court2.jpg", Reader: strings.NewReader("jpeg2")}},
+			}},
+			url: "https://api.telegram.org/bot***Token***/sendMediaGroup",
+			fields: map[string]string{"chat_id": "-100123",
+				"media": `[{"type":"photo","caption":"Court 1","media":"AgACAgIAAxk"},{"type":"photo","media":"attach://file1"}]`},
+			files: map[string]formPart{"file1": {name: "court2.jpg", data: "jpeg2"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tb, _ := NewSimpleBot("***Token***", httpClientMock{})
+			resp, err := tb.SendRequest(context.Background(), test.request)
+			if err != nil {
+				t.FailNow()
+			}
+			if resp.Request.URL.String() != test.url {
+				t.Fail()
+			}
+			fields, files := readMultipart(t, resp.Request.Header.Get("Content-Type"), resp.Request.Body)
+			if len(fields) != len(test.fields) || len(files) != len(test.files) {
+				t.Fail()
+			}
+			for key, val := range test.fields {
+				if fields[key] != val {
+					t.Fail()
+				}
+			}
+			for key, val := range test.files {
+				if files[key] != val {
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleBotSendFileId(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***", httpClientMock{})
+	resp, _ := tb.SendRequest(context.Background(), SendDocumentRequest{ChatId: 586350636,
+		Document: InputFile{FileId: "BQACAgIAAxk"}})
+
+	t.Run("Form urlencoded without uploads", func(t *testing.T) {
+		if resp.Request.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Request body", func(t *testing.T) {
+		data, _ := io.ReadAll(resp.Request.Body)
+		if string(data) != "chat_id=586350636&document=BQACAgIAAxk" {
+			t.Fail()
+		}
+	})
+}
+
+func TestSimpleBotSendMediaGroup(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***", httpClientMock{Body: `{"ok": true, "result": [
+		{"message_id": 2468, "media_group_id": "13450987", "photo": [{"file_id": "AgACAgIAAxk", "width": 90, "height": 60}]},
+		{"message_id": 2469, "media_group_id": "13450987", "photo": [{"file_id": "AgACAgIAAxl", "width": 90, "height": 60}]}
+	]}`})
+
+	resp, err := tb.SendMediaGroup(context.Background(), SendMediaGroupRequest{ChatId: 586350636})
+
+	t.Run("Error is nil", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Messages parsed", func(t *testing.T) {
+		if len(resp.Result) != 2 || resp.Result[1].MessageId != 2469 || resp.Result[0].Photo[0].FileId != "AgACAgIAAxk" {
+			t.Fail()
+		}
+	})
+}
@@ -1,39 +1,144 @@
 package telegram
 
 import (
+	"context"
 	"errors"
 	"regexp"
 	"strings"
+	"sync"
 )
 
-type CallbackQueryFunc func(cq *CallbackQuery) error
-type MessageFunc func(m *Message) error
-type MessageStateFunc func(m *Message, state State) error
+type CallbackQueryFunc func(ctx context.Context, cq *CallbackQuery) error
+type MessageFunc func(ctx context.Context, m *Message) error
+type MessageStateFunc func(ctx context.Context, m *Message, state State) error
+type InlineQueryFunc func(ctx context.Context, iq *InlineQuery) error
+type PreCheckoutQueryFunc func(ctx context.Context, pq *PreCheckoutQuery) error
+type ChatMemberFunc func(ctx context.Context, cmu *ChatMemberUpdated) error
+type PollFunc func(ctx context.Context, poll *Poll) error
+type PollAnswerFunc func(ctx context.Context, pa *PollAnswer) error
 
 type BaseUpdateHandler struct {
-	MessageHandlers  []MessageHandler
-	CallbackHandlers []CallbackHandler
+	MessageHandlers           []MessageHandler
+	EditedMessageHandlers     []MessageHandler
+	ChannelPostHandlers       []MessageHandler
+	EditedChannelPostHandlers []MessageHandler
+	CallbackHandlers          []CallbackHandler
+	InlineQueryHandlers       []InlineQueryHandler
+	PreCheckoutHandlers       []PreCheckoutQueryHandler
+	ChatMemberHandlers        []ChatMemberHandler
+	PollHandlers              []PollHandler
 }
 
 func (handler *BaseUpdateHandler) AppendCallbackHandlers(ch ...CallbackHandler) {
 	handler.CallbackHandlers = append(handler.CallbackHandlers, ch...)
 }
 
+func (handler *BaseUpdateHandler) AppendInlineQueryHandlers(ih ...InlineQueryHandler) {
+	handler.InlineQueryHandlers = append(handler.InlineQueryHandlers, ih...)
+}
+
+func (handler *BaseUpdateHandler) AppendPreCheckoutQueryHandlers(ph ...PreCheckoutQueryHandler) {
+	handler.PreCheckoutHandlers = append(handler.PreCheckoutHandlers, ph...)
+}
+
+func (handler *BaseUpdateHandler) AppendChatMemberHandlers(ch ...ChatMemberHandler) {
+	handler.ChatMemberHandlers = append(handler.ChatMemberHandlers, ch...)
+}
+
+func (handler *BaseUpdateHandler) AppendPollHandlers(ph ...PollHandler) {
+	handler.PollHandlers = append(handler.PollHandlers, ph...)
+}
+
 func (handler *BaseUpdateHandler) AppendMessageHandlers(mh ...MessageHandler) {
 	handler.MessageHandlers = append(handler.MessageHandlers, mh...)
 }
 
-func (uh BaseUpdateHandler) ProceedUpdate(tb Bot, update Update) (err error) {
+func (handler *BaseUpdateHandler) AppendEditedMessageHandlers(mh ...MessageHandler) {
+	handler.EditedMessageHandlers = append(handler.EditedMessageHandlers, mh...)
+}
+
+func (handler *BaseUpdateHandler) AppendChannelPostHandlers(mh ...MessageHandler) {
+	handler.ChannelPostHandlers = append(handler.ChannelPostHandlers, mh...)
+}
+
+func (handler *BaseUpdateHandler) AppendEditedChannelPostHandlers(mh ...MessageHandler) {
+	handler.EditedChannelPostHandlers = append(handler.EditedChannelPostHandlers, mh...)
+}
+
+func (uh BaseUpdateHandler) ProceedUpdate(ctx context.Context, tb Bot, update Update) (err error) {
 	if update.Message != nil {
 		for _, handler := range uh.MessageHandlers {
-			if err = handler.ProceedMessage(update.Message); err != nil {
+			if err = handler.ProceedMessage(ctx, update.Message); err != nil {
+				return
+			}
+		}
+	}
+	if update.EditedMessage != nil {
+		for _, handler := range uh.EditedMessageHandlers {
+			if err = handler.ProceedMessage(ctx, update.EditedMessage); err != nil {
+				return
+			}
+		}
+	}
+	if update.ChannelPost != nil {
+		for _, handler := range uh.ChannelPostHandlers {
+			if err = handler.ProceedMessage(ctx, update.ChannelPost); err != nil {
+				return
+			}
+		}
+	}
+	if update.EditedChannelPost != nil {
+		for _, handler := range uh.EditedChannelPostHandlers {
+			if err = handler.ProceedMessage(ctx, update.EditedChannelPost); err != nil {
 				return
 			}
 		}
 	}
 	if update.CallbackQuery != nil {
 		for _, handler := range uh.CallbackHandlers {
-			if err = handler.ProceedCallback(update.CallbackQuery); err != nil {
+			if err = handler.ProceedCallback(ctx, update.CallbackQuery); err != nil {
+				return
+			}
+		}
+	}
+	if update.InlineQuery != nil {
+		for _, handler := range uh.InlineQueryHandlers {
+			if err = handler.ProceedInlineQuery(ctx, update.InlineQuery); err != nil {
+				return
+			}
+		}
+	}
+	if update.PreCheckoutQuery != nil {
+		for _, handler := range uh.PreCheckoutHandlers {
+			if err = handler.ProceedPreCheckoutQuery(ctx, update.PreCheckoutQuery); err != nil {
+				return
+			}
+		}
+	}
+	if update.MyChatMember != nil {
+		for _, handler := range uh.ChatMemberHandlers {
+			if err = handler.ProceedMyChatMember(ctx, update.MyChatMember); err != nil {
+				return
+			}
+		}
+	}
+	if update.ChatMember != nil {
+		for _, handler := range uh.ChatMemberHandlers {
+			if err = handler.ProceedChatMember(ctx, update.ChatMember); err != nil {
+				return
+			}
+		}
+	}
+	if update.Poll != nil {
+		for _, handler := range uh.PollHandlers {
+			if err = handler.ProceedPoll(ctx, update.Poll); err != nil {
+				return
+			}
+		}
+	}
+	if update.PollAnswer != nil {
+		for _, handler := range uh.PollHandlers {
+			if err = handler.ProceedPollAnswer(ctx, update.PollAnswer); err != nil {
 				return
 			}
 		}
@@ -46,8 +151,8 @@ type BaseCallbackHandler struct {
 	Handler CallbackQueryFunc
 }
 
-func (h *BaseCallbackHandler) ProceedCallback(cb *CallbackQuery) error {
-	return h.Handler(cb)
+func (h *BaseCallbackHandler) ProceedCallback(ctx context.Context, cb *CallbackQuery) error {
+	return h.Handler(ctx, cb)
 }
 
 type PrefixCallbackHandler struct {
@@ -56,10 +161,10 @@ type PrefixCallbackHandler struct {
 	Handler CallbackQueryFunc
 }
 
-func (h *PrefixCallbackHandler) ProceedCallback(cb *CallbackQuery) error {
+func (h *PrefixCallbackHandler) ProceedCallback(ctx context.Context, cb *CallbackQuery) error {
 	var prefix []string = strings.Split(cb.Data, "_")
 	if len(prefix) > 1 && prefix[0] == h.Prefix {
-		return h.Handler(cb)
+		return h.Handler(ctx, cb)
 	}
 	return errors.New("data hasn't prefix")
 }
@@ -69,8 +174,8 @@ type BaseMessageHandler struct {
 	Handler MessageFunc
 }
 
-func (h *BaseMessageHandler) ProceedMessage(m *Message) error {
-	return h.Handler(m)
+func (h *BaseMessageHandler) ProceedMessage(ctx context.Context, m *Message) error {
+	return h.Handler(ctx, m)
 }
 
 type CommandHandler struct {
@@ -79,26 +184,30 @@ type CommandHandler struct {
 	Command  string
 	Commands []BotCommand
 	IsRegexp bool
+	re       *regexp.Regexp
+	reErr    error
+	once     sync.Once
 }
 
 func (h *CommandHandler) GetCommands() []BotCommand {
 	return h.Commands
 }
 
-func (h *CommandHandler) ProceedMessage(m *Message) (err error) {
+func (h *CommandHandler) ProceedMessage(ctx context.Context, m *Message) (err error) {
 	if h.IsRegexp {
-		var re *regexp.Regexp
-		re, err = regexp.Compile(h.Command)
-		if err != nil {
+		h.once.Do(func() {
+			h.re, h.reErr = regexp.Compile(h.Command)
+		})
+		if err = h.reErr; err != nil {
 			return
 		}
 		cmd := m.GetCommand()
-		if re.MatchString(cmd) {
-			return h.Handler(m)
+		if h.re.MatchString(cmd) {
+			return h.Handler(ctx, m)
 		}
 	}
 	if m.GetCommand() == h.Command {
-		return h.Handler(m)
+		return h.Handler(ctx, m)
 	}
 	return
 }
@@ -110,14 +219,14 @@ type StateMessageHandler struct {
 	StateRepository StateRepository
 }
 
-func (h *StateMessageHandler) ProceedMessage(m *Message) error {
-	slist, err := h.StateRepository.Get(m.Chat.Id)
+func (h *StateMessageHandler) ProceedMessage(ctx context.Context, m *Message) error {
+	slist, err := h.StateRepository.Get(ctx, m.Chat.Id)
 	if err != nil {
 		return err
 	}
 	for _, st := range slist {
 		if st.State == h.State {
-			return h.Handler(m, st)
+			return h.Handler(ctx, m, st)
 		}
 	}
 	return err
@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"testing"
 )
 
@@ -11,14 +12,14 @@ func TestUpdateHandlerProceed(t *testing.T) {
 
 	uh := BaseUpdateHandler{}
 	mh := BaseMessageHandler{
-		Handler: func(tm *Message) error {
+		Handler: func(ctx context.Context, tm *Message) error {
 			tm.Caption = "Test caption"
 			return nil
 		},
 	}
 	uh.MessageHandlers = append(uh.MessageHandlers, &mh)
 
-	uh.ProceedUpdate(tb, tu)
+	uh.ProceedUpdate(context.Background(), tb, tu)
 
 	t.Run("Message proceeded", func(t *testing.T) {
 		if message.Caption != "Test caption" {
@@ -67,20 +68,20 @@ func TestCommandHandlerProceed(t *testing.T) {
 	}
 
 	mh := BaseMessageHandler{
-		Handler: func(tm *Message) error {
+		Handler: func(ctx context.Context, tm *Message) error {
 			tm.Caption = "Ok"
 			return nil
 		},
 	}
 
-	ch := CommandHandler{Command: "start", Handler: func(m *Message) error {
-		return mh.ProceedMessage(m)
+	ch := CommandHandler{Command: "start", Handler: func(ctx context.Context, m *Message) error {
+		return mh.ProceedMessage(ctx, m)
 	}}
 
 	for name, test := range tests {
 		t.Run("Simple "+name, func(t *testing.T) {
 			msg := Message{Text: test.text, Caption: ""}
-			ch.ProceedMessage(&msg)
+			ch.ProceedMessage(context.Background(), &msg)
 			if msg.Caption != test.want {
 				t.Fail()
 			}
@@ -92,7 +93,7 @@ func TestCommandHandlerProceed(t *testing.T) {
 	for name, test := range tests {
 		t.Run("Regexp "+name, func(t *testing.T) {
 			msg := Message{Text: test.text, Caption: ""}
-			ch.ProceedMessage(&msg)
+			ch.ProceedMessage(context.Background(), &msg)
 			if msg.Caption != test.want_regexp {
 				t.Fail()
 			}
@@ -122,7 +123,7 @@ func TestPrefixCallbackHandlerProceed(t *testing.T) {
 
 	handler := PrefixCallbackHandler{
 		Prefix: "pref",
-		Handler: func(cb *CallbackQuery) error {
+		Handler: func(ctx context.Context, cb *CallbackQuery) error {
 			cb.Data = "Ok"
 			return nil
 		},
@@ -131,10 +132,44 @@ func TestPrefixCallbackHandlerProceed(t *testing.T) {
 	for name, test := range tests {
 		t.Run("Callback "+name, func(t *testing.T) {
 			callback := CallbackQuery{Data: test.text}
-			handler.ProceedCallback(&callback)
+			handler.ProceedCallback(context.Background(), &callback)
 			if callback.Data != test.want {
 				t.Fail()
 			}
 		})
 	}
 }
+
+func TestUpdateHandlerProceedEditedAndChannel(t *testing.T) {
+	proceeded := map[string]string{}
+	handler := func(kind string) MessageHandler {
+		return &BaseMessageHandler{Handler: func(ctx context.Context, m *Message) error {
+			proceeded[kind] = m.Text
+			return nil
+		}}
+	}
+	uh := BaseUpdateHandler{}
+	uh.AppendMessageHandlers(handler("message"))
+	uh.AppendEditedMessageHandlers(handler("edited_message"))
+	uh.AppendChannelPostHandlers(handler("channel_post"))
+	uh.AppendEditedChannelPostHandlers(handler("edited_channel_post"))
+
+	updates := []Update{
+		{EditedMessage: &Message{Text: "edited"}},
+		{ChannelPost: &Message{Text: "post"}},
+		{EditedChannelPost: &Message{Text: "edited post"}},
+	}
+	for _, update := range updates {
+		uh.ProceedUpdate(context.Background(), nil, update)
+	}
+
+	want := map[string]string{"edited_message": "edited", "channel_post": "post", "edited_channel_post": "edited post"}
+	if len(proceeded) != len(want) {
+		t.Fail()
+	}
+	for kind, text := range want {
+		if proceeded[kind] != text {
+			t.Fail()
+		}
+	}
+}
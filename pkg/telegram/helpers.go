@@ -272,6 +272,7 @@ type ActionButton struct {
 	Text   string
 	Data   string
 	Action string
+	Value  string
 }
 
 func NewActionsKeyboardHelper() ActionsKeyboardHelper {
@@ -297,6 +298,9 @@ func (kh ActionsKeyboardHelper) GetKeyboard() interface{} {
 		if act.Data != "" {
 			st.Data = act.Data
 		}
+		if act.Value != "" {
+			st.Value = act.Value
+		}
 		kbdRow = append(kbdRow, InlineKeyboardButton{Text: act.Text, CallbackData: st.String()})
 		if (i+1)%kh.Columns == 0 {
 			kbd = append(kbd, kbdRow)
@@ -365,14 +369,60 @@ type SendKeyboardHelper struct {
 }
 
 func (kh SendKeyboardHelper) GetKeyboard() interface{} {
-	var kbd [][]KeyboardButton
-
-	kbdRow := []KeyboardButton{}
-	kbdRow = append(kbdRow, KeyboardButton{
+	rh := NewReplyKeyboardHelper(KeyboardButton{
 		Text:        kh.Text,
-		RequestChat: KeyboardButtonRequestChat{RequestId: kh.RequestId, BotIsMember: true}},
+		RequestChat: &KeyboardButtonRequestChat{RequestId: kh.RequestId, BotIsMember: true}},
 	)
-	kbd = append(kbd, kbdRow)
+	return rh.GetKeyboard()
+}
+
+func NewReplyKeyboardHelper(btns ...KeyboardButton) ReplyKeyboardHelper {
+	return ReplyKeyboardHelper{Buttons: btns, Columns: 1, OneTime: true, Resize: true}
+}
+
+type ReplyKeyboardHelper struct {
+	BaseKeyboardHelper
+	Buttons     []KeyboardButton
+	Columns     int
+	OneTime     bool
+	Resize      bool
+	Placeholder string
+}
+
+func (kh *ReplyKeyboardHelper) AddContactButton(text string) {
+	kh.Buttons = append(kh.Buttons, KeyboardButton{Text: text, RequestContact: true})
+}
+
+func (kh *ReplyKeyboardHelper) AddLocationButton(text string) {
+	kh.Buttons = append(kh.Buttons, KeyboardButton{Text: text, RequestLocation: true})
+}
+
+func (kh ReplyKeyboardHelper) GetKeyboard() interface{} {
+	var kbd [][]KeyboardButton
+	if kh.Columns == 0 {
+		kh.Columns = 1
+	}
+	kbdRow := []KeyboardButton{}
+	for i, btn := range kh.Buttons {
+		kbdRow = append(kbdRow, btn)
+		if (i+1)%kh.Columns == 0 {
+			kbd = append(kbd, kbdRow)
+			kbdRow = []KeyboardButton{}
+		}
+	}
+	if len(kbdRow) > 0 {
+		kbd = append(kbd, kbdRow)
+	}
+	return ReplyKeyboardMarkup{Keyboard: kbd, OneTimeKeyboard: kh.OneTime, ResizeKeyboard: kh.Resize,
+		InputFieldPlaceholder: kh.Placeholder}
+}
+
+type ForceReplyHelper struct {
+	BaseKeyboardHelper
+	Placeholder string
+	Selective   bool
+}
 
-	return ReplyKeyboardMarkup{Keyboard: kbd, OneTimeKeyboard: true, ResizeKeyboard: true}
+func (kh ForceReplyHelper) GetKeyboard() interface{} {
+	return ForceReply{ForceReply: true, InputFieldPlaceholder: kh.Placeholder, Selective: kh.Selective}
 }
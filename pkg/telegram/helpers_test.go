@@ -1,6 +1,8 @@
 package telegram
 
 import (
+	"encoding/json"
+	"reflect"
 	"strconv"
 	"testing"
 	"time"
@@ -260,6 +262,22 @@ func TestActionsKeyboardHelperGetBtnData(t *testing.T) {
 	}
 }
 
+func TestActionsKeyboardHelperGetKeyboard(t *testing.T) {
+	kh := NewActionsKeyboardHelper()
+	kh.State, _ = NewState().Parse("pr_state1_state1_somedata")
+	kh.Actions = []ActionButton{
+		{Text: "One", Action: "act"},
+		{Text: "Two", Action: "act", Value: "2"},
+	}
+	want := InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{
+		{Text: "One", CallbackData: "pr_state1_act_somedata"},
+		{Text: "Two", CallbackData: "pr_state1_act_somedata_2"},
+	}}}
+	if !reflect.DeepEqual(kh.GetKeyboard(), want) {
+		t.Fail()
+	}
+}
+
 func TestCountKeyboardHelperGetBtnData(t *testing.T) {
 	tests := map[string]struct {
 		data  string
@@ -289,3 +307,41 @@ func TestCountKeyboardHelperGetBtnData(t *testing.T) {
 		})
 	}
 }
+
+func TestReplyKeyboardHelperGetKeyboard(t *testing.T) {
+	contact := NewReplyKeyboardHelper()
+	contact.AddContactButton("Phone")
+	contact.AddLocationButton("Where")
+	contact.Buttons = append(contact.Buttons, KeyboardButton{Text: "Cancel"})
+	contact.Columns = 2
+	contact.Placeholder = "phone"
+
+	tests := map[string]struct {
+		helper KeyboardHelper
+		want   string
+	}{
+		"Contact and location": {
+			helper: contact,
+			want: `{"keyboard":[[{"text":"Phone","request_contact":true},{"text":"Where","request_location":true}],` +
+				`[{"text":"Cancel"}]],"one_time_keyboard":true,"resize_keyboard":true,"input_field_placeholder":"phone"}`,
+		},
+		"Send chat": {
+			helper: SendKeyboardHelper{RequestId: 5, Text: "Chat"},
+			want: `{"keyboard":[[{"text":"Chat","request_chat":{"request_id":5,"bot_is_member":true}}]],` +
+				`"one_time_keyboard":true,"resize_keyboard":true}`,
+		},
+		"Force reply": {
+			helper: ForceReplyHelper{Placeholder: "text"},
+			want:   `{"force_reply":true,"input_field_placeholder":"text"}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(test.helper.GetKeyboard())
+			if err != nil || string(data) != test.want {
+				t.Errorf("got %s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+type InlineQuery struct {
+	Id       string `json:"id"`
+	From     *User  `json:"from"`
+	Query    string `json:"query"`
+	Offset   string `json:"offset"`
+	ChatType string `json:"chat_type"`
+}
+
+func (iq InlineQuery) Answer(ctx context.Context, tb Bot, req AnswerInlineQueryRequest) (*MessageResponse, error) {
+	req.InlineQueryId = iq.Id
+	return tb.SendMessage(ctx, req)
+}
+
+type InputTextMessageContent struct {
+	MessageText           string `json:"message_text"`
+	ParseMode             string `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool   `json:"disable_web_page_preview,omitempty"`
+}
+
+type InlineQueryResultArticle struct {
+	Type                string                  `json:"type"`
+	Id                  string                  `json:"id"`
+	Title               string                  `json:"title"`
+	InputMessageContent InputTextMessageContent `json:"input_message_content"`
+	ReplyMarkup         interface{}             `json:"reply_markup,omitempty"`
+	Description         string                  `json:"description,omitempty"`
+}
+
+func NewInlineQueryResultArticle(id string, title string, text string) InlineQueryResultArticle {
+	return InlineQueryResultArticle{Type: "article", Id: id, Title: title,
+		InputMessageContent: InputTextMessageContent{MessageText: text}}
+}
+
+type AnswerInlineQueryRequest struct {
+	InlineQueryId string        `json:"inline_query_id"`
+	Results       []interface{} `json:"results"`
+	CacheTime     int           `json:"cache_time"`
+	IsPersonal    bool          `json:"is_personal"`
+	NextOffset    string        `json:"next_offset"`
+}
+
+func (req AnswerInlineQueryRequest) GetParams() (val url.Values, method string, err error) {
+	method = "answerInlineQuery"
+	val = url.Values{}
+	val.Add("inline_query_id", req.InlineQueryId)
+	results := req.Results
+	if results == nil {
+		results = []interface{}{}
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, "", err
+	}
+	val.Add("results", string(data))
+	if req.CacheTime > 0 {
+		val.Add("cache_time", strconv.Itoa(req.CacheTime))
+	}
+	if req.IsPersonal {
+		val.Add("is_personal", strconv.FormatBool(req.IsPersonal))
+	}
+	if req.NextOffset != "" {
+		val.Add("next_offset", req.NextOffset)
+	}
+	return
+}
+
+type BaseInlineQueryHandler struct {
+	Bot     Bot
+	Handler InlineQueryFunc
+}
+
+func (h *BaseInlineQueryHandler) ProceedInlineQuery(ctx context.Context, iq *InlineQuery) error {
+	return h.Handler(ctx, iq)
+}
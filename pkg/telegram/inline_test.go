@@ -0,0 +1,77 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInlineQueryHandlerProceed(t *testing.T) {
+	query := ""
+	uh := BaseUpdateHandler{}
+	uh.AppendInlineQueryHandlers(&BaseInlineQueryHandler{
+		Handler: func(ctx context.Context, iq *InlineQuery) error {
+			query = iq.Query
+			return nil
+		}})
+
+	resp := UpdateResponse{}
+	resp.Parse(strings.NewReader(`{"ok": true, "result": [{"update_id": 123130161,
+		"inline_query": {"id": "3456", "from": {"id": 586350636, "first_name": "Alexey"}, "query": "volley", "offset": ""}}]}`))
+	uh.ProceedUpdate(context.Background(), nil, resp.Result[0])
+
+	t.Run("Inline query proceeded", func(t *testing.T) {
+		if query != "volley" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Chat id from user", func(t *testing.T) {
+		if resp.Result[0].ChatId() != 586350636 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGetAnswerInlineQueryParams(t *testing.T) {
+	article := NewInlineQueryResultArticle("game1", "Game", "Game text")
+	article.ReplyMarkup = InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{{Text: "Join", CallbackData: "res_show_join_abc"}}}}
+	req := AnswerInlineQueryRequest{InlineQueryId: "3456", Results: []interface{}{article}, CacheTime: 10, IsPersonal: true}
+
+	values, method, err := req.GetParams()
+
+	t.Run("Method", func(t *testing.T) {
+		if err != nil || method != "answerInlineQuery" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Params", func(t *testing.T) {
+		want := map[string]string{
+			"inline_query_id": "3456",
+			"cache_time":      "10",
+			"is_personal":     "true",
+			"results": `[{"type":"article","id":"game1","title":"Game","input_message_content":{"message_text":"Game text"},` +
+				`"reply_markup":{"inline_keyboard":[[{"text":"Join","url":"","callback_data":"res_show_join_abc","switch_inline_query":"","switch_inline_query_current_chat":"","pay":false}]]}}]`,
+		}
+		if len(values) != len(want) {
+			t.Fail()
+		}
+		for key, val := range want {
+			if values.Get(key) != val {
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestParseBoolResult(t *testing.T) {
+	resp := MessageResponse{}
+	err := resp.Parse(strings.NewReader(`{"ok": true, "result": true}`))
+
+	t.Run("Error is nil", func(t *testing.T) {
+		if err != nil || !resp.Ok {
+			t.Fail()
+		}
+	})
+}
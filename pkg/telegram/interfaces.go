@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
@@ -15,31 +16,61 @@ type Logger interface {
 }
 
 type Bot interface {
-	GetUpdates(UpdatesRequest) (*UpdateResponse, error)
-	SendRequest(Request) (*http.Response, error)
-	SendMessage(Request) (*MessageResponse, error)
+	GetUpdates(context.Context, UpdatesRequest) (*UpdateResponse, error)
+	SendRequest(context.Context, Request) (*http.Response, error)
+	SendMessage(context.Context, Request) (*MessageResponse, error)
 }
 
 type LongPoller interface {
-	Run() error
+	Run(ctx context.Context) error
 }
 
 type Poller interface {
-	ProceedUpdates(resp *UpdateResponse)
+	ProceedUpdates(ctx context.Context) error
 }
 
 type UpdateHandler interface {
-	ProceedUpdate(tb Bot, update Update) error
+	ProceedUpdate(ctx context.Context, tb Bot, update Update) error
 	AppendMessageHandlers(...MessageHandler)
+	AppendEditedMessageHandlers(...MessageHandler)
+	AppendChannelPostHandlers(...MessageHandler)
+	AppendEditedChannelPostHandlers(...MessageHandler)
 	AppendCallbackHandlers(...CallbackHandler)
+	AppendInlineQueryHandlers(...InlineQueryHandler)
+	AppendPreCheckoutQueryHandlers(...PreCheckoutQueryHandler)
+	AppendChatMemberHandlers(...ChatMemberHandler)
+	AppendPollHandlers(...PollHandler)
+}
+
+// AsyncUpdateHandler queues the update and calls done with its result when the update is proceeded.
+type AsyncUpdateHandler interface {
+	ProceedUpdateAsync(ctx context.Context, tb Bot, update Update, done func(error)) error
 }
 
 type CallbackHandler interface {
-	ProceedCallback(*CallbackQuery) error
+	ProceedCallback(context.Context, *CallbackQuery) error
+}
+
+type InlineQueryHandler interface {
+	ProceedInlineQuery(context.Context, *InlineQuery) error
+}
+
+type PreCheckoutQueryHandler interface {
+	ProceedPreCheckoutQuery(context.Context, *PreCheckoutQuery) error
+}
+
+type ChatMemberHandler interface {
+	ProceedMyChatMember(context.Context, *ChatMemberUpdated) error
+	ProceedChatMember(context.Context, *ChatMemberUpdated) error
+}
+
+type PollHandler interface {
+	ProceedPoll(context.Context, *Poll) error
+	ProceedPollAnswer(context.Context, *PollAnswer) error
 }
 
 type MessageHandler interface {
-	ProceedMessage(tm *Message) error
+	ProceedMessage(ctx context.Context, tm *Message) error
 }
 
 type MessageRequestHelper interface {
@@ -65,6 +96,11 @@ type Request interface {
 	GetParams() (url.Values, string, error)
 }
 
+type FileRequest interface {
+	Request
+	GetFiles() []FormFile
+}
+
 type Response interface {
 	Parse(reader io.Reader) error
 }
@@ -78,10 +114,25 @@ type StateProvider interface {
 	Proceed() (State, error)
 }
 
+type OffsetStore interface {
+	Get(context.Context) (int, error)
+	Set(ctx context.Context, offset int) error
+}
+
+type BanChecker interface {
+	IsBanned(ctx context.Context, uid int) (bool, error)
+}
+
+type CallbackDataCodec interface {
+	Encode(ctx context.Context, data string) (string, error)
+	Decode(ctx context.Context, token string) (string, error)
+}
+
 type StateRepository interface {
-	Get(ChatId int) ([]State, error)
-	GetByData(Data string) ([]State, error)
-	GetByMessage(msg Message) (State, error)
-	Set(State) error
-	Clear(State) error
+	Get(ctx context.Context, ChatId int) ([]State, error)
+	GetByData(ctx context.Context, Data string) ([]State, error)
+	GetByMessage(ctx context.Context, msg Message) (State, error)
+	Set(context.Context, State) error
+	Clear(context.Context, State) error
+	ClearChat(ctx context.Context, ChatId int) error
 }
@@ -0,0 +1,164 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const TooManyRequestsCode int = 429
+
+type SendResult struct {
+	Response *MessageResponse
+	Err      error
+}
+
+func NewLimitedBot(tb SimpleBot) *LimitedBot {
+	return &LimitedBot{
+		SimpleBot:      tb,
+		GlobalInterval: time.Second / 30,
+		ChatInterval:   time.Second,
+		GroupInterval:  time.Minute / 20,
+		MaxRetries:     3,
+		RetryBackoff:   time.Second,
+		chats:          make(map[string]time.Time),
+		sleep:          sleepContext,
+	}
+}
+
+type LimitedBot struct {
+	SimpleBot
+	GlobalInterval time.Duration
+	ChatInterval   time.Duration
+	GroupInterval  time.Duration
+	MaxRetries     int
+	RetryBackoff   time.Duration
+	mu             sync.Mutex
+	global         time.Time
+	chats          map[string]time.Time
+	sleep          func(context.Context, time.Duration) error
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reserve returns the time the request to the chat can be sent at. Chats which
+// can already be sent to are forgotten, so only recently used chats are kept.
+func (tb *LimitedBot) reserve(chatId string, delay time.Duration) (slot time.Time) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	now := time.Now()
+	for cid, next := range tb.chats {
+		if !next.After(now) {
+			delete(tb.chats, cid)
+		}
+	}
+	slot = now.Add(delay)
+	if tb.global.After(slot) {
+		slot = tb.global
+	}
+	// retries use the global budget as well, so they never go out at the same time
+	tb.global = slot.Add(tb.GlobalInterval)
+	if next, ok := tb.chats[chatId]; ok && chatId != "" && next.After(slot) {
+		slot = next
+	}
+	if chatId != "" {
+		if strings.HasPrefix(chatId, "-") {
+			tb.chats[chatId] = slot.Add(tb.GroupInterval)
+		} else {
+			tb.chats[chatId] = slot.Add(tb.ChatInterval)
+		}
+	}
+	return
+}
+
+// hold stops all requests until the time, Telegram applies retry_after to the whole bot.
+func (tb *LimitedBot) hold(until time.Time) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if until.After(tb.global) {
+		tb.global = until
+	}
+}
+
+func (tb *LimitedBot) wait(ctx context.Context, slot time.Time) error {
+	if d := time.Until(slot); d > 0 {
+		return tb.sleep(ctx, d)
+	}
+	return nil
+}
+
+func (tb *LimitedBot) getChatId(req Request) string {
+	if val, _, err := req.GetParams(); err == nil {
+		return val.Get("chat_id")
+	}
+	return ""
+}
+
+func (tb *LimitedBot) SendRequest(ctx context.Context, req Request) (*http.Response, error) {
+	if err := tb.wait(ctx, tb.reserve(tb.getChatId(req), 0)); err != nil {
+		return nil, err
+	}
+	return tb.SimpleBot.SendRequest(ctx, req)
+}
+
+func (tb *LimitedBot) SendMediaGroup(ctx context.Context, req SendMediaGroupRequest) (*MessagesResponse, error) {
+	if err := tb.wait(ctx, tb.reserve(tb.getChatId(req), 0)); err != nil {
+		return nil, err
+	}
+	return tb.SimpleBot.SendMediaGroup(ctx, req)
+}
+
+func (tb *LimitedBot) SendMessage(ctx context.Context, req Request) (*MessageResponse, error) {
+	chatId := tb.getChatId(req)
+	return tb.send(ctx, req, chatId, tb.reserve(chatId, 0))
+}
+
+func (tb *LimitedBot) SendMessageAsync(ctx context.Context, req Request) <-chan SendResult {
+	result := make(chan SendResult, 1)
+	chatId := tb.getChatId(req)
+	slot := tb.reserve(chatId, 0)
+	go func() {
+		resp, err := tb.send(ctx, req, chatId, slot)
+		result <- SendResult{Response: resp, Err: err}
+		close(result)
+	}()
+	return result
+}
+
+// send retries the request on 429 errors, uploads are retried only if their readers can be rewound.
+func (tb *LimitedBot) send(ctx context.Context, req Request, chatId string, slot time.Time) (resp *MessageResponse, err error) {
+	rewind, canRetry := rewindFiles(req)
+	for attempt := 0; ; attempt++ {
+		if err = tb.wait(ctx, slot); err != nil {
+			return
+		}
+		if attempt > 0 {
+			if err = rewind(); err != nil {
+				return
+			}
+		}
+		if resp, err = tb.SimpleBot.SendMessage(ctx, req); !IsTooManyRequests(err) {
+			return
+		}
+		if attempt >= tb.MaxRetries || !canRetry {
+			return
+		}
+		delay := tb.RetryBackoff * time.Duration(1<<attempt)
+		if resp.Parameters.RetryAfter > 0 {
+			tb.hold(time.Now().Add(time.Duration(resp.Parameters.RetryAfter) * time.Second))
+			delay = time.Duration(resp.Parameters.RetryAfter)*time.Second + tb.RetryBackoff*time.Duration(attempt)
+		}
+		slot = tb.reserve(chatId, delay)
+	}
+}
@@ -0,0 +1,187 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type seqClientMock struct {
+	mu       sync.Mutex
+	Bodies   []string
+	Calls    int
+	Requests []string
+}
+
+func (client *seqClientMock) Do(httpRequest *http.Request) (*http.Response, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	data, _ := io.ReadAll(httpRequest.Body)
+	client.Requests = append(client.Requests, httpRequest.URL.Path+"?"+string(data))
+	body := client.Bodies[len(client.Bodies)-1]
+	if client.Calls < len(client.Bodies) {
+		body = client.Bodies[client.Calls]
+	}
+	client.Calls++
+	return &http.Response{Request: httpRequest, Body: BodyMock{strings.NewReader(body)}}, nil
+}
+
+func TestLimitedBotReserve(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***", httpClientMock{})
+	lb := NewLimitedBot(tb)
+
+	start := time.Now()
+	first := lb.reserve("100", 0)
+	second := lb.reserve("100", 0)
+	other := lb.reserve("200", 0)
+	group := lb.reserve("-300", 0)
+	groupNext := lb.reserve("-300", 0)
+
+	t.Run("First slot is immediate", func(t *testing.T) {
+		if first.Sub(start) > lb.GlobalInterval {
+			t.Fail()
+		}
+	})
+
+	t.Run("Same chat waits chat interval", func(t *testing.T) {
+		if second.Sub(first) < lb.ChatInterval {
+			t.Fail()
+		}
+	})
+
+	t.Run("Other chat waits global interval only", func(t *testing.T) {
+		if other.Sub(first) < lb.GlobalInterval || other.Sub(first) >= lb.ChatInterval {
+			t.Fail()
+		}
+	})
+
+	t.Run("Group waits group interval", func(t *testing.T) {
+		if groupNext.Sub(group) < lb.GroupInterval {
+			t.Fail()
+		}
+	})
+
+	t.Run("Delayed retries wait global interval", func(t *testing.T) {
+		lb := NewLimitedBot(tb)
+		first := lb.reserve("100", time.Second)
+		second := lb.reserve("200", time.Second)
+		if second.Sub(first) < lb.GlobalInterval {
+			t.Fail()
+		}
+	})
+
+	t.Run("Free chats forgotten", func(t *testing.T) {
+		lb := NewLimitedBot(tb)
+		lb.GlobalInterval, lb.ChatInterval = 0, 0
+		for i := 1; i <= 10; i++ {
+			lb.reserve(strconv.Itoa(i), 0)
+		}
+		time.Sleep(time.Millisecond)
+		lb.reserve("100", 0)
+		if len(lb.chats) != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestLimitedBotRetryAfter(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{
+		`{"ok": false, "error_code": 429, "parameters": {"retry_after": 5}}`,
+		`{"ok": true, "result": {"message_id": 2468}}`,
+	}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	lb := NewLimitedBot(tb)
+	sleeps := []time.Duration{}
+	lb.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+
+	resp, err := lb.SendMessage(context.Background(), MessageRequest{ChatId: 100, Text: "Text"})
+
+	t.Run("Error is nil", func(t *testing.T) {
+		if err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Message sent after retry", func(t *testing.T) {
+		if !resp.Ok || resp.Result.MessageId != 2468 || client.Calls != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Retry after honoured", func(t *testing.T) {
+		if len(sleeps) == 0 || sleeps[len(sleeps)-1] < 4*time.Second {
+			t.Fail()
+		}
+	})
+
+	t.Run("Other chats held", func(t *testing.T) {
+		if slot := lb.reserve("200", 0); time.Until(slot) < 4*time.Second {
+			t.Fail()
+		}
+	})
+}
+
+func TestLimitedBotMaxRetries(t *testing.T) {
+	client := &seqClientMock{Bodies: []string{
+		`{"ok": false, "error_code": 429, "parameters": {"retry_after": 1}}`,
+	}}
+	tb, _ := NewSimpleBot("***Token***", client)
+	lb := NewLimitedBot(tb)
+	lb.MaxRetries = 2
+	lb.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	res := <-lb.SendMessageAsync(context.Background(), MessageRequest{ChatId: 100, Text: "Text"})
+
+	t.Run("Last response returned", func(t *testing.T) {
+		if !IsTooManyRequests(res.Err) || res.Response.ErrorCode != TooManyRequestsCode {
+			t.Fail()
+		}
+	})
+
+	t.Run("Retries count", func(t *testing.T) {
+		if client.Calls != 3 {
+			t.Fail()
+		}
+	})
+}
+
+func TestLimitedBotRetryUpload(t *testing.T) {
+	newClient := func() *seqClientMock {
+		return &seqClientMock{Bodies: []string{
+			`{"ok": false, "error_code": 429, "parameters": {"retry_after": 1}}`,
+			`{"ok": true, "result": {"message_id": 2468}}`,
+		}}
+	}
+	send := func(client *seqClientMock, reader io.Reader) error {
+		tb, _ := NewSimpleBot("***Token***", client)
+		lb := NewLimitedBot(tb)
+		lb.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+		req := SendDocumentRequest{ChatId: 100, Document: InputFile{Name: "data.json", Reader: reader}}
+		_, err := lb.SendMessage(context.Background(), req)
+		return err
+	}
+
+	t.Run("Seekable file sent again", func(t *testing.T) {
+		client := newClient()
+		err := send(client, strings.NewReader(`{"data": 1}`))
+		if err != nil || client.Calls != 2 || !strings.Contains(client.Requests[1], `{"data": 1}`) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Stream is not retried", func(t *testing.T) {
+		client := newClient()
+		err := send(client, io.MultiReader(strings.NewReader(`{"data": 1}`)))
+		if !IsTooManyRequests(err) || client.Calls != 1 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package telegram
+
+import "context"
+
+const (
+	ChatMemberCreator       = "creator"
+	ChatMemberAdministrator = "administrator"
+	ChatMemberMember        = "member"
+	ChatMemberRestricted    = "restricted"
+	ChatMemberLeft          = "left"
+	ChatMemberKicked        = "kicked"
+)
+
+type ChatMember struct {
+	Status      string `json:"status"`
+	User        *User  `json:"user"`
+	IsAnonymous bool   `json:"is_anonymous"`
+	CustomTitle string `json:"custom_title"`
+	IsMember    bool   `json:"is_member"`
+	UntilDate   int    `json:"until_date"`
+}
+
+func (cm ChatMember) InChat() bool {
+	switch cm.Status {
+	case ChatMemberCreator, ChatMemberAdministrator, ChatMemberMember:
+		return true
+	case ChatMemberRestricted:
+		return cm.IsMember
+	}
+	return false
+}
+
+type ChatMemberUpdated struct {
+	Chat          Chat       `json:"chat"`
+	From          *User      `json:"from"`
+	Date          int        `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+}
+
+func (cmu ChatMemberUpdated) Joined() bool {
+	return !cmu.OldChatMember.InChat() && cmu.NewChatMember.InChat()
+}
+
+func (cmu ChatMemberUpdated) Left() bool {
+	return cmu.OldChatMember.InChat() && !cmu.NewChatMember.InChat()
+}
+
+type BaseChatMemberHandler struct {
+	Bot             Bot
+	MyMemberHandler ChatMemberFunc
+	MemberHandler   ChatMemberFunc
+}
+
+func (h *BaseChatMemberHandler) ProceedMyChatMember(ctx context.Context, cmu *ChatMemberUpdated) error {
+	if h.MyMemberHandler == nil {
+		return nil
+	}
+	return h.MyMemberHandler(ctx, cmu)
+}
+
+func (h *BaseChatMemberHandler) ProceedChatMember(ctx context.Context, cmu *ChatMemberUpdated) error {
+	if h.MemberHandler == nil {
+		return nil
+	}
+	return h.MemberHandler(ctx, cmu)
+}
@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestChatMemberInChat(t *testing.T) {
+	tests := map[string]struct {
+		member ChatMember
+		want   bool
+	}{
+		"Creator":               {member: ChatMember{Status: ChatMemberCreator}, want: true},
+		"Administrator":         {member: ChatMember{Status: ChatMemberAdministrator}, want: true},
+		"Member":                {member: ChatMember{Status: ChatMemberMember}, want: true},
+		"Restricted member":     {member: ChatMember{Status: ChatMemberRestricted, IsMember: true}, want: true},
+		"Restricted not member": {member: ChatMember{Status: ChatMemberRestricted}, want: false},
+		"Left":                  {member: ChatMember{Status: ChatMemberLeft}, want: false},
+		"Kicked":                {member: ChatMember{Status: ChatMemberKicked}, want: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.member.InChat() != test.want {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestChatMemberHandlerProceed(t *testing.T) {
+	var my, member *ChatMemberUpdated
+	uh := BaseUpdateHandler{}
+	uh.AppendChatMemberHandlers(&BaseChatMemberHandler{
+		MyMemberHandler: func(ctx context.Context, cmu *ChatMemberUpdated) error {
+			my = cmu
+			return nil
+		},
+		MemberHandler: func(ctx context.Context, cmu *ChatMemberUpdated) error {
+			member = cmu
+			return nil
+		}})
+
+	resp := UpdateResponse{}
+	resp.Parse(strings.NewReader(`{"ok": true, "result": [
+		{"update_id": 1, "my_chat_member": {"chat": {"id": -100123, "type": "supergroup"},
+			"from": {"id": 586350636, "first_name": "Alexey"}, "date": 1640000000,
+			"old_chat_member": {"status": "left", "user": {"id": 42, "is_bot": true, "first_name": "Bot"}},
+			"new_chat_member": {"status": "member", "user": {"id": 42, "is_bot": true, "first_name": "Bot"}}}},
+		{"update_id": 2, "chat_member": {"chat": {"id": -100123, "type": "supergroup"},
+			"from": {"id": 123, "first_name": "Steve"}, "date": 1640000000,
+			"old_chat_member": {"status": "member", "user": {"id": 123, "first_name": "Steve"}},
+			"new_chat_member": {"status": "left", "user": {"id": 123, "first_name": "Steve"}}}}]}`))
+	for _, update := range resp.Result {
+		uh.ProceedUpdate(context.Background(), nil, update)
+	}
+
+	t.Run("Bot joined", func(t *testing.T) {
+		if my == nil || !my.Joined() || my.Left() || my.From.Id != 586350636 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Member left", func(t *testing.T) {
+		if member == nil || !member.Left() || member.NewChatMember.User.Id != 123 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Chat id", func(t *testing.T) {
+		if resp.Result[0].ChatId() != -100123 || resp.Result[1].ChatId() != -100123 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type UpdateFunc func(ctx context.Context, tb Bot, update Update) error
+type Middleware func(next UpdateFunc) UpdateFunc
+
+func NewMiddlewareHandler(uh UpdateHandler, mws ...Middleware) *MiddlewareHandler {
+	return &MiddlewareHandler{UpdateHandler: uh, Middlewares: mws}
+}
+
+type MiddlewareHandler struct {
+	UpdateHandler
+	Middlewares []Middleware
+}
+
+func (h *MiddlewareHandler) Use(mws ...Middleware) {
+	h.Middlewares = append(h.Middlewares, mws...)
+}
+
+func (h *MiddlewareHandler) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	next := h.UpdateHandler.ProceedUpdate
+	for i := len(h.Middlewares) - 1; i >= 0; i-- {
+		next = h.Middlewares[i](next)
+	}
+	return next(ctx, tb, update)
+}
+
+func updateFields(update Update) log.Fields {
+	return log.Fields{
+		"package":   "telegram",
+		"update_id": update.UpdateId,
+		"type":      update.Type(),
+		"chat_id":   update.ChatId(),
+		"user_id":   update.UserId(),
+	}
+}
+
+func RecoverMiddleware() Middleware {
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("panic while proceeding update %d: %v", update.UpdateId, r)
+					log.WithFields(updateFields(update)).WithFields(log.Fields{
+						"function": "RecoverMiddleware",
+						"error":    err,
+						"stack":    string(debug.Stack()),
+					}).Error("update handler panic")
+				}
+			}()
+			return next(ctx, tb, update)
+		}
+	}
+}
+
+func LoggingMiddleware() Middleware {
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) error {
+			entry := log.WithFields(updateFields(update)).WithField("function", "LoggingMiddleware")
+			entry.Debug("proceed update")
+			err := next(ctx, tb, update)
+			if err != nil {
+				entry.WithField("error", err).Error("proceed update error")
+			}
+			return err
+		}
+	}
+}
+
+func TimingMiddleware(slow time.Duration) Middleware {
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) error {
+			start := time.Now()
+			err := next(ctx, tb, update)
+			elapsed := time.Since(start)
+			entry := log.WithFields(updateFields(update)).WithFields(log.Fields{
+				"function": "TimingMiddleware",
+				"duration": elapsed,
+			})
+			if slow > 0 && elapsed > slow {
+				entry.Warn("slow update")
+			} else {
+				entry.Debug("update proceeded")
+			}
+			return err
+		}
+	}
+}
+
+type floodWindow struct {
+	start time.Time
+	count int
+}
+
+// FloodMiddleware drops the updates of users who send more than limit updates per period.
+// Payments are never dropped, callback queries are answered with the text.
+func FloodMiddleware(limit int, per time.Duration, text string) Middleware {
+	var (
+		mu    sync.Mutex
+		swept time.Time
+	)
+	windows := make(map[int]*floodWindow)
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) error {
+			uid := update.UserId()
+			if uid == 0 || limit <= 0 || isPayment(update) {
+				return next(ctx, tb, update)
+			}
+			now := time.Now()
+			mu.Lock()
+			if now.Sub(swept) >= per {
+				swept = now
+				for id, w := range windows {
+					if now.Sub(w.start) >= per {
+						delete(windows, id)
+					}
+				}
+			}
+			w, ok := windows[uid]
+			if !ok || now.Sub(w.start) >= per {
+				w = &floodWindow{start: now}
+				windows[uid] = w
+			}
+			w.count++
+			flood := w.count > limit
+			mu.Unlock()
+			if flood {
+				log.WithFields(updateFields(update)).WithField("function", "FloodMiddleware").
+					Warn("update dropped by flood control")
+				if update.CallbackQuery != nil {
+					_, err := update.CallbackQuery.Answer(ctx, tb, text, AnswerCallbackQueryRequest{})
+					return err
+				}
+				return nil
+			}
+			return next(ctx, tb, update)
+		}
+	}
+}
+
+func isPayment(update Update) bool {
+	return update.PreCheckoutQuery != nil || (update.Message != nil && update.Message.SuccessfulPayment != nil)
+}
+
+type BanList map[int]bool
+
+func (bl BanList) IsBanned(ctx context.Context, uid int) (bool, error) {
+	return bl[uid], nil
+}
+
+func BanMiddleware(bc BanChecker) Middleware {
+	return func(next UpdateFunc) UpdateFunc {
+		return func(ctx context.Context, tb Bot, update Update) error {
+			uid := update.UserId()
+			if uid != 0 {
+				banned, err := bc.IsBanned(ctx, uid)
+				if err != nil {
+					return err
+				}
+				if banned {
+					log.WithFields(updateFields(update)).WithField("function", "BanMiddleware").
+						Debug("update from banned user ignored")
+					return nil
+				}
+			}
+			return next(ctx, tb, update)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareHandlerOrder(t *testing.T) {
+	calls := []string{}
+	mark := func(name string) Middleware {
+		return func(next UpdateFunc) UpdateFunc {
+			return func(ctx context.Context, tb Bot, update Update) error {
+				calls = append(calls, name)
+				return next(ctx, tb, update)
+			}
+		}
+	}
+	uh := UpdateHandlerMock{proceed: func(update Update) { calls = append(calls, "handler") }}
+	mh := NewMiddlewareHandler(uh, mark("first"))
+	mh.Use(mark("second"))
+	mh.ProceedUpdate(context.Background(), nil, Update{})
+
+	want := []string{"first", "second", "handler"}
+	if len(calls) != len(want) {
+		t.FailNow()
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fail()
+		}
+	}
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	uh := UpdateHandlerMock{proceed: func(update Update) { panic("boom") }}
+	mh := NewMiddlewareHandler(uh, RecoverMiddleware())
+	if err := mh.ProceedUpdate(context.Background(), nil, Update{UpdateId: 1}); err == nil {
+		t.Fail()
+	}
+}
+
+func TestLoggingAndTimingMiddleware(t *testing.T) {
+	herr := errors.New("handler error")
+	mh := NewMiddlewareHandler(UpdateHandlerMock{err: herr}, LoggingMiddleware(), TimingMiddleware(time.Nanosecond))
+	if err := mh.ProceedUpdate(context.Background(), nil, Update{UpdateId: 1}); err != herr {
+		t.Fail()
+	}
+}
+
+func TestFloodMiddleware(t *testing.T) {
+	count := map[int]int{}
+	uh := UpdateHandlerMock{proceed: func(update Update) { count[update.UserId()]++ }}
+	mh := NewMiddlewareHandler(uh, FloodMiddleware(2, time.Hour, "Flood"))
+	for i := 0; i < 5; i++ {
+		mh.ProceedUpdate(context.Background(), nil, Update{Message: &Message{From: &User{Id: 10}}})
+	}
+	mh.ProceedUpdate(context.Background(), nil, Update{Message: &Message{From: &User{Id: 20}}})
+
+	t.Run("Messages limited", func(t *testing.T) {
+		if count[10] != 2 || count[20] != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Payments not limited", func(t *testing.T) {
+		mh.ProceedUpdate(context.Background(), nil, Update{PreCheckoutQuery: &PreCheckoutQuery{From: &User{Id: 10}}})
+		mh.ProceedUpdate(context.Background(), nil, Update{Message: &Message{From: &User{Id: 10},
+			SuccessfulPayment: &SuccessfulPayment{}}})
+		if count[10] != 4 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Callback answered", func(t *testing.T) {
+		client := &seqClientMock{Bodies: []string{`{"ok":true,"result":true}`}}
+		sb, _ := NewSimpleBot("***Token***", client)
+		mh.ProceedUpdate(context.Background(), sb, Update{CallbackQuery: &CallbackQuery{Id: "1", From: &User{Id: 10}}})
+		if count[10] != 4 || len(client.Requests) != 1 || !strings.Contains(client.Requests[0], "answerCallbackQuery") ||
+			!strings.Contains(client.Requests[0], "text=Flood") {
+			t.Fail()
+		}
+	})
+}
+
+func TestBanMiddleware(t *testing.T) {
+	count := map[int]int{}
+	uh := UpdateHandlerMock{proceed: func(update Update) { count[update.UserId()]++ }}
+	mh := NewMiddlewareHandler(uh, BanMiddleware(BanList{10: true}))
+	mh.ProceedUpdate(context.Background(), nil, Update{CallbackQuery: &CallbackQuery{From: &User{Id: 10}}})
+	mh.ProceedUpdate(context.Background(), nil, Update{CallbackQuery: &CallbackQuery{From: &User{Id: 20}}})
+
+	if count[10] != 0 || count[20] != 1 {
+		t.Fail()
+	}
+}
+
+func TestUpdateHandlerStopAtFirstError(t *testing.T) {
+	proceeded := 0
+	herr := errors.New("handler error")
+	uh := BaseUpdateHandler{}
+	uh.AppendMessageHandlers(
+		&BaseMessageHandler{Handler: func(ctx context.Context, m *Message) error { return herr }},
+		&BaseMessageHandler{Handler: func(ctx context.Context, m *Message) error {
+			proceeded++
+			return nil
+		}})
+
+	if err := uh.ProceedUpdate(context.Background(), nil, Update{Message: &Message{}}); err != herr || proceeded != 0 {
+		t.Fail()
+	}
+}
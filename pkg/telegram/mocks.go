@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"io"
 	"log"
 	"net/http"
@@ -32,10 +33,14 @@ func (client httpClientMock) Do(httpRequest *http.Request) (*http.Response, erro
 }
 
 type UpdateHandlerMock struct {
-	err error
+	err     error
+	proceed func(update Update)
 }
 
-func (h UpdateHandlerMock) ProceedUpdate(tb Bot, update Update) error {
+func (h UpdateHandlerMock) ProceedUpdate(ctx context.Context, tb Bot, update Update) error {
+	if h.proceed != nil {
+		h.proceed(update)
+	}
 	return h.err
 }
 
@@ -43,10 +48,38 @@ func (h UpdateHandlerMock) AppendMessageHandlers(...MessageHandler) {
 
 }
 
+func (h UpdateHandlerMock) AppendEditedMessageHandlers(...MessageHandler) {
+
+}
+
+func (h UpdateHandlerMock) AppendChannelPostHandlers(...MessageHandler) {
+
+}
+
+func (h UpdateHandlerMock) AppendEditedChannelPostHandlers(...MessageHandler) {
+
+}
+
 func (h UpdateHandlerMock) AppendCallbackHandlers(...CallbackHandler) {
 
 }
 
+func (h UpdateHandlerMock) AppendInlineQueryHandlers(...InlineQueryHandler) {
+
+}
+
+func (h UpdateHandlerMock) AppendPreCheckoutQueryHandlers(...PreCheckoutQueryHandler) {
+
+}
+
+func (h UpdateHandlerMock) AppendChatMemberHandlers(...ChatMemberHandler) {
+
+}
+
+func (h UpdateHandlerMock) AppendPollHandlers(...PollHandler) {
+
+}
+
 type LoggerMock struct {
 }
 
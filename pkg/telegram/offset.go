@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+)
+
+func NewMemoryOffsetStore() OffsetStore {
+	return &MemoryOffsetStore{}
+}
+
+type MemoryOffsetStore struct {
+	offset int
+	sync.Mutex
+}
+
+func (rep *MemoryOffsetStore) Get(ctx context.Context) (int, error) {
+	rep.Lock()
+	defer rep.Unlock()
+	return rep.offset, nil
+}
+
+func (rep *MemoryOffsetStore) Set(ctx context.Context, offset int) error {
+	rep.Lock()
+	rep.offset = offset
+	rep.Unlock()
+	return nil
+}
@@ -0,0 +1,64 @@
+package telegram
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+type OrderInfo struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	Email       string `json:"email"`
+}
+
+type PreCheckoutQuery struct {
+	Id               string     `json:"id"`
+	From             *User      `json:"from"`
+	Currency         string     `json:"currency"`
+	TotalAmount      int        `json:"total_amount"`
+	InvoicePayload   string     `json:"invoice_payload"`
+	ShippingOptionId string     `json:"shipping_option_id"`
+	OrderInfo        *OrderInfo `json:"order_info"`
+}
+
+func (pq PreCheckoutQuery) Answer(ctx context.Context, tb Bot, req AnswerPreCheckoutQueryRequest) (*MessageResponse, error) {
+	req.PreCheckoutQueryId = pq.Id
+	return tb.SendMessage(ctx, req)
+}
+
+type SuccessfulPayment struct {
+	Currency                string     `json:"currency"`
+	TotalAmount             int        `json:"total_amount"`
+	InvoicePayload          string     `json:"invoice_payload"`
+	ShippingOptionId        string     `json:"shipping_option_id"`
+	OrderInfo               *OrderInfo `json:"order_info"`
+	TelegramPaymentChargeId string     `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeId string     `json:"provider_payment_charge_id"`
+}
+
+type AnswerPreCheckoutQueryRequest struct {
+	PreCheckoutQueryId string `json:"pre_checkout_query_id"`
+	Ok                 bool   `json:"ok"`
+	ErrorMessage       string `json:"error_message"`
+}
+
+func (req AnswerPreCheckoutQueryRequest) GetParams() (val url.Values, method string, err error) {
+	method = "answerPreCheckoutQuery"
+	val = url.Values{}
+	val.Add("pre_checkout_query_id", req.PreCheckoutQueryId)
+	val.Add("ok", strconv.FormatBool(req.Ok))
+	if !req.Ok && req.ErrorMessage != "" {
+		val.Add("error_message", req.ErrorMessage)
+	}
+	return
+}
+
+type BasePreCheckoutQueryHandler struct {
+	Bot     Bot
+	Handler PreCheckoutQueryFunc
+}
+
+func (h *BasePreCheckoutQueryHandler) ProceedPreCheckoutQuery(ctx context.Context, pq *PreCheckoutQuery) error {
+	return h.Handler(ctx, pq)
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPreCheckoutQueryHandlerProceed(t *testing.T) {
+	payload := ""
+	uh := BaseUpdateHandler{}
+	uh.AppendPreCheckoutQueryHandlers(&BasePreCheckoutQueryHandler{
+		Handler: func(ctx context.Context, pq *PreCheckoutQuery) error {
+			payload = pq.InvoicePayload
+			return nil
+		}})
+
+	resp := UpdateResponse{}
+	resp.Parse(strings.NewReader(`{"ok": true, "result": [{"update_id": 123130162,
+		"pre_checkout_query": {"id": "7890", "from": {"id": 586350636, "first_name": "Alexey"},
+		"currency": "RUB", "total_amount": 50000, "invoice_payload": "res_pay_paid_abc"}}]}`))
+	uh.ProceedUpdate(context.Background(), nil, resp.Result[0])
+
+	t.Run("Pre checkout query proceeded", func(t *testing.T) {
+		if payload != "res_pay_paid_abc" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Chat id from user", func(t *testing.T) {
+		if resp.Result[0].ChatId() != 586350636 {
+			t.Fail()
+		}
+	})
+}
+
+func TestParseSuccessfulPayment(t *testing.T) {
+	resp := UpdateResponse{}
+	resp.Parse(strings.NewReader(`{"ok": true, "result": [{"update_id": 123130163,
+		"message": {"message_id": 42, "from": {"id": 586350636, "first_name": "Alexey"},
+		"chat": {"id": 586350636, "type": "private"}, "date": 1640000000,
+		"successful_payment": {"currency": "RUB", "total_amount": 50000, "invoice_payload": "res_pay_paid_abc",
+		"telegram_payment_charge_id": "tg_charge", "provider_payment_charge_id": "provider_charge"}}}]}`))
+
+	pay := resp.Result[0].Message.SuccessfulPayment
+	if pay == nil || pay.TotalAmount != 50000 || pay.TelegramPaymentChargeId != "tg_charge" ||
+		pay.ProviderPaymentChargeId != "provider_charge" {
+		t.Fail()
+	}
+}
+
+func TestGetAnswerPreCheckoutQueryParams(t *testing.T) {
+	tests := map[string]struct {
+		request AnswerPreCheckoutQueryRequest
+		want    string
+	}{
+		"Ok": {
+			request: AnswerPreCheckoutQueryRequest{PreCheckoutQueryId: "7890", Ok: true, ErrorMessage: "ignored"},
+			want:    "ok=true&pre_checkout_query_id=7890",
+		},
+		"Error": {
+			request: AnswerPreCheckoutQueryRequest{PreCheckoutQueryId: "7890", ErrorMessage: "Sold out"},
+			want:    "error_message=Sold+out&ok=false&pre_checkout_query_id=7890",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, method, err := test.request.GetParams()
+			if err != nil || method != "answerPreCheckoutQuery" {
+				t.Fail()
+			}
+			if values.Encode() != test.want {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	PollRegular = "regular"
+	PollQuiz    = "quiz"
+)
+
+type PollOption struct {
+	Text       string `json:"text"`
+	VoterCount int    `json:"voter_count"`
+}
+
+type Poll struct {
+	Id                    string       `json:"id"`
+	Question              string       `json:"question"`
+	Options               []PollOption `json:"options"`
+	TotalVoterCount       int          `json:"total_voter_count"`
+	IsClosed              bool         `json:"is_closed"`
+	IsAnonymous           bool         `json:"is_anonymous"`
+	Type                  string       `json:"type"`
+	AllowsMultipleAnswers bool         `json:"allows_multiple_answers"`
+}
+
+type PollAnswer struct {
+	PollId    string `json:"poll_id"`
+	VoterChat *Chat  `json:"voter_chat"`
+	User      *User  `json:"user"`
+	OptionIds []int  `json:"option_ids"`
+}
+
+func (pa PollAnswer) Retracted() bool {
+	return len(pa.OptionIds) == 0
+}
+
+func (pa PollAnswer) Voted(option int) bool {
+	for _, id := range pa.OptionIds {
+		if id == option {
+			return true
+		}
+	}
+	return false
+}
+
+type SendPollRequest struct {
+	ChatId                interface{} `json:"chat_id"`
+	Question              string      `json:"question"`
+	Options               []string    `json:"options"`
+	IsAnonymous           bool        `json:"is_anonymous"`
+	Type                  string      `json:"type"`
+	AllowsMultipleAnswers bool        `json:"allows_multiple_answers"`
+	IsClosed              bool        `json:"is_closed"`
+	DisableNotification   bool        `json:"disable_notification"`
+	ReplyToMessageId      int         `json:"reply_to_message_id"`
+	ReplyMarkup           interface{} `json:"reply_markup"`
+}
+
+func (req SendPollRequest) GetParams() (val url.Values, method string, err error) {
+	method = "sendPoll"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	val.Add("question", req.Question)
+	data, err := json.Marshal(req.Options)
+	if err != nil {
+		return nil, "", err
+	}
+	val.Add("options", string(data))
+	val.Add("is_anonymous", strconv.FormatBool(req.IsAnonymous))
+	if req.Type != "" {
+		val.Add("type", req.Type)
+	}
+	if req.AllowsMultipleAnswers {
+		val.Add("allows_multiple_answers", strconv.FormatBool(req.AllowsMultipleAnswers))
+	}
+	if req.IsClosed {
+		val.Add("is_closed", strconv.FormatBool(req.IsClosed))
+	}
+	if req.DisableNotification {
+		val.Add("disable_notification", strconv.FormatBool(req.DisableNotification))
+	}
+	if req.ReplyToMessageId > 0 {
+		val.Add("reply_to_message_id", strconv.Itoa(req.ReplyToMessageId))
+	}
+	if req.ReplyMarkup != nil {
+		data, err := json.Marshal(req.ReplyMarkup)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("reply_markup", string(data))
+	}
+	return
+}
+
+type StopPollRequest struct {
+	ChatId      interface{} `json:"chat_id"`
+	MessageId   int         `json:"message_id"`
+	ReplyMarkup interface{} `json:"reply_markup"`
+}
+
+func (req StopPollRequest) GetParams() (val url.Values, method string, err error) {
+	method = "stopPoll"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	val.Add("message_id", strconv.Itoa(req.MessageId))
+	if req.ReplyMarkup != nil {
+		data, err := json.Marshal(req.ReplyMarkup)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("reply_markup", string(data))
+	}
+	return
+}
+
+type BasePollHandler struct {
+	Bot           Bot
+	PollHandler   PollFunc
+	AnswerHandler PollAnswerFunc
+}
+
+func (h *BasePollHandler) ProceedPoll(ctx context.Context, poll *Poll) error {
+	if h.PollHandler == nil {
+		return nil
+	}
+	return h.PollHandler(ctx, poll)
+}
+
+func (h *BasePollHandler) ProceedPollAnswer(ctx context.Context, pa *PollAnswer) error {
+	if h.AnswerHandler == nil {
+		return nil
+	}
+	return h.AnswerHandler(ctx, pa)
+}
@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPollParams(t *testing.T) {
+	tests := map[string]struct {
+		request Request
+		method  string
+		want    map[string]string
+	}{
+		"Send poll": {
+			request: SendPollRequest{ChatId: -100, Question: "Who's in?", Options: []string{"Yes", "No"},
+				DisableNotification: true},
+			method: "sendPoll",
+			want: map[string]string{"chat_id": "-100", "question": "Who's in?", "options": `["Yes","No"]`,
+				"is_anonymous": "false", "type": "", "disable_notification": "true", "reply_markup": ""},
+		},
+		"Send quiz": {
+			request: SendPollRequest{ChatId: 10, Question: "Q", Options: []string{"A", "B"}, IsAnonymous: true,
+				Type: PollQuiz, AllowsMultipleAnswers: true},
+			method: "sendPoll",
+			want: map[string]string{"chat_id": "10", "is_anonymous": "true", "type": "quiz",
+				"allows_multiple_answers": "true"},
+		},
+		"Stop poll": {
+			request: StopPollRequest{ChatId: -100, MessageId: 12},
+			method:  "stopPoll",
+			want:    map[string]string{"chat_id": "-100", "message_id": "12", "reply_markup": ""},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			val, method, err := test.request.GetParams()
+			if err != nil || method != test.method {
+				t.FailNow()
+			}
+			for key, want := range test.want {
+				if val.Get(key) != want {
+					t.Errorf("%s: got %q, want %q", key, val.Get(key), want)
+				}
+			}
+		})
+	}
+}
+
+func TestPollHandlerProceed(t *testing.T) {
+	var (
+		poll   *Poll
+		answer *PollAnswer
+	)
+	uh := BaseUpdateHandler{}
+	uh.AppendPollHandlers(&BasePollHandler{
+		PollHandler: func(ctx context.Context, p *Poll) error {
+			poll = p
+			return nil
+		},
+		AnswerHandler: func(ctx context.Context, pa *PollAnswer) error {
+			answer = pa
+			return nil
+		}})
+
+	resp := UpdateResponse{}
+	resp.Parse(strings.NewReader(`{"ok": true, "result": [
+		{"update_id": 1, "poll": {"id": "5431", "question": "Who's in?", "total_voter_count": 1,
+			"options": [{"text": "Yes", "voter_count": 1}, {"text": "No", "voter_count": 0}],
+			"is_closed": false, "is_anonymous": false, "type": "regular", "allows_multiple_answers": false}},
+		{"update_id": 2, "poll_answer": {"poll_id": "5431",
+			"user": {"id": 123, "first_name": "Steve"}, "option_ids": [0]}},
+		{"update_id": 3, "poll_answer": {"poll_id": "5431",
+			"user": {"id": 123, "first_name": "Steve"}, "option_ids": []}}]}`))
+	uh.ProceedUpdate(context.Background(), nil, resp.Result[0])
+	uh.ProceedUpdate(context.Background(), nil, resp.Result[1])
+
+	t.Run("Poll", func(t *testing.T) {
+		if poll == nil || poll.Id != "5431" || len(poll.Options) != 2 || poll.Options[0].VoterCount != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Answer", func(t *testing.T) {
+		if answer == nil || answer.User.Id != 123 || !answer.Voted(0) || answer.Voted(1) || answer.Retracted() {
+			t.Fail()
+		}
+	})
+
+	t.Run("Retracted", func(t *testing.T) {
+		if !resp.Result[2].PollAnswer.Retracted() {
+			t.Fail()
+		}
+	})
+
+	t.Run("Update routing", func(t *testing.T) {
+		if resp.Result[0].Type() != "poll" || resp.Result[1].Type() != "poll_answer" ||
+			resp.Result[1].ChatId() != 123 || resp.Result[1].UserId() != 123 {
+			t.Fail()
+		}
+	})
+}
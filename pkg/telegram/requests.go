@@ -27,8 +27,12 @@ func (req UpdatesRequest) GetParams() (val url.Values, method string, err error)
 	if req.Timeout > 0 {
 		val.Add("timeout", strconv.Itoa(req.Timeout))
 	}
-	for _, au := range req.AllowedUpdates {
-		val.Add("allowed_updates", au)
+	if len(req.AllowedUpdates) > 0 {
+		data, err := json.Marshal(req.AllowedUpdates)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("allowed_updates", string(data))
 	}
 	return
 }
@@ -86,7 +90,7 @@ func (req MessageRequest) GetParams() (val url.Values, method string, err error)
 type EditMessageTextRequest struct {
 	ChatId                interface{}     `json:"chat_id"`
 	MessageId             int             `json:"message_id"`
-	InlineMessageId       int             `json:"inline_message_id"`
+	InlineMessageId       string          `json:"inline_message_id"`
 	Text                  string          `json:"text"`
 	ParseMode             string          `json:"parse_mode"`
 	Entities              []MessageEntity `json:"entities"`
@@ -97,9 +101,12 @@ type EditMessageTextRequest struct {
 func (req EditMessageTextRequest) GetParams() (val url.Values, method string, err error) {
 	method = "editMessageText"
 	val = url.Values{}
-	val.Add("chat_id", fmt.Sprint(req.ChatId))
-	val.Add("message_id", strconv.Itoa(req.MessageId))
-	val.Add("inline_message_id", strconv.Itoa(req.InlineMessageId))
+	if req.InlineMessageId != "" {
+		val.Add("inline_message_id", req.InlineMessageId)
+	} else {
+		val.Add("chat_id", fmt.Sprint(req.ChatId))
+		val.Add("message_id", strconv.Itoa(req.MessageId))
+	}
 	val.Add("text", req.Text)
 	if req.ParseMode != "" {
 		val.Add("parse_mode", req.ParseMode)
@@ -233,3 +240,127 @@ func (req *InvoiceRequest) GetParams() (val url.Values, method string, err error
 	}
 	return
 }
+
+type EditMessageReplyMarkupRequest struct {
+	ChatId          interface{} `json:"chat_id"`
+	MessageId       int         `json:"message_id"`
+	InlineMessageId string      `json:"inline_message_id"`
+	ReplyMarkup     interface{} `json:"reply_markup"`
+}
+
+func (req EditMessageReplyMarkupRequest) GetParams() (val url.Values, method string, err error) {
+	method = "editMessageReplyMarkup"
+	val = url.Values{}
+	if req.InlineMessageId != "" {
+		val.Add("inline_message_id", req.InlineMessageId)
+	} else {
+		val.Add("chat_id", fmt.Sprint(req.ChatId))
+		val.Add("message_id", strconv.Itoa(req.MessageId))
+	}
+	if req.ReplyMarkup != nil {
+		data, err := json.Marshal(req.ReplyMarkup)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("reply_markup", string(data))
+	}
+	return
+}
+
+type PinChatMessageRequest struct {
+	ChatId              interface{} `json:"chat_id"`
+	MessageId           int         `json:"message_id"`
+	DisableNotification bool        `json:"disable_notification"`
+}
+
+func (req PinChatMessageRequest) GetParams() (val url.Values, method string, err error) {
+	method = "pinChatMessage"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	val.Add("message_id", strconv.Itoa(req.MessageId))
+	if req.DisableNotification {
+		val.Add("disable_notification", strconv.FormatBool(req.DisableNotification))
+	}
+	return
+}
+
+type UnpinChatMessageRequest struct {
+	ChatId    interface{} `json:"chat_id"`
+	MessageId int         `json:"message_id"`
+}
+
+func (req UnpinChatMessageRequest) GetParams() (val url.Values, method string, err error) {
+	method = "unpinChatMessage"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	if req.MessageId > 0 {
+		val.Add("message_id", strconv.Itoa(req.MessageId))
+	}
+	return
+}
+
+type CopyMessageRequest struct {
+	ChatId              interface{}     `json:"chat_id"`
+	FromChatId          interface{}     `json:"from_chat_id"`
+	MessageId           int             `json:"message_id"`
+	Caption             string          `json:"caption"`
+	ParseMode           string          `json:"parse_mode"`
+	CaptionEntities     []MessageEntity `json:"caption_entities"`
+	DisableNotification bool            `json:"disable_notification"`
+	ReplyToMessageId    int             `json:"reply_to_message_id"`
+	ReplyMarkup         interface{}     `json:"reply_markup"`
+}
+
+func (req CopyMessageRequest) GetParams() (val url.Values, method string, err error) {
+	method = "copyMessage"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	val.Add("from_chat_id", fmt.Sprint(req.FromChatId))
+	val.Add("message_id", strconv.Itoa(req.MessageId))
+	if req.Caption != "" {
+		val.Add("caption", req.Caption)
+	}
+	if req.ParseMode != "" {
+		val.Add("parse_mode", req.ParseMode)
+	}
+	if len(req.CaptionEntities) > 0 {
+		data, err := json.Marshal(req.CaptionEntities)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("caption_entities", string(data))
+	}
+	if req.DisableNotification {
+		val.Add("disable_notification", strconv.FormatBool(req.DisableNotification))
+	}
+	if req.ReplyToMessageId > 0 {
+		val.Add("reply_to_message_id", strconv.Itoa(req.ReplyToMessageId))
+	}
+	if req.ReplyMarkup != nil {
+		data, err := json.Marshal(req.ReplyMarkup)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("reply_markup", string(data))
+	}
+	return
+}
+
+type ForwardMessageRequest struct {
+	ChatId              interface{} `json:"chat_id"`
+	FromChatId          interface{} `json:"from_chat_id"`
+	MessageId           int         `json:"message_id"`
+	DisableNotification bool        `json:"disable_notification"`
+}
+
+func (req ForwardMessageRequest) GetParams() (val url.Values, method string, err error) {
+	method = "forwardMessage"
+	val = url.Values{}
+	val.Add("chat_id", fmt.Sprint(req.ChatId))
+	val.Add("from_chat_id", fmt.Sprint(req.FromChatId))
+	val.Add("message_id", strconv.Itoa(req.MessageId))
+	if req.DisableNotification {
+		val.Add("disable_notification", strconv.FormatBool(req.DisableNotification))
+	}
+	return
+}
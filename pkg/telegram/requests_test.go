@@ -148,6 +148,18 @@ func TestGetEditMessageTextParams(t *testing.T) {
 				"reply_markup":             `{"inline_keyboard":[[{"text":"Button text 1","url":"","callback_data":"Data1","switch_inline_query":"","switch_inline_query_current_chat":"","pay":false},{"text":"Button text 2","url":"","callback_data":"Data2","switch_inline_query":"","switch_inline_query_current_chat":"","pay":false}]]}`,
 			},
 		},
+		"Inline message": {
+			request: &EditMessageTextRequest{
+				InlineMessageId: "AAAAAJ2uAQBVuFQ",
+				Text:            "Example of text",
+			},
+			want: map[string]string{
+				"chat_id":           "",
+				"message_id":        "",
+				"inline_message_id": "AAAAAJ2uAQBVuFQ",
+				"text":              "Example of text",
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -188,7 +200,7 @@ func TestGetUpdatesParams(t *testing.T) {
 				Timeout:        20,
 				AllowedUpdates: []string{"message", "edited_channel_post", "callback_query"},
 			},
-			want: "allowed_updates=message&allowed_updates=edited_channel_post&allowed_updates=callback_query&limit=100&offset=551&timeout=20",
+			want: "allowed_updates=%5B%22message%22%2C%22edited_channel_post%22%2C%22callback_query%22%5D&limit=100&offset=551&timeout=20",
 		},
 	}
 
@@ -358,3 +370,67 @@ func TestGetInvoiceParams(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageLifecycleParams(t *testing.T) {
+	kbd := InlineKeyboardMarkup{InlineKeyboard: [][]InlineKeyboardButton{{{Text: "Btn", CallbackData: "Data"}}}}
+	tests := map[string]struct {
+		request Request
+		method  string
+		want    map[string]string
+	}{
+		"Edit reply markup": {
+			request: EditMessageReplyMarkupRequest{ChatId: 586350636, MessageId: 12, ReplyMarkup: kbd},
+			method:  "editMessageReplyMarkup",
+			want: map[string]string{"chat_id": "586350636", "message_id": "12",
+				"reply_markup": `{"inline_keyboard":[[{"text":"Btn","url":"","callback_data":"Data","switch_inline_query":"","switch_inline_query_current_chat":"","pay":false}]]}`},
+		},
+		"Edit inline reply markup": {
+			request: EditMessageReplyMarkupRequest{InlineMessageId: "AAAAAJ2uAQBVuFQ"},
+			method:  "editMessageReplyMarkup",
+			want:    map[string]string{"chat_id": "", "message_id": "", "inline_message_id": "AAAAAJ2uAQBVuFQ", "reply_markup": ""},
+		},
+		"Pin": {
+			request: PinChatMessageRequest{ChatId: -100, MessageId: 12, DisableNotification: true},
+			method:  "pinChatMessage",
+			want:    map[string]string{"chat_id": "-100", "message_id": "12", "disable_notification": "true"},
+		},
+		"Unpin": {
+			request: UnpinChatMessageRequest{ChatId: -100, MessageId: 12},
+			method:  "unpinChatMessage",
+			want:    map[string]string{"chat_id": "-100", "message_id": "12"},
+		},
+		"Unpin latest": {
+			request: UnpinChatMessageRequest{ChatId: -100},
+			method:  "unpinChatMessage",
+			want:    map[string]string{"chat_id": "-100", "message_id": ""},
+		},
+		"Copy": {
+			request: CopyMessageRequest{ChatId: -100, FromChatId: 10, MessageId: 12, Caption: "<b>Game</b>", ParseMode: ParseModeHTML},
+			method:  "copyMessage",
+			want: map[string]string{"chat_id": "-100", "from_chat_id": "10", "message_id": "12",
+				"caption": "<b>Game</b>", "parse_mode": "HTML", "reply_markup": ""},
+		},
+		"Forward": {
+			request: ForwardMessageRequest{ChatId: -100, FromChatId: 10, MessageId: 12},
+			method:  "forwardMessage",
+			want:    map[string]string{"chat_id": "-100", "from_chat_id": "10", "message_id": "12", "disable_notification": ""},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, method, err := test.request.GetParams()
+			if err != nil {
+				t.Fail()
+			}
+			if method != test.method {
+				t.Fail()
+			}
+			for name, val := range test.want {
+				if values.Get(name) != val {
+					t.Fail()
+				}
+			}
+		})
+	}
+}
@@ -14,10 +14,35 @@ type UpdateResponse struct {
 }
 
 type MessageResponse struct {
-	Ok         bool               `json:"ok"`
-	Result     Message            `json:"result"`
-	ErrorCode  int                `json:"error_code"`
-	Parameters ResponseParameters `json:"parameters"`
+	Ok          bool               `json:"ok"`
+	Result      Message            `json:"result"`
+	Description string             `json:"description"`
+	ErrorCode   int                `json:"error_code"`
+	Parameters  ResponseParameters `json:"parameters"`
+}
+
+type MessagesResponse struct {
+	Ok          bool               `json:"ok"`
+	Result      []Message          `json:"result"`
+	Description string             `json:"description"`
+	ErrorCode   int                `json:"error_code"`
+	Parameters  ResponseParameters `json:"parameters"`
+}
+
+type UserResponse struct {
+	Ok          bool               `json:"ok"`
+	Result      User               `json:"result"`
+	Description string             `json:"description"`
+	ErrorCode   int                `json:"error_code"`
+	Parameters  ResponseParameters `json:"parameters"`
+}
+
+type BoolResponse struct {
+	Ok          bool               `json:"ok"`
+	Result      bool               `json:"result"`
+	Description string             `json:"description"`
+	ErrorCode   int                `json:"error_code"`
+	Parameters  ResponseParameters `json:"parameters"`
 }
 
 type ResponseParameters struct {
@@ -34,6 +59,66 @@ func (update *UpdateResponse) Parse(reader io.Reader) error {
 	return ParseJson(update, reader)
 }
 
+func (update *UpdateResponse) Error() error {
+	if update.Ok {
+		return nil
+	}
+	return APIError{Code: update.ErrorCode, Description: update.Description, Parameters: update.Parameters}
+}
+
+// Parse skips results which are not messages, e.g. true of edits of inline messages,
+// pins and other requests sent through SendMessage.
 func (message *MessageResponse) Parse(reader io.Reader) error {
-	return ParseJson(message, reader)
+	var raw struct {
+		MessageResponse
+		Result json.RawMessage `json:"result"`
+	}
+	if err := ParseJson(&raw, reader); err != nil {
+		return err
+	}
+	*message = raw.MessageResponse
+	if len(raw.Result) > 0 && raw.Result[0] == '{' {
+		return json.Unmarshal(raw.Result, &message.Result)
+	}
+	return nil
+}
+
+func (message *MessageResponse) Error() error {
+	if message.Ok {
+		return nil
+	}
+	return APIError{Code: message.ErrorCode, Description: message.Description, Parameters: message.Parameters}
+}
+
+func (messages *MessagesResponse) Parse(reader io.Reader) error {
+	return ParseJson(messages, reader)
+}
+
+func (messages *MessagesResponse) Error() error {
+	if messages.Ok {
+		return nil
+	}
+	return APIError{Code: messages.ErrorCode, Description: messages.Description, Parameters: messages.Parameters}
+}
+
+func (user *UserResponse) Parse(reader io.Reader) error {
+	return ParseJson(user, reader)
+}
+
+func (user *UserResponse) Error() error {
+	if user.Ok {
+		return nil
+	}
+	return APIError{Code: user.ErrorCode, Description: user.Description, Parameters: user.Parameters}
+}
+
+func (resp *BoolResponse) Parse(reader io.Reader) error {
+	return ParseJson(resp, reader)
+}
+
+func (resp *BoolResponse) Error() error {
+	if resp.Ok {
+		return nil
+	}
+	return APIError{Code: resp.ErrorCode, Description: resp.Description, Parameters: resp.Parameters}
 }
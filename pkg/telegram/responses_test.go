@@ -94,3 +94,31 @@ func TestParseResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestParseMessageResponse(t *testing.T) {
+	tests := map[string]struct {
+		json      string
+		messageId int
+	}{
+		"Message result": {json: `{"ok": true, "result": {"message_id": 2468}}`, messageId: 2468},
+		"Bool result":    {json: `{"ok": true, "result": true}`},
+		"No result":      {json: `{"ok": false, "error_code": 400, "description": "Bad Request"}`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := MessageResponse{}
+			if err := resp.Parse(strings.NewReader(test.json)); err != nil || resp.Result.MessageId != test.messageId {
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("Bool result is ok", func(t *testing.T) {
+		resp := MessageResponse{}
+		resp.Parse(strings.NewReader(`{"ok": true, "result": true}`))
+		if !resp.Ok || resp.Error() != nil {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package telegram
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Command struct {
+	Name    string
+	BotName string
+	Args    string
+}
+
+func ParseCommand(text string) (cmd Command) {
+	if !strings.HasPrefix(text, "/") {
+		return
+	}
+	head, args := text[1:], ""
+	if i := strings.IndexFunc(head, unicode.IsSpace); i >= 0 {
+		head, args = head[:i], head[i:]
+	}
+	name, bot, _ := strings.Cut(head, "@")
+	end := strings.IndexFunc(name, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	})
+	if end >= 0 {
+		return Command{Name: name[:end]}
+	}
+	return Command{Name: name, BotName: bot, Args: strings.TrimSpace(args)}
+}
+
+func (cmd Command) Fields() []string {
+	return strings.Fields(cmd.Args)
+}
+
+func (cmd Command) IsFor(botName string) bool {
+	return cmd.BotName == "" || botName == "" || strings.EqualFold(cmd.BotName, botName)
+}
+
+func (cmd Command) StartPayload() string {
+	if cmd.Name != "start" {
+		return ""
+	}
+	return cmd.Args
+}
+
+func DeepLink(botName string, payload string) string {
+	return "https://t.me/" + botName + "?start=" + url.QueryEscape(payload)
+}
+
+type GetMeRequest struct{}
+
+func (req GetMeRequest) GetParams() (val url.Values, method string, err error) {
+	return url.Values{}, "getMe", nil
+}
+
+func GetMe(ctx context.Context, tb Bot) (user *User, err error) {
+	httpResp, err := tb.SendRequest(ctx, GetMeRequest{})
+	if err != nil {
+		return
+	}
+	defer httpResp.Body.Close()
+	resp := UserResponse{}
+	if err = resp.Parse(httpResp.Body); err == nil {
+		err = resp.Error()
+	}
+	return &resp.Result, err
+}
+
+// SendBoolRequest sends a request answered with true, e.g. setMyCommands or setWebhook,
+// and returns the API error when Telegram rejects it.
+func SendBoolRequest(ctx context.Context, tb Bot, req Request) (resp *BoolResponse, err error) {
+	httpResp, err := tb.SendRequest(ctx, req)
+	if err != nil {
+		return
+	}
+	defer httpResp.Body.Close()
+	resp = &BoolResponse{}
+	if err = resp.Parse(httpResp.Body); err == nil {
+		err = resp.Error()
+	}
+	return
+}
+
+type CommandFunc func(ctx context.Context, m *Message, cmd Command) error
+type DeepLinkFunc func(ctx context.Context, m *Message, payload string) error
+
+type patternRoute struct {
+	re      *regexp.Regexp
+	handler CommandFunc
+}
+
+func NewRouter(botName string) *Router {
+	return &Router{BotName: botName, commands: make(map[string]CommandFunc), links: make(map[string]DeepLinkFunc)}
+}
+
+type Router struct {
+	BotName  string
+	Commands []BotCommand
+	commands map[string]CommandFunc
+	patterns []patternRoute
+	links    map[string]DeepLinkFunc
+}
+
+func (r *Router) Handle(command string, h CommandFunc) {
+	r.commands[command] = h
+}
+
+func (r *Router) HandlePattern(pattern string, h CommandFunc) (err error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return
+	}
+	r.patterns = append(r.patterns, patternRoute{re: re, handler: h})
+	return
+}
+
+func (r *Router) HandleDeepLink(prefix string, h DeepLinkFunc) {
+	r.links[prefix] = h
+}
+
+func (r *Router) GetCommands() []BotCommand {
+	return r.Commands
+}
+
+func (r *Router) ProceedMessage(ctx context.Context, m *Message) error {
+	cmd := m.ParseCommand()
+	if cmd.Name == "" || !cmd.IsFor(r.BotName) {
+		return nil
+	}
+	if payload := cmd.StartPayload(); payload != "" {
+		prefix, value, _ := strings.Cut(payload, "_")
+		if h, ok := r.links[prefix]; ok {
+			return h(ctx, m, value)
+		}
+	}
+	if h, ok := r.commands[cmd.Name]; ok {
+		return h(ctx, m, cmd)
+	}
+	for _, route := range r.patterns {
+		if route.re.MatchString(cmd.Name) {
+			return route.handler(ctx, m, cmd)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		want Command
+	}{
+		"Text without command": {text: "some text", want: Command{}},
+		"Command":              {text: "/start", want: Command{Name: "start"}},
+		"Command with bot name": {
+			text: "/start@VolleyBot", want: Command{Name: "start", BotName: "VolleyBot"}},
+		"Command with args": {
+			text: "/list  today  10 ", want: Command{Name: "list", Args: "today  10"}},
+		"Command with bot name and args": {
+			text: "/start@VolleyBot join_abc", want: Command{Name: "start", BotName: "VolleyBot", Args: "join_abc"}},
+		"Command with newline": {
+			text: "/desc\nnew text", want: Command{Name: "desc", Args: "new text"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cmd := ParseCommand(test.text); cmd != test.want {
+				t.Fail()
+			}
+		})
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		if !reflect.DeepEqual(ParseCommand("/list  today  10 ").Fields(), []string{"today", "10"}) {
+			t.Fail()
+		}
+	})
+}
+
+func TestRouterProceedMessage(t *testing.T) {
+	var called, args string
+	record := func(name string) CommandFunc {
+		return func(ctx context.Context, m *Message, cmd Command) error {
+			called, args = name, cmd.Args
+			return nil
+		}
+	}
+	r := NewRouter("VolleyBot")
+	r.Handle("start", record("start"))
+	r.HandlePattern("^list.*", record("list"))
+	r.HandleDeepLink("join", func(ctx context.Context, m *Message, payload string) error {
+		called, args = "join", payload
+		return nil
+	})
+
+	tests := map[string]struct {
+		text   string
+		called string
+		args   string
+	}{
+		"Plain command":          {text: "/start", called: "start"},
+		"Command for this bot":   {text: "/start@volleybot", called: "start"},
+		"Command for other bot":  {text: "/start@OtherBot"},
+		"Pattern command":        {text: "/listd tomorrow", called: "list", args: "tomorrow"},
+		"Deep link":              {text: "/start join_0a1b2c", called: "join", args: "0a1b2c"},
+		"Unknown deep link":      {text: "/start promo_1", called: "start", args: "promo_1"},
+		"Unknown command":        {text: "/help"},
+		"Text without a command": {text: "start"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			called, args = "", ""
+			if err := r.ProceedMessage(context.Background(), &Message{Text: test.text}); err != nil {
+				t.Fail()
+			}
+			if called != test.called || args != test.args {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestDeepLink(t *testing.T) {
+	if DeepLink("VolleyBot", "join_0a1b2c") != "https://t.me/VolleyBot?start=join_0a1b2c" {
+		t.Fail()
+	}
+}
+
+func TestSendBoolRequest(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want error
+	}{
+		"Accepted": {body: `{"ok":true,"result":true}`},
+		"Rejected": {
+			body: `{"ok":false,"error_code":400,"description":"Bad Request: bad webhook: HTTPS url must be provided for webhook"}`,
+			want: APIError{Code: 400, Description: "Bad Request: bad webhook: HTTPS url must be provided for webhook"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tb, _ := NewSimpleBot("***Token***", &seqClientMock{Bodies: []string{test.body}})
+			resp, err := SendBoolRequest(context.Background(), tb, SetWebhookRequest{Url: "http://example.com"})
+			if err != test.want {
+				t.Fail()
+			}
+			if resp.Result != (test.want == nil) {
+				t.Fail()
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package telegram
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"sync"
@@ -17,15 +18,16 @@ func NewState() State {
 }
 
 type State struct {
-	Action    string `json:"action"`
-	ChatId    int    `json:"chat_id"`
-	Data      string `json:"data"`
-	MessageId int    `json:"message_id"`
-	Prefix    string `json:"prefix"`
-	Separator string `json:"separator"`
-	State     string `json:"state"`
-	Updated   bool   `json:"updated"`
-	Value     string `json:"value"`
+	Action          string `json:"action"`
+	ChatId          int    `json:"chat_id"`
+	Data            string `json:"data"`
+	InlineMessageId string `json:"inline_message_id"`
+	MessageId       int    `json:"message_id"`
+	Prefix          string `json:"prefix"`
+	Separator       string `json:"separator"`
+	State           string `json:"state"`
+	Updated         bool   `json:"updated"`
+	Value           string `json:"value"`
 }
 
 func (st State) String() string {
@@ -67,6 +69,7 @@ type StateRequest struct {
 	State
 	Request
 	Clear bool
+	Pin   bool
 }
 
 func NewMemoryStateRepository() StateRepository {
@@ -78,21 +81,21 @@ type MemoryStateRepository struct {
 	sync.Mutex
 }
 
-func (rep *MemoryStateRepository) Get(ChatId int) (st []State, err error) {
+func (rep *MemoryStateRepository) Get(ctx context.Context, ChatId int) (st []State, err error) {
 	if state, ok := rep.states[ChatId]; ok {
 		return []State{state}, nil
 	}
 	return []State{}, ErrStateNotFound
 }
 
-func (rep *MemoryStateRepository) GetByMessage(msg Message) (st State, err error) {
+func (rep *MemoryStateRepository) GetByMessage(ctx context.Context, msg Message) (st State, err error) {
 	if state, ok := rep.states[msg.Chat.Id]; ok {
 		return state, nil
 	}
 	return State{}, ErrStateNotFound
 }
 
-func (rep *MemoryStateRepository) GetByData(Data string) (slist []State, err error) {
+func (rep *MemoryStateRepository) GetByData(ctx context.Context, Data string) (slist []State, err error) {
 	rep.Mutex.Lock()
 	for _, s := range rep.states {
 		if s.Data == Data {
@@ -103,7 +106,7 @@ func (rep *MemoryStateRepository) GetByData(Data string) (slist []State, err err
 	return
 }
 
-func (rep *MemoryStateRepository) Set(s State) (err error) {
+func (rep *MemoryStateRepository) Set(ctx context.Context, s State) (err error) {
 	if rep.states == nil {
 		rep.Lock()
 		rep.states = make(map[int]State)
@@ -115,7 +118,14 @@ func (rep *MemoryStateRepository) Set(s State) (err error) {
 	return
 }
 
-func (rep *MemoryStateRepository) Clear(st State) error {
+func (rep *MemoryStateRepository) Clear(ctx context.Context, st State) error {
 	delete(rep.states, st.ChatId)
 	return nil
 }
+
+func (rep *MemoryStateRepository) ClearChat(ctx context.Context, ChatId int) error {
+	rep.Lock()
+	delete(rep.states, ChatId)
+	rep.Unlock()
+	return nil
+}
@@ -0,0 +1,659 @@
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"volleybot/pkg/telegram"
+)
+
+const (
+	DefaultToken = "123456:TEST-TOKEN"
+	BotId        = 123456
+)
+
+var (
+	ErrButtonNotFound = errors.New("button not found")
+	ErrPollNotFound   = errors.New("poll not found")
+)
+
+type Call struct {
+	Method string
+	Values url.Values
+}
+
+type Server struct {
+	*httptest.Server
+	Token     string
+	Me        telegram.User
+	mu        sync.Mutex
+	updateId  int
+	messageId int
+	queryId   int
+	pollId    int
+	fileId    int
+	updates   []telegram.Update
+	chats     map[int]*telegram.Chat
+	history   map[int][]telegram.Message
+	pinned    map[int][]int
+	votes     map[string]map[int][]int
+	answers   map[string]*telegram.AnswerCallbackQueryRequest
+	files     map[string][]byte
+	commands  []telegram.BotCommand
+	calls     []Call
+}
+
+func NewServer() *Server {
+	s := &Server{
+		Token:   DefaultToken,
+		Me:      telegram.User{Id: BotId, IsBot: true, FirstName: "Test", UserName: "test_bot"},
+		chats:   make(map[int]*telegram.Chat),
+		history: make(map[int][]telegram.Message),
+		pinned:  make(map[int][]int),
+		votes:   make(map[string]map[int][]int),
+		answers: make(map[string]*telegram.AnswerCallbackQueryRequest),
+		files:   make(map[string][]byte),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *Server) Bot() telegram.SimpleBot {
+	tb, _ := telegram.NewSimpleBotWithEndpoint(s.URL, s.Token, s.Client())
+	return tb
+}
+
+func (s *Server) Chat(id int) telegram.Chat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.chat(id)
+}
+
+func (s *Server) AddChat(chat telegram.Chat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chat.Id] = &chat
+}
+
+func (s *Server) UserMessage(from telegram.User, chatId int, text string) telegram.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messageId++
+	msg := telegram.Message{MessageId: s.messageId, From: &from, Chat: s.chat(chatId),
+		Date: int(time.Now().Unix()), Text: text}
+	if cmd := msg.ParseCommand(); cmd.Name != "" {
+		msg.Entities = []telegram.MessageEntity{{Type: "bot_command", Length: strings.IndexAny(text+" ", " \n")}}
+	}
+	s.history[chatId] = append(s.history[chatId], msg)
+	s.push(telegram.Update{Message: &msg})
+	return msg
+}
+
+func (s *Server) EditUserMessage(msg telegram.Message, text string) (telegram.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.find(msg.Chat.Id, msg.MessageId)
+	if stored == nil {
+		return msg, false
+	}
+	stored.Text = text
+	stored.EditDate = int(time.Now().Unix())
+	edited := *stored
+	s.push(telegram.Update{EditedMessage: &edited})
+	return edited, true
+}
+
+func (s *Server) ChannelPost(chatId int, text string) telegram.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messageId++
+	ch := s.chat(chatId)
+	ch.Type = "channel"
+	msg := telegram.Message{MessageId: s.messageId, SenderChat: ch, Chat: ch, Date: int(time.Now().Unix()), Text: text}
+	s.history[chatId] = append(s.history[chatId], msg)
+	s.push(telegram.Update{ChannelPost: &msg})
+	return msg
+}
+
+func (s *Server) EditChannelPost(msg telegram.Message, text string) (telegram.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.find(msg.Chat.Id, msg.MessageId)
+	if stored == nil {
+		return msg, false
+	}
+	stored.Text = text
+	stored.EditDate = int(time.Now().Unix())
+	edited := *stored
+	s.push(telegram.Update{EditedChannelPost: &edited})
+	return edited, true
+}
+
+func (s *Server) Callback(from telegram.User, msg telegram.Message, data string) telegram.CallbackQuery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryId++
+	cq := telegram.CallbackQuery{Id: strconv.Itoa(s.queryId), From: &from, Message: &msg,
+		ChatInstance: strconv.Itoa(msg.Chat.Id), Data: data}
+	s.answers[cq.Id] = nil
+	s.push(telegram.Update{CallbackQuery: &cq})
+	return cq
+}
+
+// InlineCallback pushes a button press on a message sent in inline mode.
+func (s *Server) InlineCallback(from telegram.User, inlineMessageId string, data string) telegram.CallbackQuery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queryId++
+	cq := telegram.CallbackQuery{Id: strconv.Itoa(s.queryId), From: &from, InlineMessageId: inlineMessageId,
+		ChatInstance: inlineMessageId, Data: data}
+	s.answers[cq.Id] = nil
+	s.push(telegram.Update{CallbackQuery: &cq})
+	return cq
+}
+
+func (s *Server) PressButton(from telegram.User, chatId int, text string) (cq telegram.CallbackQuery, err error) {
+	s.mu.Lock()
+	msgs := s.history[chatId]
+	s.mu.Unlock()
+	for i := len(msgs) - 1; i >= 0; i-- {
+		for _, btn := range Buttons(msgs[i]) {
+			if btn.Text == text && btn.CallbackData != "" {
+				return s.Callback(from, msgs[i], btn.CallbackData), nil
+			}
+		}
+	}
+	return cq, ErrButtonNotFound
+}
+
+func (s *Server) Vote(from telegram.User, msg telegram.Message, options ...int) (pa telegram.PollAnswer, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := s.find(msg.Chat.Id, msg.MessageId)
+	if stored == nil || stored.Poll == nil || stored.Poll.IsClosed {
+		return pa, ErrPollNotFound
+	}
+	poll := *stored.Poll
+	poll.Options = append([]telegram.PollOption{}, poll.Options...)
+	votes := s.votes[poll.Id]
+	if _, ok := votes[from.Id]; ok {
+		poll.TotalVoterCount--
+	}
+	for _, id := range votes[from.Id] {
+		poll.Options[id].VoterCount--
+	}
+	delete(votes, from.Id)
+	for _, id := range options {
+		if id < 0 || id >= len(poll.Options) {
+			return pa, errors.New("Bad Request: invalid poll option")
+		}
+		poll.Options[id].VoterCount++
+	}
+	if len(options) > 0 {
+		votes[from.Id] = options
+		poll.TotalVoterCount++
+	}
+	stored.Poll = &poll
+	pa = telegram.PollAnswer{PollId: poll.Id, OptionIds: append([]int{}, options...)}
+	if !poll.IsAnonymous {
+		pa.User = &from
+		s.push(telegram.Update{PollAnswer: &pa})
+	}
+	return pa, nil
+}
+
+func (s *Server) Messages(chatId int) []telegram.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]telegram.Message{}, s.history[chatId]...)
+}
+
+func (s *Server) LastMessage(chatId int) (msg telegram.Message, ok bool) {
+	msgs := s.Messages(chatId)
+	if len(msgs) == 0 {
+		return
+	}
+	return msgs[len(msgs)-1], true
+}
+
+func (s *Server) Pinned(chatId int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int{}, s.pinned[chatId]...)
+}
+
+func (s *Server) Answer(queryId string) (answer telegram.AnswerCallbackQueryRequest, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a := s.answers[queryId]; a != nil {
+		return *a, true
+	}
+	return
+}
+
+func (s *Server) Commands() []telegram.BotCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]telegram.BotCommand{}, s.commands...)
+}
+
+// File returns the content of the document uploaded to the server.
+func (s *Server) File(fileId string) (data []byte, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok = s.files[fileId]
+	return
+}
+
+func (s *Server) Calls(method string) (calls []Call) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.calls {
+		if method == "" || c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return
+}
+
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.updates)
+}
+
+func Buttons(msg telegram.Message) (btns []telegram.InlineKeyboardButton) {
+	if kbd, ok := msg.ReplyMarkup.(telegram.InlineKeyboardMarkup); ok {
+		for _, row := range kbd.InlineKeyboard {
+			btns = append(btns, row...)
+		}
+	}
+	return
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/bot"+s.Token+"/")
+	if method == r.URL.Path {
+		writeError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		writeError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{Method: method, Values: r.Form})
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch method {
+	case "getMe":
+		result = s.Me
+	case "getUpdates":
+		result = s.getUpdates(r.Form)
+	case "sendMessage":
+		result, err = s.sendMessage(r.Form)
+	case "editMessageText":
+		result, err = s.editMessageText(r.Form)
+	case "editMessageReplyMarkup":
+		result, err = s.editMessageReplyMarkup(r.Form)
+	case "deleteMessage":
+		result, err = s.deleteMessage(r.Form)
+	case "sendDocument":
+		result, err = s.sendDocument(r.Form, r.MultipartForm)
+	case "sendPoll":
+		result, err = s.sendPoll(r.Form)
+	case "stopPoll":
+		result, err = s.stopPoll(r.Form)
+	case "pinChatMessage":
+		result, err = s.pinChatMessage(r.Form)
+	case "unpinChatMessage":
+		result, err = s.unpinChatMessage(r.Form)
+	case "copyMessage", "forwardMessage":
+		result, err = s.copyMessage(method, r.Form)
+	case "answerCallbackQuery":
+		result, err = s.answerCallbackQuery(r.Form)
+	case "setMyCommands":
+		result, err = s.setMyCommands(r.Form)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found: method not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+}
+
+func writeError(w http.ResponseWriter, code int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error_code": code, "description": description})
+}
+
+func (s *Server) push(u telegram.Update) {
+	s.updateId++
+	u.UpdateId = s.updateId
+	s.updates = append(s.updates, u)
+}
+
+func (s *Server) chat(id int) *telegram.Chat {
+	if ch, ok := s.chats[id]; ok {
+		return ch
+	}
+	ch := &telegram.Chat{Id: id, Type: "private"}
+	if id < 0 {
+		ch.Type = "group"
+	}
+	s.chats[id] = ch
+	return ch
+}
+
+func (s *Server) find(chatId int, messageId int) *telegram.Message {
+	for i, msg := range s.history[chatId] {
+		if msg.MessageId == messageId {
+			return &s.history[chatId][i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) getUpdates(val url.Values) []telegram.Update {
+	offset, _ := strconv.Atoi(val.Get("offset"))
+	limit, _ := strconv.Atoi(val.Get("limit"))
+	for len(s.updates) > 0 && s.updates[0].UpdateId < offset {
+		s.updates = s.updates[1:]
+	}
+	if limit <= 0 || limit > len(s.updates) {
+		limit = len(s.updates)
+	}
+	return append([]telegram.Update{}, s.updates[:limit]...)
+}
+
+func (s *Server) sendMessage(val url.Values) (msg telegram.Message, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	if cid == 0 {
+		return msg, errors.New("Bad Request: chat not found")
+	}
+	if val.Get("text") == "" {
+		return msg, errors.New("Bad Request: message text is empty")
+	}
+	if msg.ReplyMarkup, err = parseMarkup(val.Get("reply_markup")); err != nil {
+		return
+	}
+	msg.Text = telegram.PlainText(val.Get("parse_mode"), val.Get("text"))
+	return s.post(cid, msg), nil
+}
+
+func (s *Server) sendDocument(val url.Values, form *multipart.Form) (msg telegram.Message, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	if cid == 0 {
+		return msg, errors.New("Bad Request: chat not found")
+	}
+	doc := telegram.Document{FileId: val.Get("document")}
+	if form != nil && len(form.File["document"]) > 0 {
+		fh := form.File["document"][0]
+		f, ferr := fh.Open()
+		if ferr != nil {
+			return msg, ferr
+		}
+		defer f.Close()
+		data, rerr := io.ReadAll(f)
+		if rerr != nil {
+			return msg, rerr
+		}
+		s.fileId++
+		doc = telegram.Document{FileId: "file" + strconv.Itoa(s.fileId), FileName: fh.Filename, FileSize: len(data)}
+		s.files[doc.FileId] = data
+	}
+	if doc.FileId == "" {
+		return msg, errors.New("Bad Request: there is no document in the request")
+	}
+	if msg.ReplyMarkup, err = parseMarkup(val.Get("reply_markup")); err != nil {
+		return
+	}
+	doc.FileUniqueId = doc.FileId
+	msg.Document = &doc
+	msg.Caption = telegram.PlainText(val.Get("parse_mode"), val.Get("caption"))
+	return s.post(cid, msg), nil
+}
+
+func (s *Server) sendPoll(val url.Values) (msg telegram.Message, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	if cid == 0 {
+		return msg, errors.New("Bad Request: chat not found")
+	}
+	var options []string
+	if err = json.Unmarshal([]byte(val.Get("options")), &options); err != nil || len(options) < 2 {
+		return msg, errors.New("Bad Request: poll must have at least 2 option")
+	}
+	if val.Get("question") == "" {
+		return msg, errors.New("Bad Request: poll question must be non-empty")
+	}
+	if msg.ReplyMarkup, err = parseMarkup(val.Get("reply_markup")); err != nil {
+		return
+	}
+	s.pollId++
+	poll := telegram.Poll{Id: strconv.Itoa(5000 + s.pollId), Question: val.Get("question"),
+		IsAnonymous: val.Get("is_anonymous") != "false", Type: telegram.PollRegular}
+	if val.Get("type") != "" {
+		poll.Type = val.Get("type")
+	}
+	if !poll.IsAnonymous && s.chat(cid).Type == "channel" {
+		return msg, errors.New("Bad Request: non-anonymous polls can't be sent to channel chats")
+	}
+	for _, opt := range options {
+		poll.Options = append(poll.Options, telegram.PollOption{Text: opt})
+	}
+	s.votes[poll.Id] = make(map[int][]int)
+	msg.Poll = &poll
+	return s.post(cid, msg), nil
+}
+
+func (s *Server) stopPoll(val url.Values) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	msg := s.find(cid, mid)
+	if msg == nil || msg.Poll == nil {
+		return nil, errors.New("Bad Request: message with poll to stop not found")
+	}
+	if msg.Poll.IsClosed {
+		return nil, errors.New("Bad Request: poll has already been closed")
+	}
+	poll := *msg.Poll
+	poll.IsClosed = true
+	msg.Poll = &poll
+	return poll, nil
+}
+
+func (s *Server) post(cid int, msg telegram.Message) telegram.Message {
+	s.messageId++
+	me := s.Me
+	msg.MessageId = s.messageId
+	msg.From = &me
+	msg.Chat = s.chat(cid)
+	msg.Date = int(time.Now().Unix())
+	s.history[cid] = append(s.history[cid], msg)
+	return msg
+}
+
+func (s *Server) editMessageText(val url.Values) (result interface{}, err error) {
+	markup, err := parseMarkup(val.Get("reply_markup"))
+	if err != nil {
+		return
+	}
+	if val.Get("inline_message_id") != "" {
+		return true, nil
+	}
+	text := telegram.PlainText(val.Get("parse_mode"), val.Get("text"))
+	return s.edit(val, func(msg *telegram.Message) bool {
+		if msg.Text == text && equalMarkup(msg.ReplyMarkup, markup) {
+			return false
+		}
+		msg.Text = text
+		msg.ReplyMarkup = markup
+		return true
+	})
+}
+
+func (s *Server) editMessageReplyMarkup(val url.Values) (result interface{}, err error) {
+	markup, err := parseMarkup(val.Get("reply_markup"))
+	if err != nil {
+		return
+	}
+	if val.Get("inline_message_id") != "" {
+		return true, nil
+	}
+	return s.edit(val, func(msg *telegram.Message) bool {
+		if equalMarkup(msg.ReplyMarkup, markup) {
+			return false
+		}
+		msg.ReplyMarkup = markup
+		return true
+	})
+}
+
+func (s *Server) edit(val url.Values, apply func(*telegram.Message) bool) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	msg := s.find(cid, mid)
+	if msg == nil {
+		return nil, errors.New("Bad Request: message to edit not found")
+	}
+	if !apply(msg) {
+		return nil, errors.New("Bad Request: message is not modified: specified new message content and reply markup " +
+			"are exactly the same as a current content and reply markup of the message")
+	}
+	msg.EditDate = int(time.Now().Unix())
+	return *msg, nil
+}
+
+func (s *Server) pinChatMessage(val url.Values) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	if s.find(cid, mid) == nil {
+		return nil, errors.New("Bad Request: message to pin not found")
+	}
+	s.unpin(cid, mid)
+	s.pinned[cid] = append(s.pinned[cid], mid)
+	return true, nil
+}
+
+func (s *Server) unpinChatMessage(val url.Values) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	if mid == 0 && len(s.pinned[cid]) > 0 {
+		mid = s.pinned[cid][len(s.pinned[cid])-1]
+	}
+	if !s.unpin(cid, mid) {
+		return nil, errors.New("Bad Request: message to unpin not found")
+	}
+	return true, nil
+}
+
+func (s *Server) unpin(cid int, mid int) bool {
+	for i, id := range s.pinned[cid] {
+		if id == mid {
+			s.pinned[cid] = append(s.pinned[cid][:i:i], s.pinned[cid][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) copyMessage(method string, val url.Values) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	from, _ := strconv.Atoi(val.Get("from_chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	src := s.find(from, mid)
+	if cid == 0 || src == nil {
+		return nil, errors.New("Bad Request: message to " + strings.TrimSuffix(method, "Message") + " not found")
+	}
+	msg := telegram.Message{Text: src.Text, Entities: src.Entities, ReplyMarkup: src.ReplyMarkup}
+	if method == "forwardMessage" {
+		msg.ForwardFrom = src.From
+		msg.ForwardDate = src.Date
+		return s.post(cid, msg), nil
+	}
+	if msg.ReplyMarkup, err = parseMarkup(val.Get("reply_markup")); err != nil {
+		return
+	}
+	msg = s.post(cid, msg)
+	return map[string]int{"message_id": msg.MessageId}, nil
+}
+
+func (s *Server) deleteMessage(val url.Values) (result interface{}, err error) {
+	cid, _ := strconv.Atoi(val.Get("chat_id"))
+	mid, _ := strconv.Atoi(val.Get("message_id"))
+	for i, msg := range s.history[cid] {
+		if msg.MessageId == mid {
+			s.history[cid] = append(s.history[cid][:i:i], s.history[cid][i+1:]...)
+			return true, nil
+		}
+	}
+	return nil, errors.New("Bad Request: message to delete not found")
+}
+
+func (s *Server) answerCallbackQuery(val url.Values) (result interface{}, err error) {
+	id := val.Get("callback_query_id")
+	if a, ok := s.answers[id]; !ok || a != nil {
+		return nil, errors.New("Bad Request: query is too old and response timeout expired or query ID is invalid")
+	}
+	showAlert, _ := strconv.ParseBool(val.Get("show_alert"))
+	s.answers[id] = &telegram.AnswerCallbackQueryRequest{CallbackQueryId: id, Text: val.Get("text"),
+		ShowAlert: showAlert, URL: val.Get("url")}
+	return true, nil
+}
+
+func (s *Server) setMyCommands(val url.Values) (result interface{}, err error) {
+	var cmds []telegram.BotCommand
+	if err = json.Unmarshal([]byte(val.Get("commands")), &cmds); err != nil {
+		return nil, errors.New("Bad Request: can't parse commands JSON object")
+	}
+	s.commands = cmds
+	return true, nil
+}
+
+func parseMarkup(data string) (markup interface{}, err error) {
+	if data == "" {
+		return
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, errors.New("Bad Request: can't parse reply keyboard markup JSON object")
+	}
+	if _, ok := raw["inline_keyboard"]; !ok {
+		return raw, nil
+	}
+	var kbd telegram.InlineKeyboardMarkup
+	if err = json.Unmarshal([]byte(data), &kbd); err != nil {
+		return nil, errors.New("Bad Request: can't parse inline keyboard button")
+	}
+	for _, row := range kbd.InlineKeyboard {
+		for _, btn := range row {
+			if len(btn.CallbackData) > telegram.MaxCallbackDataLength {
+				return nil, errors.New("Bad Request: BUTTON_DATA_INVALID")
+			}
+		}
+	}
+	return kbd, nil
+}
+
+func equalMarkup(a interface{}, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
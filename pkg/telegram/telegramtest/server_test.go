@@ -0,0 +1,173 @@
+package telegramtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"volleybot/pkg/telegram"
+)
+
+func TestServerConversation(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	tb := srv.Bot()
+	user := telegram.User{Id: 10, FirstName: "Ivan"}
+
+	uh := &telegram.BaseUpdateHandler{}
+	uh.AppendMessageHandlers(&telegram.BaseMessageHandler{Handler: func(ctx context.Context, msg *telegram.Message) error {
+		_, err := tb.SendMessage(ctx, &telegram.MessageRequest{ChatId: msg.Chat.Id, Text: "Echo: " + msg.Text,
+			ReplyMarkup: telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{
+				{{Text: "Press", CallbackData: "pr_state_press"}}}}})
+		return err
+	}})
+	uh.AppendCallbackHandlers(&telegram.BaseCallbackHandler{Handler: func(ctx context.Context, cq *telegram.CallbackQuery) error {
+		if _, err := tb.SendMessage(ctx, &telegram.EditMessageTextRequest{ChatId: cq.Message.Chat.Id,
+			MessageId: cq.Message.MessageId, Text: "Pressed"}); err != nil {
+			return err
+		}
+		_, err := cq.Answer(ctx, tb, "Ok", telegram.AnswerCallbackQueryRequest{})
+		return err
+	}})
+	lp := telegram.NewSimplePoller(tb)
+	lp.Timeout = 0
+	lp.UpdateHandlers = []telegram.UpdateHandler{uh}
+
+	srv.UserMessage(user, user.Id, "hello")
+	if err := lp.ProceedUpdates(ctx); err != nil || srv.Pending() != 1 {
+		t.FailNow()
+	}
+	msg, ok := srv.LastMessage(user.Id)
+	if !ok || msg.Text != "Echo: hello" || msg.From.Id != BotId || len(Buttons(msg)) != 1 {
+		t.FailNow()
+	}
+
+	cq, err := srv.PressButton(user, user.Id, "Press")
+	if err != nil {
+		t.FailNow()
+	}
+	lp.ProceedUpdates(ctx)
+	if srv.Pending() != 1 {
+		t.Fail()
+	}
+	if msg, _ = srv.LastMessage(user.Id); msg.Text != "Pressed" || len(Buttons(msg)) != 0 {
+		t.Fail()
+	}
+	if answer, ok := srv.Answer(cq.Id); !ok || answer.Text != "Ok" {
+		t.Fail()
+	}
+	if len(srv.Messages(user.Id)) != 2 {
+		t.Fail()
+	}
+	if _, err = srv.PressButton(user, user.Id, "Missing"); err != ErrButtonNotFound {
+		t.Fail()
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	tb := srv.Bot()
+
+	resp, err := tb.SendMessage(ctx, &telegram.MessageRequest{ChatId: 10, Text: "Text"})
+	if err != nil {
+		t.FailNow()
+	}
+	mid := resp.Result.MessageId
+
+	tests := map[string]struct {
+		req  telegram.Request
+		code int
+		mod  bool
+	}{
+		"Not modified": {
+			req:  &telegram.EditMessageTextRequest{ChatId: 10, MessageId: mid, Text: "Text"},
+			code: telegram.BadRequestCode, mod: true},
+		"Edit not found": {
+			req:  &telegram.EditMessageTextRequest{ChatId: 10, MessageId: mid + 1, Text: "Text"},
+			code: telegram.BadRequestCode},
+		"Empty text": {
+			req:  &telegram.MessageRequest{ChatId: 10},
+			code: telegram.BadRequestCode},
+		"Long callback data": {
+			req: &telegram.MessageRequest{ChatId: 10, Text: "Text", ReplyMarkup: telegram.InlineKeyboardMarkup{
+				InlineKeyboard: [][]telegram.InlineKeyboardButton{{{Text: "Btn", CallbackData: string(make([]byte, 65))}}}}},
+			code: telegram.BadRequestCode},
+		"Unknown callback query": {
+			req:  telegram.AnswerCallbackQueryRequest{CallbackQueryId: "42"},
+			code: telegram.BadRequestCode},
+		"Unknown method": {
+			req:  telegram.DeleteWebhookRequest{},
+			code: 404},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tb.SendMessage(ctx, test.req)
+			apiErr, ok := err.(telegram.APIError)
+			if !ok || apiErr.Code != test.code {
+				t.Fail()
+			}
+			if telegram.IsNotModified(err) != test.mod {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestServerCommandsAndDelete(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	tb := srv.Bot()
+
+	cmds := []telegram.BotCommand{{Command: "start", Description: "Start"}}
+	if _, err := tb.SendRequest(ctx, &telegram.SetMyCommandsRequest{Commands: cmds}); err != nil {
+		t.FailNow()
+	}
+	if got := srv.Commands(); len(got) != 1 || got[0] != cmds[0] {
+		t.Fail()
+	}
+
+	msg := srv.UserMessage(telegram.User{Id: 10}, -100, "/start@test_bot")
+	if msg.Chat.Type != "group" || len(msg.Entities) != 1 || msg.Entities[0].Length != 15 {
+		t.Fail()
+	}
+	if _, err := tb.SendMessage(ctx, &telegram.DeleteMessageRequest{ChatId: -100, MessageId: msg.MessageId}); err != nil {
+		t.Fail()
+	}
+	if len(srv.Messages(-100)) != 0 || len(srv.Calls("deleteMessage")) != 1 {
+		t.Fail()
+	}
+	if me, err := telegram.GetMe(ctx, tb); err != nil || me.UserName != "test_bot" {
+		t.Fail()
+	}
+}
+
+func TestServerDocument(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	tb := srv.Bot()
+
+	req := telegram.SendDocumentRequest{ChatId: 10, Caption: "<b>Bracket</b>", ParseMode: telegram.ParseModeHTML,
+		Document: telegram.InputFile{Name: "bracket.json", Reader: strings.NewReader(`{"format":0}`)}}
+	resp, err := tb.SendMessage(ctx, req)
+	if err != nil || resp.Result.Document == nil {
+		t.FailNow()
+	}
+	doc := resp.Result.Document
+	if doc.FileName != "bracket.json" || resp.Result.Caption != "Bracket" {
+		t.Fail()
+	}
+	if data, ok := srv.File(doc.FileId); !ok || string(data) != `{"format":0}` {
+		t.Fail()
+	}
+	if _, err := tb.SendMessage(ctx, telegram.SendDocumentRequest{ChatId: 10, Document: telegram.InputFile{FileId: doc.FileId}}); err != nil {
+		t.Fail()
+	}
+	if _, err := tb.SendMessage(ctx, telegram.SendDocumentRequest{ChatId: 10}); err == nil {
+		t.Fail()
+	}
+}
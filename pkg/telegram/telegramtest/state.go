@@ -0,0 +1,99 @@
+package telegramtest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"volleybot/pkg/telegram"
+)
+
+type StateRepository struct {
+	mu     sync.Mutex
+	states []telegram.State
+}
+
+func NewStateRepository() *StateRepository {
+	return &StateRepository{}
+}
+
+func (rep *StateRepository) Get(ctx context.Context, ChatId int) (slist []telegram.State, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, st := range rep.states {
+		if st.ChatId == ChatId && st.MessageId <= 0 {
+			slist = append(slist, st)
+		}
+	}
+	sort.Slice(slist, func(i, j int) bool {
+		return slist[i].MessageId > slist[j].MessageId
+	})
+	return
+}
+
+func (rep *StateRepository) GetByData(ctx context.Context, Data string) (slist []telegram.State, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, st := range rep.states {
+		if st.Data == Data {
+			slist = append(slist, st)
+		}
+	}
+	return
+}
+
+func (rep *StateRepository) GetByMessage(ctx context.Context, msg telegram.Message) (state telegram.State, err error) {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	for _, st := range rep.states {
+		if st.ChatId == msg.Chat.Id && st.MessageId == msg.MessageId {
+			state = st
+		}
+	}
+	return
+}
+
+func (rep *StateRepository) Set(ctx context.Context, st telegram.State) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	stored := telegram.NewState()
+	stored.ChatId, stored.MessageId, stored.InlineMessageId = st.ChatId, st.MessageId, st.InlineMessageId
+	stored.Prefix, stored.State, stored.Action, stored.Data = st.Prefix, st.State, st.Action, st.Data
+	for i := range rep.states {
+		if sameMessage(rep.states[i], st) {
+			rep.states[i] = stored
+			return nil
+		}
+	}
+	rep.states = append(rep.states, stored)
+	return nil
+}
+
+func (rep *StateRepository) Clear(ctx context.Context, st telegram.State) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	states := rep.states[:0]
+	for _, s := range rep.states {
+		if !sameMessage(s, st) {
+			states = append(states, s)
+		}
+	}
+	rep.states = states
+	return nil
+}
+
+func (rep *StateRepository) ClearChat(ctx context.Context, ChatId int) error {
+	rep.mu.Lock()
+	defer rep.mu.Unlock()
+	states := rep.states[:0]
+	for _, s := range rep.states {
+		if s.ChatId != ChatId {
+			states = append(states, s)
+		}
+	}
+	rep.states = states
+	return nil
+}
+
+func sameMessage(a telegram.State, b telegram.State) bool {
+	return a.ChatId == b.ChatId && a.MessageId == b.MessageId && a.InlineMessageId == b.InlineMessageId
+}
@@ -0,0 +1,149 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+var (
+	htmlReplacer       = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+	markdownV2Replacer = strings.NewReplacer(
+		"\\", "\\\\", "_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)",
+		"~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=",
+		"|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!")
+	markdownV2UrlReplacer = strings.NewReplacer("\\", "\\\\", ")", "\\)")
+	markdownV2PreReplacer = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+	htmlTagRegexp         = regexp.MustCompile(`<[^>]*>`)
+)
+
+func EscapeText(mode string, s string) string {
+	switch mode {
+	case ParseModeHTML:
+		return htmlReplacer.Replace(s)
+	case ParseModeMarkdownV2:
+		return markdownV2Replacer.Replace(s)
+	}
+	return s
+}
+
+func PlainText(mode string, s string) string {
+	if mode == ParseModeHTML {
+		return html.UnescapeString(htmlTagRegexp.ReplaceAllString(s, ""))
+	}
+	return s
+}
+
+func UserLink(uid int) string {
+	return fmt.Sprintf("tg://user?id=%d", uid)
+}
+
+type TextBuilder struct {
+	ParseMode string
+	text      strings.Builder
+	entities  []MessageEntity
+	offset    int
+}
+
+func NewTextBuilder(mode string) *TextBuilder {
+	return &TextBuilder{ParseMode: mode}
+}
+
+func (b *TextBuilder) write(s string) {
+	b.text.WriteString(s)
+	b.offset += len(utf16.Encode([]rune(s)))
+}
+
+func (b *TextBuilder) span(s string, tag string, md string, etype string) *TextBuilder {
+	switch b.ParseMode {
+	case ParseModeHTML:
+		b.write("<" + tag + ">" + EscapeText(b.ParseMode, s) + "</" + tag + ">")
+	case ParseModeMarkdownV2:
+		b.write(md + EscapeText(b.ParseMode, s) + md)
+	default:
+		b.entity(s, MessageEntity{Type: etype})
+	}
+	return b
+}
+
+func (b *TextBuilder) entity(s string, e MessageEntity) {
+	e.Offset = b.offset
+	b.write(s)
+	if e.Length = b.offset - e.Offset; e.Length > 0 {
+		b.entities = append(b.entities, e)
+	}
+}
+
+func (b *TextBuilder) Text(s string) *TextBuilder {
+	b.write(EscapeText(b.ParseMode, s))
+	return b
+}
+
+func (b *TextBuilder) Textf(format string, a ...interface{}) *TextBuilder {
+	return b.Text(fmt.Sprintf(format, a...))
+}
+
+func (b *TextBuilder) Raw(s string) *TextBuilder {
+	b.write(s)
+	return b
+}
+
+func (b *TextBuilder) Bold(s string) *TextBuilder {
+	return b.span(s, "b", "*", "bold")
+}
+
+func (b *TextBuilder) Italic(s string) *TextBuilder {
+	return b.span(s, "i", "_", "italic")
+}
+
+// Pre adds preformatted text with a monospace font, so columns stay aligned.
+func (b *TextBuilder) Pre(s string) *TextBuilder {
+	switch b.ParseMode {
+	case ParseModeHTML:
+		b.write("<pre>" + EscapeText(b.ParseMode, s) + "</pre>")
+	case ParseModeMarkdownV2:
+		b.write("```\n" + markdownV2PreReplacer.Replace(s) + "\n```")
+	default:
+		b.entity(s, MessageEntity{Type: "pre"})
+	}
+	return b
+}
+
+func (b *TextBuilder) Link(s string, url string) *TextBuilder {
+	switch b.ParseMode {
+	case ParseModeHTML:
+		b.write("<a href=\"" + EscapeText(b.ParseMode, url) + "\">" + EscapeText(b.ParseMode, s) + "</a>")
+	case ParseModeMarkdownV2:
+		b.write("[" + EscapeText(b.ParseMode, s) + "](" + markdownV2UrlReplacer.Replace(url) + ")")
+	default:
+		b.entity(s, MessageEntity{Type: "text_link", Url: url})
+	}
+	return b
+}
+
+func (b *TextBuilder) Mention(s string, uid int) *TextBuilder {
+	if b.ParseMode != ParseModeHTML && b.ParseMode != ParseModeMarkdownV2 {
+		b.entity(s, MessageEntity{Type: "text_mention", User: &User{Id: uid}})
+		return b
+	}
+	return b.Link(s, UserLink(uid))
+}
+
+func (b *TextBuilder) Len() int {
+	return b.text.Len()
+}
+
+func (b *TextBuilder) String() string {
+	return b.text.String()
+}
+
+func (b *TextBuilder) Entities() []MessageEntity {
+	return b.entities
+}
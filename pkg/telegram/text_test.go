@@ -0,0 +1,135 @@
+package telegram
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTextBuilder(t *testing.T) {
+	build := func(mode string) *TextBuilder {
+		return NewTextBuilder(mode).
+			Bold("Организатор:").Text(" ").Mention("Ivan_Petrov", 123).
+			Text("\n").Italic("a*b").Text(" <1 & 2> ").Link("[x]", "https://t.me/a_(b)")
+	}
+	tests := map[string]struct {
+		mode     string
+		text     string
+		entities []MessageEntity
+	}{
+		"HTML": {
+			mode: ParseModeHTML,
+			text: "<b>Организатор:</b> <a href=\"tg://user?id=123\">Ivan_Petrov</a>\n" +
+				"<i>a*b</i> &lt;1 &amp; 2&gt; <a href=\"https://t.me/a_(b)\">[x]</a>",
+		},
+		"MarkdownV2": {
+			mode: ParseModeMarkdownV2,
+			text: "*Организатор:* [Ivan\\_Petrov](tg://user?id=123)\n" +
+				"_a\\*b_ <1 & 2\\> [\\[x\\]](https://t.me/a_(b\\))",
+		},
+		"Entities": {
+			mode: "",
+			text: "Организатор: Ivan_Petrov\na*b <1 & 2> [x]",
+			entities: []MessageEntity{
+				{Type: "bold", Offset: 0, Length: 12},
+				{Type: "text_mention", Offset: 13, Length: 11, User: &User{Id: 123}},
+				{Type: "italic", Offset: 25, Length: 3},
+				{Type: "text_link", Offset: 37, Length: 3, Url: "https://t.me/a_(b)"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := build(test.mode)
+			if b.String() != test.text {
+				t.Fail()
+			}
+			if !reflect.DeepEqual(b.Entities(), test.entities) {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestTextBuilderEntitiesUTF16(t *testing.T) {
+	b := NewTextBuilder("").Text("🏐 ").Bold("Игра")
+	want := []MessageEntity{{Type: "bold", Offset: 3, Length: 4}}
+	if !reflect.DeepEqual(b.Entities(), want) {
+		t.Fail()
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := map[string]struct {
+		mode string
+		text string
+		want string
+	}{
+		"HTML":         {mode: ParseModeHTML, text: "<b>Tom & \"Jerry\"</b>", want: "&lt;b&gt;Tom &amp; &quot;Jerry&quot;&lt;/b&gt;"},
+		"MarkdownV2":   {mode: ParseModeMarkdownV2, text: "*bold* 1.5-2!", want: "\\*bold\\* 1\\.5\\-2\\!"},
+		"Backslash":    {mode: ParseModeMarkdownV2, text: "a\\_b", want: "a\\\\\\_b"},
+		"Unknown mode": {mode: "", text: "*as is*", want: "*as is*"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if EscapeText(test.mode, test.text) != test.want {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := map[string]struct {
+		mode string
+		text string
+		want string
+	}{
+		"HTML": {mode: ParseModeHTML, text: "<b>Game</b> <a href=\"tg://user?id=1\">Ivan_Petrov</a> &lt;3 &amp; *",
+			want: "Game Ivan_Petrov <3 & *"},
+		"Plain": {mode: "", text: "<b>as is</b>", want: "<b>as is</b>"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if PlainText(test.mode, test.text) != test.want {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestTextBuilderPre(t *testing.T) {
+	tests := map[string]struct {
+		mode     string
+		text     string
+		entities []MessageEntity
+	}{
+		"HTML": {
+			mode: ParseModeHTML,
+			text: "<pre>#1 A &amp; B  21</pre>",
+		},
+		"MarkdownV2": {
+			mode: ParseModeMarkdownV2,
+			text: "```\n#1 A & B  21\n```",
+		},
+		"Entities": {
+			mode:     "",
+			text:     "#1 A & B  21",
+			entities: []MessageEntity{{Type: "pre", Offset: 0, Length: 12}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := NewTextBuilder(test.mode).Pre("#1 A & B  21")
+			if b.String() != test.text {
+				t.Fail()
+			}
+			if !reflect.DeepEqual(b.Entities(), test.entities) {
+				t.Fail()
+			}
+		})
+	}
+}
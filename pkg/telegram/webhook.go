@@ -0,0 +1,121 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const SecretTokenHeader string = "X-Telegram-Bot-Api-Secret-Token"
+
+type SetWebhookRequest struct {
+	Url                string   `json:"url"`
+	MaxConnections     int      `json:"max_connections"`
+	AllowedUpdates     []string `json:"allowed_updates"`
+	DropPendingUpdates bool     `json:"drop_pending_updates"`
+	SecretToken        string   `json:"secret_token"`
+}
+
+func (req SetWebhookRequest) GetParams() (val url.Values, method string, err error) {
+	method = "setWebhook"
+	val = url.Values{}
+	val.Add("url", req.Url)
+	if req.MaxConnections > 0 {
+		val.Add("max_connections", strconv.Itoa(req.MaxConnections))
+	}
+	if len(req.AllowedUpdates) > 0 {
+		data, err := json.Marshal(req.AllowedUpdates)
+		if err != nil {
+			return nil, "", err
+		}
+		val.Add("allowed_updates", string(data))
+	}
+	if req.DropPendingUpdates {
+		val.Add("drop_pending_updates", strconv.FormatBool(req.DropPendingUpdates))
+	}
+	if req.SecretToken != "" {
+		val.Add("secret_token", req.SecretToken)
+	}
+	return
+}
+
+type DeleteWebhookRequest struct {
+	DropPendingUpdates bool `json:"drop_pending_updates"`
+}
+
+func (req DeleteWebhookRequest) GetParams() (val url.Values, method string, err error) {
+	method = "deleteWebhook"
+	val = url.Values{}
+	if req.DropPendingUpdates {
+		val.Add("drop_pending_updates", strconv.FormatBool(req.DropPendingUpdates))
+	}
+	return
+}
+
+func NewWebhookServer(tb Bot, addr string, secret string) WebhookServer {
+	uh := BaseUpdateHandler{MessageHandlers: []MessageHandler{}}
+	return WebhookServer{bot: tb, Addr: addr, Path: "/", SecretToken: secret,
+		UpdateHandlers: []UpdateHandler{&uh}}
+}
+
+type WebhookServer struct {
+	bot         Bot
+	Addr        string
+	Path        string
+	SecretToken string
+	Logger
+	UpdateHandlers []UpdateHandler
+}
+
+func (ws WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if ws.SecretToken != "" &&
+		subtle.ConstantTimeCompare([]byte(r.Header.Get(SecretTokenHeader)), []byte(ws.SecretToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	update := Update{}
+	if err := ParseJson(&update, r.Body); err != nil {
+		if ws.Logger != nil {
+			ws.Logger.Printf("ERROR: WebhookServer parse update error '%s'", err.Error())
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	ctx := WithoutCancel(r.Context())
+	for _, handler := range ws.UpdateHandlers {
+		if err := handler.ProceedUpdate(ctx, ws.bot, update); err != nil && ws.Logger != nil {
+			ws.Logger.Printf("ERROR: WebhookServer proceed update error '%s'", err.Error())
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (ws WebhookServer) Run(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(ws.Path, ws)
+	srv := &http.Server{Addr: ws.Addr, Handler: mux}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	err := srv.Shutdown(WithoutCancel(ctx))
+	// updates accepted by async handlers are proceeded before the server stops
+	for _, handler := range ws.UpdateHandlers {
+		if wh, ok := handler.(interface{ Wait() }); ok {
+			wh.Wait()
+		}
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookServerServeHTTP(t *testing.T) {
+	tests := map[string]struct {
+		method string
+		secret string
+		body   string
+		status int
+		text   string
+	}{
+		"Valid update": {
+			method: http.MethodPost,
+			secret: "secret",
+			body:   `{"update_id": 123130161, "message": {"message_id": 2468, "text": "Hello!"}}`,
+			status: http.StatusOK,
+			text:   "Hello!",
+		},
+		"Wrong secret": {
+			method: http.MethodPost,
+			secret: "wrong",
+			body:   `{"update_id": 123130161, "message": {"message_id": 2468, "text": "Hello!"}}`,
+			status: http.StatusUnauthorized,
+		},
+		"Secret prefix": {
+			method: http.MethodPost,
+			secret: "secr",
+			body:   `{"update_id": 123130161, "message": {"message_id": 2468, "text": "Hello!"}}`,
+			status: http.StatusUnauthorized,
+		},
+		"Wrong method": {
+			method: http.MethodGet,
+			secret: "secret",
+			status: http.StatusMethodNotAllowed,
+		},
+		"Broken json": {
+			method: http.MethodPost,
+			secret: "secret",
+			body:   `{"update_id": `,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tb, _ := NewSimpleBot("***Token***", httpClientMock{})
+			ws := NewWebhookServer(tb, ":8080", "secret")
+			text := ""
+			ws.UpdateHandlers[0].AppendMessageHandlers(&BaseMessageHandler{
+				Handler: func(ctx context.Context, m *Message) error {
+					text = m.Text
+					return nil
+				}})
+
+			req := httptest.NewRequest(test.method, "/", strings.NewReader(test.body))
+			req.Header.Set(SecretTokenHeader, test.secret)
+			rec := httptest.NewRecorder()
+			ws.ServeHTTP(rec, req)
+
+			if rec.Code != test.status {
+				t.Fail()
+			}
+			if text != test.text {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestWebhookServerDispatcher(t *testing.T) {
+	tb, _ := NewSimpleBot("***Token***", httpClientMock{})
+	ws := NewWebhookServer(tb, ":8080", "")
+	var (
+		mu    sync.Mutex
+		texts []string
+	)
+	ws.UpdateHandlers[0].AppendMessageHandlers(&BaseMessageHandler{
+		Handler: func(ctx context.Context, m *Message) error {
+			if m.Text == "first" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			texts = append(texts, m.Text)
+			return nil
+		}})
+	d := NewDispatcher(2, 10, ws.UpdateHandlers[0])
+	ws.UpdateHandlers = []UpdateHandler{d}
+
+	var wg sync.WaitGroup
+	for i, text := range []string{"first", "second"} {
+		body := fmt.Sprintf(`{"update_id": %d, "message": {"message_id": %d, "chat": {"id": 100}, "text": "%s"}}`,
+			123130161+i, 2468+i, text)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			ws.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		}()
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+	d.Wait()
+
+	t.Run("Updates of one chat in order", func(t *testing.T) {
+		if len(texts) != 2 || texts[0] != "first" || texts[1] != "second" {
+			t.Fail()
+		}
+	})
+}
+
+func TestWebhookRequestsParams(t *testing.T) {
+	tests := map[string]struct {
+		request Request
+		method  string
+		want    map[string]string
+	}{
+		"Set webhook": {
+			request: SetWebhookRequest{Url: "https://example.com/hook", SecretToken: "secret",
+				AllowedUpdates: []string{"message", "callback_query"}},
+			method: "setWebhook",
+			want: map[string]string{
+				"url":             "https://example.com/hook",
+				"secret_token":    "secret",
+				"allowed_updates": `["message","callback_query"]`,
+			},
+		},
+		"Delete webhook": {
+			request: DeleteWebhookRequest{DropPendingUpdates: true},
+			method:  "deleteWebhook",
+			want:    map[string]string{"drop_pending_updates": "true"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, method, err := test.request.GetParams()
+			if err != nil || method != test.method {
+				t.Fail()
+			}
+			if len(values) != len(test.want) {
+				t.Fail()
+			}
+			for key, val := range test.want {
+				if values.Get(key) != val {
+					t.Fail()
+				}
+			}
+		})
+	}
+}